@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Top-Ranger/questiongo/registry"
+)
+
+func init() {
+	fs := &filesystem{}
+	err := registry.RegisterBlobStore(fs, "filesystem")
+	if err != nil {
+		log.Panicln(err)
+	}
+}
+
+// ErrFilesystemInvalidName is returned when a questionnaire id or blob name can not be used as a file name.
+var ErrFilesystemInvalidName = errors.New("filesystem: invalid name")
+
+// ErrFilesystemNotConfigured is returned when the blob store is used before it is configured.
+var ErrFilesystemNotConfigured = errors.New("filesystem: usage before configuration is used")
+
+type filesystem struct {
+	path  string
+	mutex sync.RWMutex
+}
+
+func filesystemValidName(name string) bool {
+	if name == "" || name == "." || name == ".." {
+		return false
+	}
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	return !strings.ContainsAny(name, "/\\\x00")
+}
+
+func (fs *filesystem) SaveBlob(questionnaireID, name string, r io.Reader) error {
+	if !filesystemValidName(questionnaireID) || !filesystemValidName(name) {
+		return ErrFilesystemInvalidName
+	}
+
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+
+	if fs.path == "" {
+		return ErrFilesystemNotConfigured
+	}
+
+	dir := filepath.Join(fs.path, questionnaireID)
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("filesystem: can not create %s: %w", dir, err)
+	}
+
+	// Write to temporary file first so incomplete blobs are never visible
+	f, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return fmt.Errorf("filesystem: can not create temporary file in %s: %w", dir, err)
+	}
+	tempName := f.Name()
+
+	_, err = io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		os.Remove(tempName)
+		return fmt.Errorf("filesystem: can not write %s: %w", tempName, err)
+	}
+	err = f.Close()
+	if err != nil {
+		os.Remove(tempName)
+		return fmt.Errorf("filesystem: can not close %s: %w", tempName, err)
+	}
+
+	target := filepath.Join(dir, name)
+	if _, err := os.Stat(target); err == nil {
+		os.Remove(tempName)
+		return fmt.Errorf("filesystem: blob %s already exists", target)
+	}
+
+	err = os.Rename(tempName, target)
+	if err != nil {
+		os.Remove(tempName)
+		return fmt.Errorf("filesystem: can not move %s to %s: %w", tempName, target, err)
+	}
+	return nil
+}
+
+func (fs *filesystem) GetBlob(questionnaireID, name string) (io.ReadCloser, error) {
+	if !filesystemValidName(questionnaireID) || !filesystemValidName(name) {
+		return nil, ErrFilesystemInvalidName
+	}
+
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+
+	if fs.path == "" {
+		return nil, ErrFilesystemNotConfigured
+	}
+
+	return os.Open(filepath.Join(fs.path, questionnaireID, name))
+}
+
+func (fs *filesystem) DeleteBlob(questionnaireID, name string) error {
+	if !filesystemValidName(questionnaireID) || !filesystemValidName(name) {
+		return ErrFilesystemInvalidName
+	}
+
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+
+	if fs.path == "" {
+		return ErrFilesystemNotConfigured
+	}
+
+	err := os.Remove(filepath.Join(fs.path, questionnaireID, name))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("filesystem: can not delete %s: %w", name, err)
+	}
+	return nil
+}
+
+func (fs *filesystem) LoadConfig(data []byte) error {
+	p := strings.TrimSpace(string(data))
+	if p == "" {
+		return fmt.Errorf("filesystem: empty path")
+	}
+	err := os.MkdirAll(p, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("filesystem: can not create %s: %w", p, err)
+	}
+
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	fs.path = p
+	return nil
+}
+
+func (fs *filesystem) FlushAndClose() {
+	// All blobs are written synchronously, nothing to do
+}
@@ -0,0 +1,489 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Top-Ranger/questiongo/helper"
+	"github.com/Top-Ranger/questiongo/registry"
+	"github.com/Top-Ranger/questiongo/translation"
+)
+
+// codingPageSize is the number of answers shown on a page of the coding workspace.
+const codingPageSize = 50
+
+const (
+	codingTypeCodebook = "codebook"
+	codingTypeCodes    = "codes"
+)
+
+var codeFrequenciesTemplate = template.Must(template.New("codeFrequenciesTemplate").Parse(`<h3>Codes</h3>
+<table>
+<thead>
+<tr>
+<th>Code</th>
+<th>Number</th>
+<th>Percent</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Codes}}
+<tr>
+<td>{{$e.Code}}</td>
+{{if $e.Suppressed}}
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+{{else}}
+<td>{{$e.Number}}</td>
+<td>{{printf "%.2f" $e.Percent}}</td>
+{{end}}
+</tr>
+{{end}}
+<tr>
+<td class="th-cell">[coded answers]</td>
+<td>{{if .CodedSuppressed}}[suppressed]{{else}}{{.Coded}}{{end}}</td>
+</tr>
+<tr>
+<td class="th-cell">[uncoded answers]</td>
+<td>{{if .UncodedSuppressed}}[suppressed]{{else}}{{.Uncoded}}{{end}}</td>
+</tr>
+</tbody>
+</table>
+{{.Image}}
+`))
+
+type codeFrequenciesTemplateStruct struct {
+	Codes             []codeFrequenciesTemplateStructInner
+	Coded             int
+	CodedSuppressed   bool
+	Uncoded           int
+	UncodedSuppressed bool
+	Image             template.HTML
+}
+
+type codeFrequenciesTemplateStructInner struct {
+	Code       string
+	Number     int
+	Percent    float64
+	Suppressed bool
+}
+
+type codingTemplateStruct struct {
+	Key          string
+	Auth         string
+	Questions    []string
+	Question     string
+	Codebook     []string
+	Answers      []codingTemplateStructAnswer
+	Page         int
+	PageNumber   int
+	PreviousPage int
+	NextPage     int
+	Pages        int
+	Uncoded      bool
+	Message      string
+	Translation  translation.Translation
+	ServerPath   string
+}
+
+type codingTemplateStructAnswer struct {
+	Position int
+	Number   int
+	Text     string
+	Codes    []codingTemplateStructCode
+}
+
+type codingTemplateStructCode struct {
+	Code    string
+	Checked bool
+}
+
+// codingRecord is a single entry of the coding log stored under registry.CodingQuestion.
+// The current state is the result of replaying all entries in order.
+type codingRecord struct {
+	Type     string
+	Question string
+	Codebook []string `json:",omitempty"`
+	Position int      `json:",omitempty"`
+	Codes    []string `json:",omitempty"`
+}
+
+// codingState holds the current codebook and coded answers of a question.
+// Codes removed from the codebook are kept for coded answers, so they come back if the code is added again.
+type codingState struct {
+	Codebook []string
+	Codes    map[int][]string // position of the answer -> codes
+}
+
+// codesOf returns the codes of the answer which are part of the codebook, in codebook order.
+// The bool is false if the answer was not coded yet.
+func (c codingState) codesOf(position int) ([]string, bool) {
+	codes, ok := c.Codes[position]
+	if !ok {
+		return nil, false
+	}
+	return orderCodes(c.Codebook, codes), true
+}
+
+// orderCodes returns the codes which are part of the codebook, in codebook order.
+func orderCodes(codebook, codes []string) []string {
+	result := make([]string, 0, len(codes))
+	for i := range codebook {
+		for j := range codes {
+			if codes[j] == codebook[i] {
+				result = append(result, codebook[i])
+				break
+			}
+		}
+	}
+	return result
+}
+
+// CodableQuestions returns the IDs of all questions whose answers can be coded.
+func (q Questionnaire) CodableQuestions() []string {
+	result := make([]string, 0)
+	for i := range q.allQuestions {
+		if _, ok := q.allQuestions[i].(registry.CodableQuestion); ok {
+			result = append(result, q.allQuestions[i].GetID())
+		}
+	}
+	return result
+}
+
+func (q Questionnaire) codableQuestion(id string) (registry.CodableQuestion, error) {
+	for i := range q.allQuestions {
+		if q.allQuestions[i].GetID() == id {
+			c, ok := q.allQuestions[i].(registry.CodableQuestion)
+			if !ok {
+				return nil, fmt.Errorf("question %s can not be coded", id)
+			}
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown question %s", id)
+}
+
+// getCoding returns the current coding state of all questions.
+func (q Questionnaire) getCoding() (map[string]codingState, error) {
+	safe, ok := registry.GetDataSafe(config.DataSafe)
+	if !ok {
+		return nil, fmt.Errorf("can not get datasafe %s", config.DataSafe)
+	}
+	data, err := safe.GetData(q.id, []string{registry.CodingQuestion})
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != 1 {
+		return nil, fmt.Errorf("datasafe returned %d question data, expected was 1", len(data))
+	}
+
+	result := make(map[string]codingState)
+	for i := range data[0] {
+		var r codingRecord
+		err := json.Unmarshal([]byte(data[0][i]), &r)
+		if err != nil {
+			log.Printf("coding: can not parse '%s' (%s)", data[0][i], err.Error())
+			continue
+		}
+		state, ok := result[r.Question]
+		if !ok {
+			state.Codes = make(map[int][]string)
+		}
+		switch r.Type {
+		case codingTypeCodebook:
+			state.Codebook = r.Codebook
+		case codingTypeCodes:
+			state.Codes[r.Position] = r.Codes
+		default:
+			log.Printf("coding: unknown record type '%s' (%s)", r.Type, q.id)
+		}
+		result[r.Question] = state
+	}
+	return result, nil
+}
+
+func (q Questionnaire) saveCodingRecords(records []codingRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	safe, ok := registry.GetDataSafe(config.DataSafe)
+	if !ok {
+		return fmt.Errorf("can not get datasafe %s", config.DataSafe)
+	}
+	questionID := make([]string, len(records))
+	data := make([]string, len(records))
+	for i := range records {
+		b, err := json.Marshal(records[i])
+		if err != nil {
+			return err
+		}
+		questionID[i] = registry.CodingQuestion
+		data[i] = string(b)
+	}
+	return safe.SaveData(q.id, questionID, data)
+}
+
+// ParseCodebook parses a codebook with one code per line. Empty lines are ignored.
+func ParseCodebook(s string) ([]string, error) {
+	result := make([]string, 0)
+	known := make(map[string]bool)
+	for _, line := range strings.Split(s, "\n") {
+		code := strings.TrimSpace(line)
+		if code == "" {
+			continue
+		}
+		if utf8.RuneCountInString(code) > 200 {
+			return nil, fmt.Errorf("code '%s' is too long", code)
+		}
+		if known[code] {
+			return nil, fmt.Errorf("code '%s' found twice", code)
+		}
+		known[code] = true
+		result = append(result, code)
+	}
+	return result, nil
+}
+
+// SaveCodebook replaces the codebook of the question.
+func (q Questionnaire) SaveCodebook(question string, codebook []string) error {
+	_, err := q.codableQuestion(question)
+	if err != nil {
+		return err
+	}
+	return q.saveCodingRecords([]codingRecord{{Type: codingTypeCodebook, Question: question, Codebook: codebook}})
+}
+
+// SaveCodes sets the codes of answers of the question, identified by their position.
+// Only answers whose codes changed are stored.
+func (q Questionnaire) SaveCodes(question string, codes map[int][]string) error {
+	_, err := q.codableQuestion(question)
+	if err != nil {
+		return err
+	}
+	coding, err := q.getCoding()
+	if err != nil {
+		return err
+	}
+	state := coding[question]
+	known := make(map[string]bool)
+	for i := range state.Codebook {
+		known[state.Codebook[i]] = true
+	}
+	for p := range codes {
+		for i := range codes[p] {
+			if !known[codes[p][i]] {
+				return fmt.Errorf("code '%s' is not part of the codebook", codes[p][i])
+			}
+		}
+		codes[p] = orderCodes(state.Codebook, codes[p])
+	}
+
+	safe, ok := registry.GetDataSafe(config.DataSafe)
+	if !ok {
+		return fmt.Errorf("can not get datasafe %s", config.DataSafe)
+	}
+	data, err := safe.GetData(q.id, []string{question})
+	if err != nil {
+		return err
+	}
+	if len(data) != 1 {
+		return fmt.Errorf("datasafe returned %d question data, expected was 1", len(data))
+	}
+
+	positions := make([]int, 0, len(codes))
+	for p := range codes {
+		positions = append(positions, p)
+	}
+	sort.Ints(positions)
+
+	records := make([]codingRecord, 0, len(codes))
+	for _, p := range positions {
+		if p < 0 || p >= len(data[0]) {
+			return fmt.Errorf("unknown answer %d", p)
+		}
+		old, coded := state.codesOf(p)
+		if coded && strings.Join(old, "\n") == strings.Join(codes[p], "\n") {
+			continue
+		}
+		records = append(records, codingRecord{Type: codingTypeCodes, Question: question, Position: p, Codes: codes[p]})
+	}
+	return q.saveCodingRecords(records)
+}
+
+// GetCodingPage returns the data of a page of the coding workspace for the question.
+// If uncoded is true, only answers which are not coded yet are included.
+func (q Questionnaire) GetCodingPage(question string, page int, uncoded bool) (codingTemplateStruct, error) {
+	td := codingTemplateStruct{
+		Questions: q.CodableQuestions(),
+		Question:  question,
+		Uncoded:   uncoded,
+	}
+	c, err := q.codableQuestion(question)
+	if err != nil {
+		return td, err
+	}
+	coding, err := q.getCoding()
+	if err != nil {
+		return td, err
+	}
+	state := coding[question]
+	td.Codebook = state.Codebook
+
+	data, _, err := q.getData([]string{question}, ResultFilter{})
+	if err != nil {
+		return td, err
+	}
+	text := c.GetCodableText(data[0])
+
+	positions := make([]int, 0, len(text))
+	for p := range text {
+		if text[p] == "" {
+			continue
+		}
+		if _, coded := state.codesOf(p); uncoded && coded {
+			continue
+		}
+		positions = append(positions, p)
+	}
+
+	td.Pages = (len(positions) + codingPageSize - 1) / codingPageSize
+	if td.Pages == 0 {
+		td.Pages = 1
+	}
+	if page < 0 {
+		page = 0
+	}
+	if page >= td.Pages {
+		page = td.Pages - 1
+	}
+	td.Page = page
+	td.PageNumber = page + 1
+	td.PreviousPage = page - 1
+	td.NextPage = page + 1
+	if td.NextPage >= td.Pages {
+		td.NextPage = -1
+	}
+
+	start := page * codingPageSize
+	end := start + codingPageSize
+	if end > len(positions) {
+		end = len(positions)
+	}
+	for _, p := range positions[start:end] {
+		codes, _ := state.codesOf(p)
+		answer := codingTemplateStructAnswer{
+			Position: p,
+			Number:   p + 1,
+			Text:     text[p],
+			Codes:    make([]codingTemplateStructCode, len(state.Codebook)),
+		}
+		for i := range state.Codebook {
+			answer.Codes[i].Code = state.Codebook[i]
+			for j := range codes {
+				if codes[j] == state.Codebook[i] {
+					answer.Codes[i].Checked = true
+					break
+				}
+			}
+		}
+		td.Answers = append(td.Answers, answer)
+	}
+	return td, nil
+}
+
+// codeFrequencies returns a save HTML fragment with the frequency of each code among the answers at the positions.
+// Small counts are suppressed according to the options.
+func codeFrequencies(id string, state codingState, text []string, positions []int, options registry.DisplayOptions) template.HTML {
+	td := codeFrequenciesTemplateStruct{
+		Codes: make([]codeFrequenciesTemplateStructInner, len(state.Codebook)),
+	}
+	index := make(map[string]int)
+	for i := range state.Codebook {
+		td.Codes[i].Code = state.Codebook[i]
+		index[state.Codebook[i]] = i
+	}
+	for i, p := range positions {
+		if i >= len(text) || text[i] == "" {
+			continue
+		}
+		codes, coded := state.codesOf(p)
+		if !coded {
+			td.Uncoded++
+			continue
+		}
+		td.Coded++
+		for j := range codes {
+			td.Codes[index[codes[j]]].Number++
+		}
+	}
+
+	v := make([]helper.ChartValue, len(td.Codes))
+	for i := range td.Codes {
+		if td.Coded > 0 {
+			td.Codes[i].Percent = float64(td.Codes[i].Number) / float64(td.Coded)
+		}
+		td.Codes[i].Suppressed = options.Suppressed(td.Codes[i].Number)
+		v[i].Label = td.Codes[i].Code
+		if !td.Codes[i].Suppressed {
+			v[i].Value = float64(td.Codes[i].Number)
+		}
+	}
+	td.CodedSuppressed = options.Suppressed(td.Coded)
+	td.UncodedSuppressed = options.Suppressed(td.Uncoded)
+	td.Image = helper.BarChart(v, fmt.Sprintf("%s__codes", id), "Codes")
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := codeFrequenciesTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("coding: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+// codeColumns returns the CSV header and values of the code columns of the question.
+// For each code there is one column containing 1 if the answer has the code, 0 if it does not have it and nothing if the answer is not coded.
+func codeColumns(id string, state codingState, positions []int) ([]string, [][]string) {
+	header := make([]string, len(state.Codebook))
+	for i := range state.Codebook {
+		header[i] = fmt.Sprintf("%s_code_%s", id, state.Codebook[i])
+	}
+	values := make([][]string, len(positions))
+	for i, p := range positions {
+		values[i] = make([]string, len(state.Codebook))
+		codes, coded := state.codesOf(p)
+		if !coded {
+			continue
+		}
+		for j := range state.Codebook {
+			values[i][j] = "0"
+			for k := range codes {
+				if codes[k] == state.Codebook[j] {
+					values[i][j] = "1"
+					break
+				}
+			}
+		}
+	}
+	return header, values
+}
@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"math"
+
+	"github.com/Top-Ranger/questiongo/helper"
+	"github.com/Top-Ranger/questiongo/registry"
+)
+
+var groupComparisonTemplate = template.Must(template.New("groupComparisonTemplate").Parse(`<h2>Group comparison: {{.Group}}</h2>
+<div style="width: 100%; overflow-x: auto;">
+<table>
+<thead>
+<tr>
+<th>Question</th>
+<th>Test</th>
+{{range $i, $e := .GroupLabels }}
+<th>{{$e}}</th>
+{{end}}
+<th>Statistic</th>
+<th>p</th>
+<th>p (Holm)</th>
+<th>Effect size</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Rows }}
+<tr>
+<td>{{$e.Question}}</td>
+<td>{{$e.Test}}</td>
+{{range $I, $E := $e.Groups }}
+<td>{{$E}}</td>
+{{end}}
+{{if $e.Valid}}
+<td>{{$e.Statistic}}</td>
+<td>{{printf "%.4f" $e.P}}</td>
+<td>{{if $e.Significant}}<strong>{{printf "%.4f" $e.PAdjusted}}</strong>{{else}}{{printf "%.4f" $e.PAdjusted}}{{end}}</td>
+<td>{{$e.Effect}}</td>
+{{else}}
+<td colspan="4">not enough data</td>
+{{end}}
+</tr>
+{{end}}
+</tbody>
+</table>
+</div>
+<p><small>Numeric questions show mean (standard deviation, n) and are compared with Welch's t-test (Cohen's d) for two groups and a one-way ANOVA (η²) for more groups. Categorical questions show n and are compared with a chi-square test (Cramér's V).
+p-values are corrected for {{.Tests}} tests with the Holm-Bonferroni method; values below 0.05 are highlighted. Responses without a group are ignored.</small></p>
+`))
+
+type groupComparisonTemplateStruct struct {
+	Group       string
+	GroupLabels []string
+	Rows        []groupComparisonTemplateStructRow
+	Tests       int
+}
+
+type groupComparisonTemplateStructRow struct {
+	Question    string
+	Test        string
+	Groups      []string
+	Valid       bool
+	Statistic   string
+	P           float64
+	PAdjusted   float64
+	Significant bool
+	Effect      string
+}
+
+// GetGroupComparison returns a save html fragment comparing the answers to all numeric and categorical questions between the groups of a categorical question.
+// Only responses matching the filter are included. Small groups are suppressed (see MinCellSize).
+func (q Questionnaire) GetGroupComparison(group string, filter ResultFilter) (template.HTML, error) {
+	var groupQuestion registry.CategoricalQuestion
+	ids := make([]string, len(q.allQuestions))
+	for i := range q.allQuestions {
+		ids[i] = q.allQuestions[i].GetID()
+		if cq, ok := q.allQuestions[i].(registry.CategoricalQuestion); ok && ids[i] == group {
+			groupQuestion = cq
+		}
+	}
+	if groupQuestion == nil {
+		return "", fmt.Errorf("question %s is not categorical", group)
+	}
+
+	data, _, err := q.getData(ids, filter)
+	if err != nil {
+		return "", err
+	}
+
+	td := groupComparisonTemplateStruct{
+		Group:       group,
+		GroupLabels: groupQuestion.GetCategoryLabels(),
+	}
+
+	var groups []int
+	for i := range ids {
+		if ids[i] == group {
+			groups = groupQuestion.GetCategories(data[i])
+			break
+		}
+	}
+
+	options := registry.DisplayOptions{MinCellSize: q.MinCellSize}
+	p := make([]float64, 0)
+	tested := make([]int, 0)
+	for i := range q.allQuestions {
+		if ids[i] == group {
+			continue
+		}
+
+		var row groupComparisonTemplateStructRow
+		switch question := q.allQuestions[i].(type) {
+		case registry.NumericQuestion:
+			if !question.IsNumeric() {
+				continue
+			}
+			row = compareNumeric(question.GetValues(data[i]), groups, len(td.GroupLabels), options)
+		case registry.CategoricalQuestion:
+			row = compareCategorical(question.GetCategories(data[i]), len(question.GetCategoryLabels()), groups, len(td.GroupLabels), options)
+		default:
+			continue
+		}
+		row.Question = ids[i]
+		if row.Valid {
+			p = append(p, row.P)
+			tested = append(tested, len(td.Rows))
+		}
+		td.Rows = append(td.Rows, row)
+	}
+
+	adjusted := helper.HolmCorrection(p)
+	for i := range tested {
+		td.Rows[tested[i]].PAdjusted = adjusted[i]
+		td.Rows[tested[i]].Significant = adjusted[i] < 0.05
+	}
+	td.Tests = len(p)
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err = groupComparisonTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("group comparison: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes()), nil
+}
+
+// compareNumeric compares the values between the groups.
+func compareNumeric(values []float64, groups []int, numberGroups int, options registry.DisplayOptions) groupComparisonTemplateStructRow {
+	samples := make([][]float64, numberGroups)
+	for i := range values {
+		if i >= len(groups) || groups[i] < 0 || groups[i] >= numberGroups || math.IsNaN(values[i]) {
+			continue
+		}
+		samples[groups[i]] = append(samples[groups[i]], values[i])
+	}
+
+	row := groupComparisonTemplateStructRow{
+		Groups: make([]string, numberGroups),
+	}
+	nonEmpty := make([][]float64, 0, numberGroups)
+	for g := range samples {
+		if len(samples[g]) == 0 {
+			row.Groups[g] = "-"
+			continue
+		}
+		nonEmpty = append(nonEmpty, samples[g])
+		if options.Suppressed(len(samples[g])) {
+			row.Groups[g] = "[suppressed]"
+			continue
+		}
+		d := helper.DescriptiveStatistics(samples[g])
+		row.Groups[g] = fmt.Sprintf("%.2f (%.2f, %d)", d.Mean, d.SD, d.N)
+	}
+
+	if len(nonEmpty) == 2 {
+		row.Test = "t-test (Welch)"
+		r, ok := helper.WelchTTest(nonEmpty[0], nonEmpty[1])
+		if ok {
+			row.Valid = true
+			row.Statistic = fmt.Sprintf("t(%.2f) = %.2f", r.DF, r.T)
+			row.P = r.P
+			row.Effect = fmt.Sprintf("d = %.2f", r.CohensD)
+		}
+		return row
+	}
+
+	row.Test = "ANOVA"
+	r, ok := helper.OneWayANOVA(nonEmpty)
+	if ok {
+		row.Valid = true
+		row.Statistic = fmt.Sprintf("F(%d, %d) = %.2f", r.DFBetween, r.DFWithin, r.F)
+		row.P = r.P
+		row.Effect = fmt.Sprintf("η² = %.2f", r.EtaSquared)
+	}
+	return row
+}
+
+// compareCategorical compares the distribution of the categories between the groups.
+func compareCategorical(categories []int, numberCategories int, groups []int, numberGroups int, options registry.DisplayOptions) groupComparisonTemplateStructRow {
+	table := make([][]int, numberGroups)
+	for g := range table {
+		table[g] = make([]int, numberCategories)
+	}
+	n := make([]int, numberGroups)
+	for i := range categories {
+		if i >= len(groups) || groups[i] < 0 || groups[i] >= numberGroups || categories[i] < 0 || categories[i] >= numberCategories {
+			continue
+		}
+		table[groups[i]][categories[i]]++
+		n[groups[i]]++
+	}
+
+	row := groupComparisonTemplateStructRow{
+		Test:   "chi-square",
+		Groups: make([]string, numberGroups),
+	}
+	for g := range n {
+		if options.Suppressed(n[g]) {
+			row.Groups[g] = "[suppressed]"
+			continue
+		}
+		row.Groups[g] = fmt.Sprintf("n = %d", n[g])
+	}
+
+	r, ok := helper.ChiSquareTest(table)
+	if ok {
+		row.Valid = true
+		row.Statistic = fmt.Sprintf("χ²(%d) = %.2f", r.DF, r.Statistic)
+		row.P = r.P
+		row.Effect = fmt.Sprintf("V = %.2f", r.CramersV)
+	}
+	return row
+}
@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+
+	"github.com/Top-Ranger/questiongo/helper"
+	"github.com/Top-Ranger/questiongo/registry"
+)
+
+var crossTabTemplate = template.Must(template.New("crossTabTemplate").Parse(`<h2>{{.Row}} &times; {{.Column}}</h2>
+<div style="width: 100%; overflow-x: auto;">
+<table>
+<thead>
+<tr>
+<th>{{.Row}} \ {{.Column}}</th>
+{{range $i, $e := .ColumnLabels }}
+<th>{{$e}}</th>
+{{end}}
+<th>Total</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Cells }}
+<tr>
+<td>{{index $.RowLabels $i}}</td>
+{{range $I, $E := $e }}
+{{if $E.Suppressed}}
+<td class="centre">[suppressed]</td>
+{{else}}
+<td class="centre">{{$E.Number}}<br><small>{{if $E.RowPercentSuppressed}}[suppressed]{{else}}{{printf "%.1f" $E.RowPercent}}%{{end}} | {{if $E.ColumnPercentSuppressed}}[suppressed]{{else}}{{printf "%.1f" $E.ColumnPercent}}%{{end}}</small></td>
+{{end}}
+{{end}}
+<td class="centre th-cell">{{if index $.RowTotalSuppressed $i}}[suppressed]{{else}}{{index $.RowTotal $i}}{{end}}</td>
+</tr>
+{{end}}
+<tr>
+<td class="th-cell"><strong>Total</strong></td>
+{{range $i, $e := .ColumnTotal }}
+<td class="centre th-cell">{{if index $.ColumnTotalSuppressed $i}}[suppressed]{{else}}{{$e}}{{end}}</td>
+{{end}}
+<td class="centre th-cell">{{if .TotalSuppressed}}[suppressed]{{else}}{{.Total}}{{end}}</td>
+</tr>
+</tbody>
+</table>
+</div>
+<p><small>Cells: number<br>row percentage | column percentage</small></p>
+{{if .CellsSuppressed}}<p><small>Totals and percentages of rows and columns with suppressed cells are suppressed as well, so that the suppressed cells can not be calculated. These rows are not included in the chart.</small></p>{{end}}
+{{if .Missing}}<p>{{if .MissingSuppressed}}Some{{else}}{{.Missing}}{{end}} responses without an answer to both questions are not included.</p>{{end}}
+{{if .ChiSquareValid}}
+<p><strong>&chi;&sup2;</strong> = {{printf "%.3f" .ChiSquare.Statistic}}, df = {{.ChiSquare.DF}}, p = {{printf "%.4f" .ChiSquare.P}}, Cram&eacute;r's V = {{printf "%.3f" .ChiSquare.CramersV}}</p>
+{{if gt .ChiSquare.LowExpected 0.2}}<p><em>Warning: {{printf "%.0f" .LowExpectedPercent}}% of the cells have an expected count below 5. The chi-square test might not be reliable.</em></p>{{end}}
+{{else}}
+<p><em>The chi-square test needs at least two rows and two columns with answers.</em></p>
+{{end}}
+{{.Image}}
+`))
+
+type crossTabTemplateStruct struct {
+	Row                   string
+	Column                string
+	RowLabels             []string
+	ColumnLabels          []string
+	Cells                 [][]crossTabCell
+	RowTotal              []int
+	RowTotalSuppressed    []bool
+	ColumnTotal           []int
+	ColumnTotalSuppressed []bool
+	Total                 int
+	TotalSuppressed       bool
+	Missing               int
+	MissingSuppressed     bool
+	CellsSuppressed       bool
+	ChiSquare             helper.ChiSquareResult
+	ChiSquareValid        bool
+	LowExpectedPercent    float64
+	Image                 template.HTML
+}
+
+type crossTabCell struct {
+	Number                  int
+	RowPercent              float64
+	RowPercentSuppressed    bool
+	ColumnPercent           float64
+	ColumnPercentSuppressed bool
+	Suppressed              bool
+}
+
+// CategoricalQuestions returns the IDs of all questions which can be used for cross-tabulation.
+func (q Questionnaire) CategoricalQuestions() []string {
+	result := make([]string, 0)
+	for i := range q.allQuestions {
+		if _, ok := q.allQuestions[i].(registry.CategoricalQuestion); ok {
+			result = append(result, q.allQuestions[i].GetID())
+		}
+	}
+	return result
+}
+
+// GetCrossTab returns a save html fragment containing the cross-tabulation of two categorical questions.
+// Only responses matching the filter are included. Small counts are suppressed (see MinCellSize).
+func (q Questionnaire) GetCrossTab(row, column string, filter ResultFilter) (template.HTML, error) {
+	var rowQuestion, columnQuestion registry.CategoricalQuestion
+	for i := range q.allQuestions {
+		cq, ok := q.allQuestions[i].(registry.CategoricalQuestion)
+		if !ok {
+			continue
+		}
+		if cq.GetID() == row {
+			rowQuestion = cq
+		}
+		if cq.GetID() == column {
+			columnQuestion = cq
+		}
+	}
+	if rowQuestion == nil {
+		return "", fmt.Errorf("question %s is not categorical", row)
+	}
+	if columnQuestion == nil {
+		return "", fmt.Errorf("question %s is not categorical", column)
+	}
+
+	data, _, err := q.getData([]string{row, column}, filter)
+	if err != nil {
+		return "", err
+	}
+
+	td := crossTabTemplateStruct{
+		Row:          row,
+		Column:       column,
+		RowLabels:    rowQuestion.GetCategoryLabels(),
+		ColumnLabels: columnQuestion.GetCategoryLabels(),
+	}
+
+	table := make([][]int, len(td.RowLabels))
+	for r := range table {
+		table[r] = make([]int, len(td.ColumnLabels))
+	}
+	td.RowTotal = make([]int, len(td.RowLabels))
+	td.ColumnTotal = make([]int, len(td.ColumnLabels))
+
+	rowCategories := rowQuestion.GetCategories(data[0])
+	columnCategories := columnQuestion.GetCategories(data[1])
+	for i := range rowCategories {
+		if i >= len(columnCategories) || rowCategories[i] < 0 || columnCategories[i] < 0 || rowCategories[i] >= len(table) || columnCategories[i] >= len(td.ColumnLabels) {
+			td.Missing++
+			continue
+		}
+		table[rowCategories[i]][columnCategories[i]]++
+		td.RowTotal[rowCategories[i]]++
+		td.ColumnTotal[columnCategories[i]]++
+		td.Total++
+	}
+
+	// A suppressed cell could be calculated from the total and the other cells of its row or column,
+	// so totals and percentages of rows and columns containing a suppressed cell are suppressed as well.
+	options := registry.DisplayOptions{MinCellSize: q.MinCellSize}
+	rowSuppressed := make([]bool, len(table))
+	columnSuppressed := make([]bool, len(td.ColumnLabels))
+	for r := range table {
+		for c := range table[r] {
+			if options.Suppressed(table[r][c]) {
+				rowSuppressed[r] = true
+				columnSuppressed[c] = true
+				td.CellsSuppressed = true
+			}
+		}
+	}
+	td.RowTotalSuppressed = make([]bool, len(td.RowTotal))
+	for r := range td.RowTotal {
+		td.RowTotalSuppressed[r] = rowSuppressed[r] || options.Suppressed(td.RowTotal[r])
+	}
+	td.ColumnTotalSuppressed = make([]bool, len(td.ColumnTotal))
+	for c := range td.ColumnTotal {
+		td.ColumnTotalSuppressed[c] = columnSuppressed[c] || options.Suppressed(td.ColumnTotal[c])
+	}
+	td.TotalSuppressed = td.CellsSuppressed || options.Suppressed(td.Total)
+	td.MissingSuppressed = options.Suppressed(td.Missing)
+
+	td.Cells = make([][]crossTabCell, len(table))
+	chart := make([][]float64, 0, len(table))
+	chartLabels := make([]string, 0, len(table))
+	for r := range table {
+		td.Cells[r] = make([]crossTabCell, len(table[r]))
+		for c := range table[r] {
+			td.Cells[r][c].Number = table[r][c]
+			td.Cells[r][c].Suppressed = options.Suppressed(table[r][c])
+			td.Cells[r][c].RowPercentSuppressed = rowSuppressed[r]
+			td.Cells[r][c].ColumnPercentSuppressed = columnSuppressed[c]
+			if td.RowTotal[r] > 0 {
+				td.Cells[r][c].RowPercent = 100 * float64(table[r][c]) / float64(td.RowTotal[r])
+			}
+			if td.ColumnTotal[c] > 0 {
+				td.Cells[r][c].ColumnPercent = 100 * float64(table[r][c]) / float64(td.ColumnTotal[c])
+			}
+		}
+		// The chart shows row percentages, so rows with suppressed cells are left out instead of distorting them
+		if rowSuppressed[r] {
+			continue
+		}
+		values := make([]float64, len(table[r]))
+		for c := range table[r] {
+			values[c] = float64(table[r][c])
+		}
+		chart = append(chart, values)
+		chartLabels = append(chartLabels, td.RowLabels[r])
+	}
+
+	td.ChiSquare, td.ChiSquareValid = helper.ChiSquareTest(table)
+	td.LowExpectedPercent = 100 * td.ChiSquare.LowExpected
+	td.Image = helper.Stacked100Chart(chart, "__crosstab", chartLabels, td.ColumnLabels, fmt.Sprintf("%s × %s", row, column))
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err = crossTabTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("crosstab: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes()), nil
+}
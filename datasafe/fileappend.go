@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2021,2022 Marcus Soll
+// Copyright 2020,2021,2022,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -31,7 +31,7 @@ import (
 func init() {
 	fa := &fileAppend{}
 	fa.newPath = make(chan string)
-	fa.data = make(chan []fileAppendResult)
+	fa.buffer = make([]fileAppendResult, 0, 10)
 	fa.close = make(chan bool)
 	fa.isClosed = make(chan bool)
 	err := registry.RegisterDataSafe(fa, "fileappend")
@@ -64,13 +64,15 @@ func (f fileAppendResultBuffer) Swap(i, j int) {
 }
 
 type fileAppend struct {
-	path     string
-	mutex    sync.Mutex
-	start    sync.Once
-	newPath  chan string
-	data     chan []fileAppendResult
-	close    chan bool
-	isClosed chan bool
+	path        string
+	mutex       sync.Mutex // Guards the files
+	start       sync.Once
+	newPath     chan string
+	bufferMutex sync.Mutex // Guards buffer and running
+	buffer      fileAppendResultBuffer
+	running     bool
+	close       chan bool
+	isClosed    chan bool
 }
 
 func (fa *fileAppend) SaveData(questionnaireID string, questionID, data []string) error {
@@ -86,7 +88,13 @@ func (fa *fileAppend) SaveData(questionnaireID string, questionID, data []string
 		d[i].data = data[i]
 	}
 
-	fa.data <- d
+	fa.bufferMutex.Lock()
+	defer fa.bufferMutex.Unlock()
+	if !fa.running {
+		fmt.Printf("FileAppend: Not saving result - worker not running (%v)", d)
+		return nil
+	}
+	fa.buffer = append(fa.buffer, d...)
 	return nil
 }
 
@@ -112,9 +120,92 @@ func (fa *fileAppend) GetData(questionnaireID string, questionID []string) ([][]
 			return nil, err
 		}
 	}
+
+	// Add data which is not flushed yet. Since we hold the file lock, no flush is running.
+	fa.bufferMutex.Lock()
+	defer fa.bufferMutex.Unlock()
+	for i := range questionID {
+		for _, j := range fa.bufferedUnsafe(questionnaireID, questionID[i]) {
+			result[i] = append(result[i], fa.buffer[j].data)
+		}
+	}
 	return result, nil
 }
 
+func (fa *fileAppend) UpdateData(questionnaireID, responseID string, questionID, data []string) error {
+	if len(questionID) != len(data) {
+		return fmt.Errorf("FileAppend: len(questionID)=%d does not match len(data)=%d", len(questionID), len(data))
+	}
+
+	fa.mutex.Lock()
+	defer fa.mutex.Unlock()
+	fa.bufferMutex.Lock()
+	defer fa.bufferMutex.Unlock()
+
+	responses, err := fa.getSingleDataUnsafeParallel(questionnaireID, registry.ResponseIDQuestion)
+	if err != nil {
+		return err
+	}
+	for _, j := range fa.bufferedUnsafe(questionnaireID, registry.ResponseIDQuestion) {
+		responses = append(responses, fa.buffer[j].data)
+	}
+
+	// Find all positions first, so that unknown responses do not lead to partial updates.
+	stored := make([][]string, len(questionID))
+	buffered := make([][]int, len(questionID))
+	index := make([]int, len(questionID))
+	for i := range questionID {
+		stored[i], err = fa.getSingleDataUnsafeParallel(questionnaireID, questionID[i])
+		if err != nil {
+			return err
+		}
+		buffered[i] = fa.bufferedUnsafe(questionnaireID, questionID[i])
+		var ok bool
+		index[i], ok = registry.ResponseIndex(responses, len(stored[i])+len(buffered[i]), responseID)
+		if !ok {
+			return fmt.Errorf("FileAppend: unknown response %s for %s/%s", responseID, questionnaireID, questionID[i])
+		}
+	}
+
+	for i := range questionID {
+		if index[i] >= len(stored[i]) {
+			fa.buffer[buffered[i][index[i]-len(stored[i])]].data = data[i]
+			continue
+		}
+
+		stored[i][index[i]] = data[i]
+		var sb strings.Builder
+		for j := range stored[i] {
+			sb.WriteString(fileAppendEscape(stored[i][j]))
+			sb.WriteString("\n")
+		}
+
+		// Write to a temporary file first so that a failed write does not destroy existing data.
+		path := filepath.Join(fa.path, questionnaireID, questionID[i])
+		err = os.WriteFile(path+".tmp", []byte(sb.String()), os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("FileAppend: Can not write to %s: %w", path+".tmp", err)
+		}
+		err = os.Rename(path+".tmp", path)
+		if err != nil {
+			return fmt.Errorf("FileAppend: Can not replace %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func (fa *fileAppend) bufferedUnsafe(questionnaireID, questionID string) []int {
+	// Caller must lock bufferMutex
+
+	result := make([]int, 0)
+	for i := range fa.buffer {
+		if fa.buffer[i].questionnaireID == questionnaireID && fa.buffer[i].questionID == questionID {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
 func (fa *fileAppend) getSingleDataUnsafeParallel(questionnaireID, questionID string) ([]string, error) {
 	// Caller must lock
 
@@ -133,6 +224,11 @@ func (fa *fileAppend) getSingleDataUnsafeParallel(questionnaireID, questionID st
 	return split, nil
 }
 
+func fileAppendEscape(s string) string {
+	s = strings.ReplaceAll(s, "󰀕", "") // Remove invalid characters. This are not allowed to be used anyway
+	return strings.ReplaceAll(s, "\n", "󰀕")
+}
+
 func (fa *fileAppend) FlushAndClose() {
 	select {
 	case fa.close <- true:
@@ -142,21 +238,18 @@ func (fa *fileAppend) FlushAndClose() {
 }
 
 func (fa *fileAppend) fileappendWorker() {
-	workerLock := sync.Mutex{}
-	buffer := fileAppendResultBuffer(make([]fileAppendResult, 0, 10))
 	tick := time.NewTicker(5 * time.Second)
 	flushTries := 0
-	running := false
 	closeWorker := false
 	for {
 		select {
 		case <-fa.close:
-			workerLock.Lock()
+			fa.bufferMutex.Lock()
 			if !closeWorker {
 				log.Printf("FileAppend: starting flush")
 				closeWorker = true
 			}
-			workerLock.Unlock()
+			fa.bufferMutex.Unlock()
 		case p := <-fa.newPath:
 			if closeWorker {
 				log.Printf("FileAppend: Ignoring new path %s since close has been called.", p)
@@ -165,25 +258,17 @@ func (fa *fileAppend) fileappendWorker() {
 			func() {
 				fa.mutex.Lock()
 				defer fa.mutex.Unlock()
-				workerLock.Lock()
-				defer workerLock.Unlock()
+				fa.bufferMutex.Lock()
+				defer fa.bufferMutex.Unlock()
 				fa.path = p
 				err := os.MkdirAll(fa.path, os.ModePerm)
 				if err != nil {
 					log.Printf("FileAppend: Can not create %s: %s", p, err.Error())
 				} else {
-					running = true
-					buffer = make([]fileAppendResult, 0, 10)
+					fa.running = true
+					fa.buffer = make([]fileAppendResult, 0, 10)
 				}
 			}()
-		case d := <-fa.data:
-			if !running {
-				fmt.Printf("FileAppend: Not saving result - worker not running (%v)", d)
-				continue
-			}
-			workerLock.Lock()
-			buffer = append(buffer, d...)
-			workerLock.Unlock()
 		case <-tick.C:
 			flushTries++
 			locked := fa.mutex.TryLock()
@@ -195,21 +280,27 @@ func (fa *fileAppend) fileappendWorker() {
 						fa.mutex.Lock()
 					}
 					defer fa.mutex.Unlock()
-					workerLock.Lock()
-					b := buffer
-					newLen := len(buffer) * 2
+					fa.bufferMutex.Lock()
+					b := fa.buffer
+					newLen := len(fa.buffer) * 2
 					if newLen < 10 {
 						newLen = 10
 					}
-					buffer = make([]fileAppendResult, 0, newLen)
-					workerLock.Unlock()
+					fa.buffer = make([]fileAppendResult, 0, newLen)
+					fa.bufferMutex.Unlock()
+
+					stop := func() {
+						fa.bufferMutex.Lock()
+						fa.running = false
+						fa.bufferMutex.Unlock()
+					}
 
 					sort.Stable(b) // We need to preserve the order of the answers
 					for i := 0; i < len(b); i++ {
 						err := os.MkdirAll(filepath.Join(fa.path, b[i].questionnaireID), os.ModePerm)
 						if err != nil {
 							log.Printf("FileAppend: Can not create %s: %s", filepath.Join(fa.path, b[i].questionnaireID), err.Error())
-							running = false
+							stop()
 							return
 						}
 
@@ -218,7 +309,7 @@ func (fa *fileAppend) fileappendWorker() {
 							f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, os.ModePerm)
 							if err != nil {
 								log.Printf("FileAppend: Can not create %s: %s", path, err.Error())
-								running = false
+								stop()
 								return
 							}
 							defer f.Close()
@@ -226,21 +317,19 @@ func (fa *fileAppend) fileappendWorker() {
 							write := true
 							for write {
 								write = false
-								s := strings.ReplaceAll(b[i].data, "󰀕", "") // Remove invalid characters. This are not allowed to be used anyway
-								s = strings.ReplaceAll(s, "\n", "󰀕")
-								_, err = f.Write([]byte(s))
+								_, err = f.Write([]byte(fileAppendEscape(b[i].data)))
 								if err != nil {
 									log.Printf("FileAppend: Can not write to %s: %s", path, err.Error())
-									running = false
+									stop()
 									return
 								}
 								_, err = f.Write([]byte("\n"))
 								if err != nil {
 									log.Printf("FileAppend: Can not write to %s: %s", path, err.Error())
-									running = false
+									stop()
 									return
 								}
-								for i < len(b)-1 && b[i+1].questionnaireID == b[i].questionnaireID && b[i+1].questionID == b[i].questionID {
+								if i < len(b)-1 && b[i+1].questionnaireID == b[i].questionnaireID && b[i+1].questionID == b[i].questionID {
 									write = true
 									i++
 								}
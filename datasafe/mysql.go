@@ -1,7 +1,7 @@
 //go:build mysql
 
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2021,2022 Marcus Soll
+// Copyright 2021,2022,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -161,6 +161,87 @@ func (m *mySQL) GetData(questionnaireID string, questionID []string) ([][]string
 	return result, nil
 }
 
+func (m *mySQL) UpdateData(questionnaireID, responseID string, questionID, data []string) error {
+	if m.db == nil {
+		return ErrMySQLNotConfigured
+	}
+
+	if len(questionnaireID) > MySQLMaxLengthID {
+		return ErrMySQLIDtooLong
+	}
+
+	if len(questionID) != len(data) {
+		return fmt.Errorf("mysql: len(questionID)=%d does not match len(data)=%d", len(questionID), len(data))
+	}
+
+	for i := range questionID {
+		if len(questionID[i]) > MySQLMaxLengthID {
+			return ErrMySQLIDtooLong
+		}
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	successful := false
+
+	defer func() {
+		if !successful {
+			err := tx.Rollback()
+			if err != nil {
+				log.Printf("mysql: can not rollback transaction: %s", err.Error())
+			}
+		}
+	}()
+
+	rows, err := tx.Query("SELECT data FROM data WHERE questionnaire=? AND question=? ORDER BY id ASC", questionnaireID, registry.ResponseIDQuestion)
+	if err != nil {
+		return err
+	}
+	responses := make([]string, 0)
+	for rows.Next() {
+		var s string
+		err = rows.Scan(&s)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		responses = append(responses, s)
+	}
+	rows.Close()
+
+	for i := range questionID {
+		var n int
+		err = tx.QueryRow("SELECT COUNT(*) FROM data WHERE questionnaire=? AND question=?", questionnaireID, questionID[i]).Scan(&n)
+		if err != nil {
+			return err
+		}
+		index, ok := registry.ResponseIndex(responses, n, responseID)
+		if !ok {
+			return fmt.Errorf("mysql: unknown response %s for %s/%s", responseID, questionnaireID, questionID[i])
+		}
+		var id int64
+		err = tx.QueryRow("SELECT id FROM data WHERE questionnaire=? AND question=? ORDER BY id ASC LIMIT 1 OFFSET ?", questionnaireID, questionID[i], index).Scan(&id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE data SET data=? WHERE id=?", data[i], id)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	successful = true
+	return nil
+}
+
 func (m *mySQL) FlushAndClose() {
 	if m.db == nil {
 		return
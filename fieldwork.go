@@ -0,0 +1,348 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Top-Ranger/questiongo/helper"
+	"github.com/Top-Ranger/questiongo/registry"
+)
+
+var fieldworkTemplate = template.Must(template.New("fieldworkTemplate").Parse(`{{if .Days}}
+<table>
+<thead>
+<tr>
+<th>Day</th>
+<th>Start page views</th>
+<th>Questionnaire views</th>
+<th>Submissions</th>
+<th>Screened out</th>
+<th>Completion rate (start page)</th>
+<th>Completion rate (questionnaire)</th>
+<th>Submissions (cumulative)</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Days}}
+<tr>
+<td>{{$e.Day}}</td>
+<td>{{$e.Start}}</td>
+<td>{{$e.Main}}</td>
+<td>{{$e.Submissions}}</td>
+<td>{{$e.ScreenOuts}}</td>
+<td>{{$e.RateStart}}</td>
+<td>{{$e.RateMain}}</td>
+<td>{{$e.Cumulative}}</td>
+</tr>
+{{end}}
+<tr>
+<td class="th-cell">[total]</td>
+<td>{{.Total.Start}}</td>
+<td>{{.Total.Main}}</td>
+<td>{{.Total.Submissions}}</td>
+<td>{{.Total.ScreenOuts}}</td>
+<td>{{.Total.RateStart}}</td>
+<td>{{.Total.RateMain}}</td>
+<td>{{.Total.Cumulative}}</td>
+</tr>
+</tbody>
+</table>
+{{.Daily}}
+{{.Cumulative}}
+{{else}}
+<p>No fieldwork data recorded yet.</p>
+{{end}}
+<p><small>Counts are recorded per day without any data of single respondents. Page views include reloads. Screened out participants are not counted as submissions. Responses submitted before counting started are not included.</small></p>
+`))
+
+type fieldworkTemplateStruct struct {
+	Days       []fieldworkTemplateStructInner
+	Total      fieldworkTemplateStructInner
+	Daily      template.HTML
+	Cumulative template.HTML
+}
+
+type fieldworkTemplateStructInner struct {
+	Day         string
+	Start       int
+	Main        int
+	Submissions int
+	ScreenOuts  int
+	RateStart   string
+	RateMain    string
+	Cumulative  int
+}
+
+// fieldworkEvent is an event counted for fieldwork statistics.
+type fieldworkEvent int
+
+const (
+	fieldworkStart      fieldworkEvent = iota // start page was shown
+	fieldworkMain                             // questionnaire was shown
+	fieldworkSubmission                       // answers were saved
+	fieldworkScreenOut                        // answers were dropped, e.g. because the participant was screened out
+)
+
+// fieldworkRecord holds the counts of a questionnaire for a single day.
+// Records are stored as deltas, so there might be multiple records for a day.
+type fieldworkRecord struct {
+	Day         string
+	Start       int `json:",omitempty"`
+	Main        int `json:",omitempty"`
+	Submissions int `json:",omitempty"`
+	ScreenOuts  int `json:",omitempty"`
+}
+
+func (r *fieldworkRecord) add(other fieldworkRecord) {
+	r.Start += other.Start
+	r.Main += other.Main
+	r.Submissions += other.Submissions
+	r.ScreenOuts += other.ScreenOuts
+}
+
+var (
+	fieldworkPending     = make(map[string]map[string]fieldworkRecord) // questionnaire -> day -> counts not yet stored
+	fieldworkMutex       sync.Mutex                                    // Guards fieldworkPending
+	fieldworkWorkerStart sync.Once
+	fieldworkClose       = make(chan bool)
+	fieldworkClosed      = make(chan bool)
+)
+
+// countFieldwork counts an event for the questionnaire.
+// Counts are kept in memory and are periodically stored in the data safe.
+func countFieldwork(questionnaireID string, event fieldworkEvent) {
+	fieldworkWorkerStart.Do(func() {
+		go fieldworkWorker()
+	})
+
+	day := time.Now().Format("2006-01-02")
+
+	fieldworkMutex.Lock()
+	defer fieldworkMutex.Unlock()
+	if fieldworkPending[questionnaireID] == nil {
+		fieldworkPending[questionnaireID] = make(map[string]fieldworkRecord)
+	}
+	r := fieldworkPending[questionnaireID][day]
+	r.Day = day
+	switch event {
+	case fieldworkStart:
+		r.Start++
+	case fieldworkMain:
+		r.Main++
+	case fieldworkSubmission:
+		r.Submissions++
+	case fieldworkScreenOut:
+		r.ScreenOuts++
+	}
+	fieldworkPending[questionnaireID][day] = r
+}
+
+// flushFieldwork stores all pending counts in the data safe.
+// Counts which can not be stored are kept for the next try.
+func flushFieldwork() {
+	fieldworkMutex.Lock()
+	pending := fieldworkPending
+	fieldworkPending = make(map[string]map[string]fieldworkRecord)
+	fieldworkMutex.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	safe, ok := registry.GetDataSafe(config.DataSafe)
+	if !ok {
+		log.Printf("fieldwork: can not get datasafe %s", config.DataSafe)
+		return
+	}
+
+	for questionnaireID := range pending {
+		for day := range pending[questionnaireID] {
+			r := pending[questionnaireID][day]
+			b, err := json.Marshal(r)
+			if err == nil {
+				err = safe.SaveData(questionnaireID, []string{registry.FieldworkQuestion}, []string{string(b)})
+			}
+			if err != nil {
+				log.Printf("fieldwork: can not save counts for %s (%s)", questionnaireID, err.Error())
+				fieldworkMutex.Lock()
+				if fieldworkPending[questionnaireID] == nil {
+					fieldworkPending[questionnaireID] = make(map[string]fieldworkRecord)
+				}
+				old := fieldworkPending[questionnaireID][day]
+				old.Day = day
+				old.add(r)
+				fieldworkPending[questionnaireID][day] = old
+				fieldworkMutex.Unlock()
+			}
+		}
+	}
+}
+
+func fieldworkWorker() {
+	tick := time.NewTicker(time.Minute)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			flushFieldwork()
+		case <-fieldworkClose:
+			flushFieldwork()
+			close(fieldworkClosed)
+			return
+		}
+	}
+}
+
+// flushAndCloseFieldwork stores all pending counts. It must be called once before the data safe is closed.
+// Events counted afterwards are not stored.
+func flushAndCloseFieldwork() {
+	started := true
+	fieldworkWorkerStart.Do(func() {
+		// Nothing was counted, so there is nothing to flush.
+		started = false
+	})
+	if !started {
+		return
+	}
+	fieldworkClose <- true
+	<-fieldworkClosed
+}
+
+// getFieldwork returns the counts of the questionnaire per day, sorted by day.
+// Counts not yet stored in the data safe are included.
+func getFieldwork(questionnaireID string) ([]fieldworkRecord, error) {
+	safe, ok := registry.GetDataSafe(config.DataSafe)
+	if !ok {
+		return nil, fmt.Errorf("can not get datasafe %s", config.DataSafe)
+	}
+	data, err := safe.GetData(questionnaireID, []string{registry.FieldworkQuestion})
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != 1 {
+		return nil, fmt.Errorf("datasafe returned %d question data, expected was 1", len(data))
+	}
+
+	days := make(map[string]fieldworkRecord)
+	for i := range data[0] {
+		var r fieldworkRecord
+		err := json.Unmarshal([]byte(data[0][i]), &r)
+		if err != nil {
+			log.Printf("fieldwork: can not parse '%s' (%s)", data[0][i], err.Error())
+			continue
+		}
+		d := days[r.Day]
+		d.Day = r.Day
+		d.add(r)
+		days[r.Day] = d
+	}
+
+	fieldworkMutex.Lock()
+	for day, r := range fieldworkPending[questionnaireID] {
+		d := days[day]
+		d.Day = day
+		d.add(r)
+		days[day] = d
+	}
+	fieldworkMutex.Unlock()
+
+	result := make([]fieldworkRecord, 0, len(days))
+	for _, r := range days {
+		result = append(result, r)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Day < result[j].Day })
+	return result, nil
+}
+
+func fieldworkRate(submissions, views int) string {
+	if views == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", float64(submissions)/float64(views))
+}
+
+// GetFieldwork returns a save HTML fragment with the fieldwork statistics of the questionnaire.
+func (q Questionnaire) GetFieldwork() (template.HTML, error) {
+	records, err := getFieldwork(q.id)
+	if err != nil {
+		return "", err
+	}
+
+	td := fieldworkTemplateStruct{
+		Days: make([]fieldworkTemplateStructInner, len(records)),
+	}
+	labels := make([]string, len(records))
+	daily := []helper.ChartSeries{
+		{Label: "Start page views", Values: make([]float64, len(records))},
+		{Label: "Questionnaire views", Values: make([]float64, len(records))},
+		{Label: "Submissions", Values: make([]float64, len(records))},
+	}
+	cumulative := []helper.ChartSeries{
+		{Label: "Start page views", Values: make([]float64, len(records))},
+		{Label: "Questionnaire views", Values: make([]float64, len(records))},
+		{Label: "Submissions", Values: make([]float64, len(records))},
+	}
+
+	var total fieldworkRecord
+	for i := range records {
+		total.add(records[i])
+		td.Days[i] = fieldworkTemplateStructInner{
+			Day:         records[i].Day,
+			Start:       records[i].Start,
+			Main:        records[i].Main,
+			Submissions: records[i].Submissions,
+			ScreenOuts:  records[i].ScreenOuts,
+			RateStart:   fieldworkRate(records[i].Submissions, records[i].Start),
+			RateMain:    fieldworkRate(records[i].Submissions, records[i].Main),
+			Cumulative:  total.Submissions,
+		}
+		labels[i] = records[i].Day
+		daily[0].Values[i] = float64(records[i].Start)
+		daily[1].Values[i] = float64(records[i].Main)
+		daily[2].Values[i] = float64(records[i].Submissions)
+		cumulative[0].Values[i] = float64(total.Start)
+		cumulative[1].Values[i] = float64(total.Main)
+		cumulative[2].Values[i] = float64(total.Submissions)
+	}
+	td.Total = fieldworkTemplateStructInner{
+		Start:       total.Start,
+		Main:        total.Main,
+		Submissions: total.Submissions,
+		ScreenOuts:  total.ScreenOuts,
+		RateStart:   fieldworkRate(total.Submissions, total.Start),
+		RateMain:    fieldworkRate(total.Submissions, total.Main),
+		Cumulative:  total.Submissions,
+	}
+	if len(records) > 0 {
+		td.Daily = helper.TimeLineChart(labels, daily, "__fieldwork_daily", "Per day", "day")
+		td.Cumulative = helper.TimeLineChart(labels, cumulative, "__fieldwork_cumulative", "Cumulative", "day")
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err = fieldworkTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("fieldwork: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes()), nil
+}
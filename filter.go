@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Top-Ranger/questiongo/registry"
+)
+
+// ResultFilter restricts the results to responses whose answer to a categorical question is in a given category.
+// The zero value does not filter.
+type ResultFilter struct {
+	Question string
+	Category int
+}
+
+// ParseResultFilter parses a filter in the format 'question=category' (see ResultFilter.String).
+// An empty string results in a filter which does not filter.
+func ParseResultFilter(s string) (ResultFilter, error) {
+	if s == "" {
+		return ResultFilter{}, nil
+	}
+	i := strings.LastIndex(s, "=")
+	if i <= 0 {
+		return ResultFilter{}, fmt.Errorf("can not parse filter '%s'", s)
+	}
+	category, err := strconv.Atoi(s[i+1:])
+	if err != nil || category < 0 {
+		return ResultFilter{}, fmt.Errorf("can not parse filter '%s'", s)
+	}
+	return ResultFilter{Question: s[:i], Category: category}, nil
+}
+
+// Active returns whether the filter restricts the results.
+func (f ResultFilter) Active() bool {
+	return f.Question != ""
+}
+
+// String returns the filter in the format understood by ParseResultFilter.
+func (f ResultFilter) String() string {
+	if !f.Active() {
+		return ""
+	}
+	return fmt.Sprintf("%s=%d", f.Question, f.Category)
+}
+
+// resultFilterQuestion is used to show all filter options on the results page.
+type resultFilterQuestion struct {
+	ID         string
+	Categories []resultFilterCategory
+}
+
+type resultFilterCategory struct {
+	Value string
+	Label string
+}
+
+// FilterOptions returns all possible filters of the questionnaire.
+func (q Questionnaire) FilterOptions() []resultFilterQuestion {
+	result := make([]resultFilterQuestion, 0)
+	for i := range q.allQuestions {
+		cq, ok := q.allQuestions[i].(registry.CategoricalQuestion)
+		if !ok {
+			continue
+		}
+		labels := cq.GetCategoryLabels()
+		fq := resultFilterQuestion{ID: cq.GetID(), Categories: make([]resultFilterCategory, len(labels))}
+		for c := range labels {
+			fq.Categories[c] = resultFilterCategory{Value: ResultFilter{cq.GetID(), c}.String(), Label: labels[c]}
+		}
+		result = append(result, fq)
+	}
+	return result
+}
+
+// DescribeFilter returns a human readable description of the filter.
+func (q Questionnaire) DescribeFilter(f ResultFilter) string {
+	if !f.Active() {
+		return ""
+	}
+	for i := range q.allQuestions {
+		cq, ok := q.allQuestions[i].(registry.CategoricalQuestion)
+		if !ok || cq.GetID() != f.Question {
+			continue
+		}
+		labels := cq.GetCategoryLabels()
+		if f.Category < len(labels) {
+			return fmt.Sprintf("%s = %s", f.Question, labels[f.Category])
+		}
+	}
+	return f.String()
+}
+
+// filterQuestion returns the question the filter is based on.
+func (q Questionnaire) filterQuestion(f ResultFilter) (registry.CategoricalQuestion, error) {
+	for i := range q.allQuestions {
+		cq, ok := q.allQuestions[i].(registry.CategoricalQuestion)
+		if ok && cq.GetID() == f.Question {
+			if f.Category >= len(cq.GetCategoryLabels()) {
+				return nil, fmt.Errorf("can not filter by question %s: unknown category %d", f.Question, f.Category)
+			}
+			return cq, nil
+		}
+	}
+	return nil, fmt.Errorf("can not filter by question %s: question is not categorical", f.Question)
+}
+
+// CheckFilter returns an error if the filter can not be used for the questionnaire.
+func (q Questionnaire) CheckFilter(f ResultFilter) error {
+	if !f.Active() {
+		return nil
+	}
+	_, err := q.filterQuestion(f)
+	return err
+}
+
+// getData returns the stored data of the questions, restricted to the responses matching the filter.
+// The second value holds the number of responses before filtering.
+func (q Questionnaire) getData(ids []string, f ResultFilter) ([][]string, int, error) {
+	data, _, total, err := q.getDataPositions(ids, f)
+	return data, total, err
+}
+
+// getDataPositions is like getData, but additionally returns the position of each remaining response among all stored responses.
+func (q Questionnaire) getDataPositions(ids []string, f ResultFilter) ([][]string, []int, int, error) {
+	safe, ok := registry.GetDataSafe(config.DataSafe)
+	if !ok {
+		return nil, nil, 0, fmt.Errorf("can not get datasafe %s", config.DataSafe)
+	}
+
+	var filterQuestion registry.CategoricalQuestion
+	if f.Active() {
+		var err error
+		filterQuestion, err = q.filterQuestion(f)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		// Copy to not change the backing array of the caller
+		ids = append(append([]string(nil), ids...), f.Question)
+	}
+
+	data, err := safe.GetData(q.id, ids)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if len(data) != len(ids) {
+		return nil, nil, 0, fmt.Errorf("datasafe returned %d question data, expected was %d", len(data), len(ids))
+	}
+
+	if !f.Active() {
+		// Questions added later have less stored records, so use the longest column
+		total := 0
+		for i := range data {
+			if len(data[i]) > total {
+				total = len(data[i])
+			}
+		}
+		positions := make([]int, total)
+		for i := range positions {
+			positions[i] = i
+		}
+		return data, positions, total, nil
+	}
+
+	// Answers of a response share the same position across questions
+	categories := filterQuestion.GetCategories(data[len(data)-1])
+	data = data[:len(data)-1]
+	positions := make([]int, 0)
+	for r := range categories {
+		if categories[r] == f.Category {
+			positions = append(positions, r)
+		}
+	}
+	for i := range data {
+		filtered := make([]string, 0, len(positions))
+		for _, r := range positions {
+			if r < len(data[i]) {
+				filtered = append(filtered, data[i][r])
+			}
+		}
+		data[i] = filtered
+	}
+	return data, positions, len(categories), nil
+}
+
+// CountFiltered returns the number of responses matching the filter and the number of all responses.
+func (q Questionnaire) CountFiltered(f ResultFilter) (int, int, error) {
+	if !f.Active() {
+		return 0, 0, fmt.Errorf("can not count responses without filter")
+	}
+	data, total, err := q.getData([]string{f.Question}, f)
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(data[0]), total, nil
+}
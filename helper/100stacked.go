@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2021,2023 Marcus Soll
+// Copyright 2020,2021,2023,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -67,7 +67,7 @@ new Chart(ctx, {
 `))
 
 type stacked100TemplateStruct struct {
-	Data         [][]int
+	Data         [][]float64
 	Colour       []string
 	ID           string
 	Type         string
@@ -80,7 +80,7 @@ type stacked100TemplateStruct struct {
 // Stacked100Chart returns a save HTML fragment of the data as a 100% stacked bar chart.
 // v is interpreted as v[bar][value]. Missing labels will be filled with empty labels.
 // User must embed chart.js and chartjs-plugin-stacked100.
-func Stacked100Chart(v [][]int, id string, labelBars []string, LabelValues []string, title string) template.HTML {
+func Stacked100Chart(v [][]float64, id string, labelBars []string, LabelValues []string, title string) template.HTML {
 	for len(v) > len(labelBars) {
 		labelBars = append(labelBars, "")
 	}
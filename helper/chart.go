@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2021 Marcus Soll
+// Copyright 2020,2021,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -28,6 +28,12 @@ type ChartValue struct {
 	Value float64
 }
 
+// ChartSeries represents a named series of values in a chart with multiple datasets.
+type ChartSeries struct {
+	Label  string
+	Values []float64
+}
+
 var chartTemplate = template.Must(template.New("chartTemplate").Parse(`
 <div class="chart">
 	<canvas id="{{.ID}}"></canvas>
@@ -81,14 +87,115 @@ new Chart(ctx, {
 </script>
 `))
 
+var timeChartTemplate = template.Must(template.New("timeChartTemplate").Parse(`
+<div class="chart">
+	<canvas id="{{.ID}}"></canvas>
+</div>
+<script>
+var ctx = document.getElementById('{{.ID}}').getContext('2d');
+new Chart(ctx, {
+	type: "bar",
+	data: {
+		datasets: [{
+			data: [
+				{{range $i, $e := .Data }}
+				{x: {{$e.Label}}, y: {{$e.Value}}},
+				{{end}}
+			],
+			backgroundColor: {{.SingleColour}},
+			label: {{.Label}}
+		}],
+	},
+	options: {
+		plugins: {
+			title: {
+				display: true,
+				text: {{.Label}}
+			}
+		},
+		responsive: true,
+		scales: {
+			x: {
+				type: "time",
+				offset: true,
+				time: {
+					unit: {{.Unit}},
+					isoWeekday: true,
+					{{if .DisplayFormat}}
+					displayFormats: {
+						{{.Unit}}: {{.DisplayFormat}}
+					},
+					{{end}}
+				},
+			},
+			y: {
+				beginAtZero: true
+			}
+		},
+	}
+});
+</script>
+`))
+
+var timeLineChartTemplate = template.Must(template.New("timeLineChartTemplate").Parse(`
+<div class="chart">
+	<canvas id="{{.ID}}"></canvas>
+</div>
+<script>
+var ctx = document.getElementById('{{.ID}}').getContext('2d');
+new Chart(ctx, {
+	type: "line",
+	data: {
+		datasets: [
+			{{range $i, $e := .Series }}
+			{
+				data: [
+					{{range $I, $E := $e.Values }}
+					{x: {{index $.Labels $I}}, y: {{$E}}},
+					{{end}}
+				],
+				backgroundColor: {{index $.Colour $i}},
+				borderColor: {{index $.Colour $i}},
+				label: {{$e.Label}}
+			},
+			{{end}}
+		],
+	},
+	options: {
+		plugins: {
+			title: {
+				display: true,
+				text: {{.Label}}
+			}
+		},
+		responsive: true,
+		scales: {
+			x: {
+				type: "time",
+				time: {
+					unit: {{.Unit}},
+					isoWeekday: true,
+				},
+			},
+			y: {
+				beginAtZero: true
+			}
+		},
+	}
+});
+</script>
+`))
+
 type chartTemplateStruct struct {
-	Data         []ChartValue
-	Colour       []string
-	SingleColour string
-	ID           string
-	Type         string
-	Label        string
-	Scales       bool
+	Data          []ChartValue
+	Colour        []string
+	SingleColour  string
+	ID            string
+	Type          string
+	Label         string
+	Scales        bool
+	Unit          string
+	DisplayFormat string
 }
 
 func getColours(n int) []string {
@@ -160,3 +267,61 @@ func BarChart(v []ChartValue, id, label string) template.HTML {
 	}
 	return template.HTML(output.Bytes())
 }
+
+// TimeChart returns a save HTML fragment of the data as a bar chart with a time axis.
+// The labels of v must be dates or times in ISO 8601 format (e.g. "2006-01-02" or "2006-01-02T15:04").
+// unit must be a time unit known to chart.js (e.g. "hour", "day", "week", "month" or "year").
+// displayFormat is an optional moment.js format for the labels of the axis.
+// User must embed chart.js, moment.js and the chart.js moment adapter.
+func TimeChart(v []ChartValue, id, label, unit, displayFormat string) template.HTML {
+	td := chartTemplateStruct{
+		Data:          v,
+		SingleColour:  getColours(1)[0],
+		ID:            id,
+		Label:         label,
+		Unit:          unit,
+		DisplayFormat: displayFormat,
+	}
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := timeChartTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("time chart: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+type timeLineChartTemplateStruct struct {
+	Labels []string
+	Series []ChartSeries
+	Colour []string
+	ID     string
+	Label  string
+	Unit   string
+}
+
+// TimeLineChart returns a save HTML fragment of the series as a line chart with a time axis.
+// labels must be dates or times in ISO 8601 format (e.g. "2006-01-02") and each series must have one value per label.
+// unit must be a time unit known to chart.js (e.g. "hour", "day", "week", "month" or "year").
+// User must embed chart.js, moment.js and the chart.js moment adapter.
+func TimeLineChart(labels []string, series []ChartSeries, id, label, unit string) template.HTML {
+	for i := range series {
+		if len(series[i].Values) > len(labels) {
+			log.Printf("time line chart: Series %d has %d values for %d labels", i, len(series[i].Values), len(labels))
+			series[i].Values = series[i].Values[:len(labels)]
+		}
+	}
+	td := timeLineChartTemplateStruct{
+		Labels: labels,
+		Series: series,
+		Colour: getColours(len(series)),
+		ID:     id,
+		Label:  label,
+		Unit:   unit,
+	}
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := timeLineChartTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("time line chart: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
@@ -0,0 +1,309 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"math"
+	"strconv"
+)
+
+var descriptiveTableTemplate = template.Must(template.New("descriptiveTableTemplate").Parse(`<table>
+<thead>
+<tr>
+{{if .Labels}}<th></th>{{end}}
+<th>N</th>
+<th>Mean</th>
+<th>Standard deviation</th>
+<th>Min</th>
+<th>Lower quartile</th>
+<th>Median</th>
+<th>Upper quartile</th>
+<th>Max</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Data}}
+<tr>
+{{if $.Labels}}<td>{{index $.Labels $i}}</td>{{end}}
+{{if $e.Suppressed}}
+<td colspan="8">[suppressed]</td>
+{{else}}
+<td>{{$e.N}}</td>
+{{if $e.N}}
+<td>{{printf "%.2f" $e.Mean}}</td>
+<td>{{printf "%.2f" $e.SD}}</td>
+{{if $e.ExtremesSuppressed}}
+<td colspan="5">[suppressed]</td>
+{{else}}
+<td>{{printf "%.2f" $e.Min}}</td>
+<td>{{printf "%.2f" $e.Q1}}</td>
+<td>{{printf "%.2f" $e.Median}}</td>
+<td>{{printf "%.2f" $e.Q3}}</td>
+<td>{{printf "%.2f" $e.Max}}</td>
+{{end}}
+{{else}}
+<td>-</td><td>-</td><td>-</td><td>-</td><td>-</td><td>-</td><td>-</td>
+{{end}}
+{{end}}
+</tr>
+{{end}}
+</tbody>
+</table>
+`))
+
+var boxPlotTemplate = template.Must(template.New("boxPlotTemplate").Parse(`<div class="chart">
+<svg viewBox="0 0 {{.Width}} {{.Height}}" role="img" style="width:100%;height:auto;font-family:sans-serif;font-size:12px">
+{{range $i, $e := .Boxes}}
+<g>
+<title>{{$e.Title}}</title>
+{{if $e.Label}}<text x="10" y="{{$e.LabelY}}">{{$e.Label}}</text>{{end}}
+<line x1="{{$e.Min}}" y1="{{$e.Middle}}" x2="{{$e.Q1}}" y2="{{$e.Middle}}" stroke="black"/>
+<line x1="{{$e.Q3}}" y1="{{$e.Middle}}" x2="{{$e.Max}}" y2="{{$e.Middle}}" stroke="black"/>
+<line x1="{{$e.Min}}" y1="{{$e.Top}}" x2="{{$e.Min}}" y2="{{$e.Bottom}}" stroke="black"/>
+<line x1="{{$e.Max}}" y1="{{$e.Top}}" x2="{{$e.Max}}" y2="{{$e.Bottom}}" stroke="black"/>
+<rect x="{{$e.Q1}}" y="{{$e.Top}}" width="{{$e.BoxWidth}}" height="{{$e.BoxHeight}}" fill="{{$.Colour}}" fill-opacity="0.5" stroke="black"/>
+<line x1="{{$e.Median}}" y1="{{$e.Top}}" x2="{{$e.Median}}" y2="{{$e.Bottom}}" stroke="black" stroke-width="2"/>
+</g>
+{{end}}
+<line x1="{{.AxisStart}}" y1="{{.AxisY}}" x2="{{.AxisEnd}}" y2="{{.AxisY}}" stroke="black"/>
+{{range $i, $e := .Ticks}}
+<line x1="{{$e.X}}" y1="{{$.AxisY}}" x2="{{$e.X}}" y2="{{$.TickY}}" stroke="black"/>
+<text x="{{$e.X}}" y="{{$.TickLabelY}}" text-anchor="middle">{{$e.Label}}</text>
+{{end}}
+</svg>
+</div>
+`))
+
+type descriptiveTableTemplateStruct struct {
+	Labels []string
+	Data   []Descriptive
+}
+
+type boxPlotTemplateStruct struct {
+	Width      int
+	Height     int
+	Colour     string
+	Boxes      []boxPlotTemplateStructBox
+	AxisStart  float64
+	AxisEnd    float64
+	AxisY      float64
+	TickY      float64
+	TickLabelY float64
+	Ticks      []boxPlotTemplateStructTick
+}
+
+type boxPlotTemplateStructBox struct {
+	Title     string
+	Label     string
+	LabelY    float64
+	Top       float64
+	Middle    float64
+	Bottom    float64
+	Min       float64
+	Q1        float64
+	Median    float64
+	Q3        float64
+	Max       float64
+	BoxWidth  float64
+	BoxHeight float64
+}
+
+type boxPlotTemplateStructTick struct {
+	X     float64
+	Label string
+}
+
+// DescriptiveTable returns a save HTML fragment of the statistics as a table.
+// labels is optional. If it is set, it must have the same length as d and is shown as the first column.
+func DescriptiveTable(labels []string, d []Descriptive) template.HTML {
+	if labels != nil && len(labels) != len(d) {
+		log.Printf("descriptive table: Got %d labels for %d rows", len(labels), len(d))
+		labels = nil
+	}
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := descriptiveTableTemplate.Execute(output, descriptiveTableTemplateStruct{Labels: labels, Data: d})
+	if err != nil {
+		log.Printf("descriptive table: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+// BoxPlot returns a save HTML fragment with one horizontal box plot per entry of d on a common axis.
+// Whiskers extend to the minimum and maximum. Entries without observations or with suppressed statistics or extremes are left empty.
+// labels is optional. If it is set, it must have the same length as d.
+// The box plot is rendered as inline SVG and does not need chart.js.
+func BoxPlot(labels []string, d []Descriptive) template.HTML {
+	const width = 600
+	const margin = 20.0
+	const rowHeight = 50.0
+
+	if labels != nil && len(labels) != len(d) {
+		log.Printf("box plot: Got %d labels for %d rows", len(labels), len(d))
+		labels = nil
+	}
+
+	min, max := math.Inf(1), math.Inf(-1)
+	for i := range d {
+		if d[i].N == 0 || d[i].Suppressed || d[i].ExtremesSuppressed {
+			continue
+		}
+		min = math.Min(min, d[i].Min)
+		max = math.Max(max, d[i].Max)
+	}
+	if math.IsInf(min, 1) {
+		return ""
+	}
+	if min == max {
+		min--
+		max++
+	}
+	scale := func(v float64) float64 {
+		return math.Round((margin+(v-min)/(max-min)*(width-2*margin))*100) / 100
+	}
+
+	td := boxPlotTemplateStruct{
+		Width:     width,
+		Colour:    getColours(1)[0],
+		Boxes:     make([]boxPlotTemplateStructBox, 0, len(d)),
+		AxisStart: margin,
+		AxisEnd:   width - margin,
+	}
+	y := 0.0
+	for i := range d {
+		box := boxPlotTemplateStructBox{
+			LabelY: y + 14,
+			Top:    y + 20,
+			Middle: y + 32,
+			Bottom: y + 44,
+		}
+		if labels != nil {
+			box.Label = labels[i]
+		}
+		if d[i].N != 0 && !d[i].Suppressed && !d[i].ExtremesSuppressed {
+			box.Title = fmt.Sprintf("Min: %.2f, lower quartile: %.2f, median: %.2f, upper quartile: %.2f, max: %.2f", d[i].Min, d[i].Q1, d[i].Median, d[i].Q3, d[i].Max)
+			box.Min = scale(d[i].Min)
+			box.Q1 = scale(d[i].Q1)
+			box.Median = scale(d[i].Median)
+			box.Q3 = scale(d[i].Q3)
+			box.Max = scale(d[i].Max)
+			box.BoxWidth = box.Q3 - box.Q1
+			box.BoxHeight = box.Bottom - box.Top
+		}
+		td.Boxes = append(td.Boxes, box)
+		y += rowHeight
+	}
+
+	td.AxisY = y + 5
+	td.TickY = y + 10
+	td.TickLabelY = y + 24
+	td.Height = int(y + 30)
+	const ticks = 5
+	for i := 0; i < ticks; i++ {
+		v := min + (max-min)*float64(i)/float64(ticks-1)
+		td.Ticks = append(td.Ticks, boxPlotTemplateStructTick{X: scale(v), Label: strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)})
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := boxPlotTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("box plot: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+// Histogram returns a save HTML fragment of v as a bar chart with up to bins equally sized bins between the minimum and the maximum.
+// If all values are integers, bins are aligned to integers and might therefore be fewer than requested.
+// w holds the weight of each value. If w is nil, each value is counted once.
+// Bins containing less than minCount values (but at least one) are shown as empty.
+// User must embed chart.js.
+func Histogram(v, w []float64, bins, minCount int, id, label string) template.HTML {
+	if len(v) == 0 || bins <= 0 {
+		return BarChart(nil, id, label)
+	}
+
+	weight := func(i int) float64 {
+		if w == nil || i >= len(w) {
+			return 1
+		}
+		return w[i]
+	}
+
+	min, max := v[0], v[0]
+	integer := true
+	for i := range v {
+		min = math.Min(min, v[i])
+		max = math.Max(max, v[i])
+		if v[i] != math.Trunc(v[i]) {
+			integer = false
+		}
+	}
+
+	var values []ChartValue
+	var counts []int
+	if integer {
+		size := int(math.Ceil((max - min + 1) / float64(bins)))
+		n := int(math.Ceil((max - min + 1) / float64(size)))
+		values = make([]ChartValue, n)
+		for i := range values {
+			start := int(min) + i*size
+			end := start + size - 1
+			if size == 1 {
+				values[i].Label = strconv.Itoa(start)
+			} else {
+				values[i].Label = fmt.Sprintf("%d–%d", start, end)
+			}
+		}
+		counts = make([]int, n)
+		for i := range v {
+			values[int(v[i]-min)/size].Value += weight(i)
+			counts[int(v[i]-min)/size]++
+		}
+	} else {
+		if min == max {
+			bins = 1
+		}
+		size := (max - min) / float64(bins)
+		values = make([]ChartValue, bins)
+		counts = make([]int, bins)
+		for i := range values {
+			values[i].Label = fmt.Sprintf("%.2f–%.2f", min+float64(i)*size, min+float64(i+1)*size)
+		}
+		for i := range v {
+			b := 0
+			if size > 0 {
+				b = int((v[i] - min) / size)
+			}
+			if b >= bins {
+				// Maximum belongs to the last bin.
+				b = bins - 1
+			}
+			values[b].Value += weight(i)
+			counts[b]++
+		}
+	}
+
+	for i := range counts {
+		if counts[i] < minCount {
+			values[i].Value = 0
+		}
+	}
+
+	return BarChart(values, id, label)
+}
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDescriptiveExtremesSuppressed(t *testing.T) {
+	d := DescriptiveStatistics([]float64{1, 2, 3, 4, 97})
+	d.ExtremesSuppressed = true
+
+	table := string(DescriptiveTable(nil, []Descriptive{d}))
+	if !strings.Contains(table, "<td>5</td>") || !strings.Contains(table, "21.40") {
+		t.Errorf("table does not contain N and mean: %s", table)
+	}
+	if strings.Contains(table, "97.00") || strings.Contains(table, "1.00") {
+		t.Errorf("table contains suppressed extremes: %s", table)
+	}
+	if !strings.Contains(table, "[suppressed]") {
+		t.Errorf("table does not mark suppressed extremes: %s", table)
+	}
+
+	if plot := BoxPlot(nil, []Descriptive{d}); plot != "" {
+		t.Errorf("box plot of suppressed extremes is not empty: %s", plot)
+	}
+}
@@ -0,0 +1,779 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ExpressionType represents the type of an expression or value.
+type ExpressionType int
+
+const (
+	// ExpressionAny is the type of variables. The value is a string which is converted as needed.
+	ExpressionAny ExpressionType = iota
+	// ExpressionNumber represents a floating point number.
+	ExpressionNumber
+	// ExpressionString represents a string.
+	ExpressionString
+	// ExpressionBool represents a boolean.
+	ExpressionBool
+)
+
+// String returns a readable name of the type.
+func (t ExpressionType) String() string {
+	switch t {
+	case ExpressionNumber:
+		return "number"
+	case ExpressionString:
+		return "string"
+	case ExpressionBool:
+		return "bool"
+	default:
+		return "any"
+	}
+}
+
+// ErrExpressionMissingValue is returned by Evaluate if a value needed for the result is missing or empty.
+var ErrExpressionMissingValue = errors.New("expression: value missing")
+
+// ExpressionValue represents the result of an evaluated expression.
+type ExpressionValue struct {
+	Type   ExpressionType
+	Number float64
+	String string
+	Bool   bool
+}
+
+// Format returns the value as a string suitable for storage.
+func (v ExpressionValue) Format() string {
+	switch v.Type {
+	case ExpressionNumber:
+		return strconv.FormatFloat(v.Number, 'f', -1, 64)
+	case ExpressionBool:
+		return strconv.FormatBool(v.Bool)
+	default:
+		return v.String
+	}
+}
+
+func (v ExpressionValue) toNumber() (float64, error) {
+	switch v.Type {
+	case ExpressionNumber:
+		return v.Number, nil
+	case ExpressionAny:
+		s := strings.TrimSpace(v.String)
+		if s == "" {
+			return 0, ErrExpressionMissingValue
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("expression: '%s' is not a number", v.String)
+		}
+		return f, nil
+	default:
+		return 0, fmt.Errorf("expression: %s is not a number", v.Type)
+	}
+}
+
+func (v ExpressionValue) toBool() (bool, error) {
+	switch v.Type {
+	case ExpressionBool:
+		return v.Bool, nil
+	case ExpressionAny:
+		if v.String == "" {
+			return false, ErrExpressionMissingValue
+		}
+		b, err := strconv.ParseBool(v.String)
+		if err != nil {
+			return false, fmt.Errorf("expression: '%s' is not a bool", v.String)
+		}
+		return b, nil
+	default:
+		return false, fmt.Errorf("expression: %s is not a bool", v.Type)
+	}
+}
+
+// Expression represents a compiled expression.
+// Expressions support numbers, strings ("text" or 'text'), true and false, variables, the operators + - * / % == != < <= > >= && || !, parentheses,
+// and the functions sum, mean, min, max (any number of numbers), round (number and optional digits), abs, if (condition, then, else), empty (variable), and number (variable).
+// Variables are either written directly (letters, digits and '_', starting with a letter) or enclosed in curly braces ({childage-1}).
+// All methods are safe for parallel usage.
+type Expression struct {
+	root      expressionNode
+	variables []string
+	types     map[string]ExpressionType
+}
+
+// CompileExpression parses and type checks an expression.
+func CompileExpression(expression string) (*Expression, error) {
+	tokens, err := tokeniseExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := expressionParser{tokens: tokens, variables: make(map[string]bool)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("expression: unexpected '%s'", p.tokens[p.pos].text)
+	}
+	_, err = root.check(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{root: root, variables: p.order}, nil
+}
+
+// Check type checks the expression against the known types of the variables and returns the type of the result.
+// Variables not contained in types are of type ExpressionAny. Afterwards, Type reports the result of the check.
+// Check must not be called in parallel to other methods.
+func (e *Expression) Check(types map[string]ExpressionType) (ExpressionType, error) {
+	t, err := e.root.check(types)
+	if err != nil {
+		return t, err
+	}
+	e.types = types
+	return t, nil
+}
+
+// Variables returns all variables referenced by the expression in order of appearance.
+func (e *Expression) Variables() []string {
+	v := make([]string, len(e.variables))
+	copy(v, e.variables)
+	return v
+}
+
+// Type returns the type of the expression result.
+func (e *Expression) Type() ExpressionType {
+	t, _ := e.root.check(e.types)
+	return t
+}
+
+// Evaluate computes the expression. Missing variables are treated as empty.
+// If a needed value is empty, ErrExpressionMissingValue is returned.
+func (e *Expression) Evaluate(values map[string]string) (ExpressionValue, error) {
+	return e.root.eval(values)
+}
+
+// Tokeniser
+
+type expressionTokenType int
+
+const (
+	tokenNumber expressionTokenType = iota
+	tokenString
+	tokenIdentifier
+	tokenVariable
+	tokenOperator
+)
+
+type expressionToken struct {
+	kind expressionTokenType
+	text string
+}
+
+func tokeniseExpression(s string) ([]expressionToken, error) {
+	tokens := make([]expressionToken, 0)
+	r := []rune(s)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(r) && unicode.IsDigit(r[i+1])):
+			start := i
+			for i < len(r) && (unicode.IsDigit(r[i]) || r[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, expressionToken{tokenNumber, string(r[start:i])})
+		case unicode.IsLetter(c):
+			start := i
+			for i < len(r) && (unicode.IsLetter(r[i]) || unicode.IsDigit(r[i]) || r[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, expressionToken{tokenIdentifier, string(r[start:i])})
+		case c == '{':
+			start := i + 1
+			for i < len(r) && r[i] != '}' {
+				i++
+			}
+			if i >= len(r) {
+				return nil, fmt.Errorf("expression: missing '}'")
+			}
+			name := strings.TrimSpace(string(r[start:i]))
+			if name == "" {
+				return nil, fmt.Errorf("expression: empty variable name")
+			}
+			tokens = append(tokens, expressionToken{tokenVariable, name})
+			i++
+		case c == '"' || c == '\'':
+			var b strings.Builder
+			i++
+			for i < len(r) && r[i] != c {
+				if r[i] == '\\' && i+1 < len(r) {
+					i++
+				}
+				b.WriteRune(r[i])
+				i++
+			}
+			if i >= len(r) {
+				return nil, fmt.Errorf("expression: unterminated string")
+			}
+			i++
+			tokens = append(tokens, expressionToken{tokenString, b.String()})
+		default:
+			if i+1 < len(r) {
+				two := string(r[i : i+2])
+				switch two {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					tokens = append(tokens, expressionToken{tokenOperator, two})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("+-*/%<>!(),", c) {
+				return nil, fmt.Errorf("expression: unknown character '%c'", c)
+			}
+			tokens = append(tokens, expressionToken{tokenOperator, string(c)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// Parser
+
+type expressionParser struct {
+	tokens    []expressionToken
+	pos       int
+	variables map[string]bool
+	order     []string
+}
+
+func (p *expressionParser) peekOperator(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenOperator {
+		return "", false
+	}
+	for i := range ops {
+		if p.tokens[p.pos].text == ops[i] {
+			return ops[i], true
+		}
+	}
+	return "", false
+}
+
+func (p *expressionParser) expect(op string) error {
+	if _, ok := p.peekOperator(op); !ok {
+		if p.pos >= len(p.tokens) {
+			return fmt.Errorf("expression: expected '%s' at end of expression", op)
+		}
+		return fmt.Errorf("expression: expected '%s', found '%s'", op, p.tokens[p.pos].text)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *expressionParser) parseBinary(next func() (expressionNode, error), ops ...string) (expressionNode, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.peekOperator(ops...)
+		if !ok {
+			return left, nil
+		}
+		p.pos++
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *expressionParser) parseOr() (expressionNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *expressionParser) parseAnd() (expressionNode, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *expressionParser) parseComparison() (expressionNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.peekOperator("==", "!=", "<", "<=", ">", ">=")
+	if !ok {
+		return left, nil
+	}
+	p.pos++
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	return binaryNode{op: op, left: left, right: right}, nil
+}
+
+func (p *expressionParser) parseAdditive() (expressionNode, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *expressionParser) parseMultiplicative() (expressionNode, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *expressionParser) parseUnary() (expressionNode, error) {
+	op, ok := p.peekOperator("-", "!")
+	if ok {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *expressionParser) parsePrimary() (expressionNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("expression: unexpected end of expression")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.kind {
+	case tokenNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expression: invalid number '%s'", t.text)
+		}
+		return constantNode{ExpressionValue{Type: ExpressionNumber, Number: f}}, nil
+	case tokenString:
+		return constantNode{ExpressionValue{Type: ExpressionString, String: t.text}}, nil
+	case tokenVariable:
+		p.addVariable(t.text)
+		return variableNode{t.text}, nil
+	case tokenIdentifier:
+		switch t.text {
+		case "true", "false":
+			return constantNode{ExpressionValue{Type: ExpressionBool, Bool: t.text == "true"}}, nil
+		}
+		if _, ok := p.peekOperator("("); !ok {
+			p.addVariable(t.text)
+			return variableNode{t.text}, nil
+		}
+		p.pos++
+		args := make([]expressionNode, 0)
+		if _, ok := p.peekOperator(")"); !ok {
+			for {
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if _, ok := p.peekOperator(","); !ok {
+					break
+				}
+				p.pos++
+			}
+		}
+		err := p.expect(")")
+		if err != nil {
+			return nil, err
+		}
+		return functionNode{name: t.text, args: args}, nil
+	case tokenOperator:
+		if t.text == "(" {
+			n, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			err = p.expect(")")
+			if err != nil {
+				return nil, err
+			}
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("expression: unexpected '%s'", t.text)
+}
+
+func (p *expressionParser) addVariable(name string) {
+	if !p.variables[name] {
+		p.variables[name] = true
+		p.order = append(p.order, name)
+	}
+}
+
+// Nodes
+
+type expressionNode interface {
+	check(types map[string]ExpressionType) (ExpressionType, error)
+	eval(values map[string]string) (ExpressionValue, error)
+}
+
+func isNumeric(t ExpressionType) bool {
+	return t == ExpressionNumber || t == ExpressionAny
+}
+
+func isBoolean(t ExpressionType) bool {
+	return t == ExpressionBool || t == ExpressionAny
+}
+
+type constantNode struct {
+	value ExpressionValue
+}
+
+func (n constantNode) check(types map[string]ExpressionType) (ExpressionType, error) {
+	return n.value.Type, nil
+}
+
+func (n constantNode) eval(values map[string]string) (ExpressionValue, error) {
+	return n.value, nil
+}
+
+type variableNode struct {
+	name string
+}
+
+func (n variableNode) check(types map[string]ExpressionType) (ExpressionType, error) {
+	t, ok := types[n.name]
+	if !ok {
+		return ExpressionAny, nil
+	}
+	return t, nil
+}
+
+func (n variableNode) eval(values map[string]string) (ExpressionValue, error) {
+	return ExpressionValue{Type: ExpressionAny, String: values[n.name]}, nil
+}
+
+type unaryNode struct {
+	op      string
+	operand expressionNode
+}
+
+func (n unaryNode) check(types map[string]ExpressionType) (ExpressionType, error) {
+	t, err := n.operand.check(types)
+	if err != nil {
+		return t, err
+	}
+	if n.op == "-" {
+		if !isNumeric(t) {
+			return t, fmt.Errorf("expression: can not negate %s", t)
+		}
+		return ExpressionNumber, nil
+	}
+	if !isBoolean(t) {
+		return t, fmt.Errorf("expression: can not apply '!' to %s", t)
+	}
+	return ExpressionBool, nil
+}
+
+func (n unaryNode) eval(values map[string]string) (ExpressionValue, error) {
+	v, err := n.operand.eval(values)
+	if err != nil {
+		return v, err
+	}
+	if n.op == "-" {
+		f, err := v.toNumber()
+		return ExpressionValue{Type: ExpressionNumber, Number: -f}, err
+	}
+	b, err := v.toBool()
+	return ExpressionValue{Type: ExpressionBool, Bool: !b}, err
+}
+
+type binaryNode struct {
+	op          string
+	left, right expressionNode
+}
+
+func (n binaryNode) check(types map[string]ExpressionType) (ExpressionType, error) {
+	l, err := n.left.check(types)
+	if err != nil {
+		return l, err
+	}
+	r, err := n.right.check(types)
+	if err != nil {
+		return r, err
+	}
+	switch n.op {
+	case "+", "-", "*", "/", "%":
+		if !isNumeric(l) || !isNumeric(r) {
+			return ExpressionNumber, fmt.Errorf("expression: can not use '%s' on %s and %s", n.op, l, r)
+		}
+		return ExpressionNumber, nil
+	case "<", "<=", ">", ">=":
+		if !isNumeric(l) || !isNumeric(r) {
+			return ExpressionBool, fmt.Errorf("expression: can not compare %s and %s with '%s'", l, r, n.op)
+		}
+		return ExpressionBool, nil
+	case "==", "!=":
+		if l != r && l != ExpressionAny && r != ExpressionAny {
+			return ExpressionBool, fmt.Errorf("expression: can not compare %s and %s", l, r)
+		}
+		return ExpressionBool, nil
+	default:
+		if !isBoolean(l) || !isBoolean(r) {
+			return ExpressionBool, fmt.Errorf("expression: can not use '%s' on %s and %s", n.op, l, r)
+		}
+		return ExpressionBool, nil
+	}
+}
+
+func (n binaryNode) eval(values map[string]string) (ExpressionValue, error) {
+	l, err := n.left.eval(values)
+	if err != nil {
+		return l, err
+	}
+
+	// Short circuit
+	switch n.op {
+	case "&&", "||":
+		lb, err := l.toBool()
+		if err != nil {
+			return l, err
+		}
+		if (n.op == "&&" && !lb) || (n.op == "||" && lb) {
+			return ExpressionValue{Type: ExpressionBool, Bool: lb}, nil
+		}
+		r, err := n.right.eval(values)
+		if err != nil {
+			return r, err
+		}
+		rb, err := r.toBool()
+		return ExpressionValue{Type: ExpressionBool, Bool: rb}, err
+	}
+
+	r, err := n.right.eval(values)
+	if err != nil {
+		return r, err
+	}
+
+	if n.op == "==" || n.op == "!=" {
+		equal, err := expressionEqual(l, r)
+		if err != nil {
+			return l, err
+		}
+		return ExpressionValue{Type: ExpressionBool, Bool: equal == (n.op == "==")}, nil
+	}
+
+	lf, err := l.toNumber()
+	if err != nil {
+		return l, err
+	}
+	rf, err := r.toNumber()
+	if err != nil {
+		return r, err
+	}
+	switch n.op {
+	case "+":
+		return ExpressionValue{Type: ExpressionNumber, Number: lf + rf}, nil
+	case "-":
+		return ExpressionValue{Type: ExpressionNumber, Number: lf - rf}, nil
+	case "*":
+		return ExpressionValue{Type: ExpressionNumber, Number: lf * rf}, nil
+	case "/":
+		if rf == 0 {
+			return l, fmt.Errorf("expression: division by zero")
+		}
+		return ExpressionValue{Type: ExpressionNumber, Number: lf / rf}, nil
+	case "%":
+		if rf == 0 {
+			return l, fmt.Errorf("expression: division by zero")
+		}
+		return ExpressionValue{Type: ExpressionNumber, Number: math.Mod(lf, rf)}, nil
+	case "<":
+		return ExpressionValue{Type: ExpressionBool, Bool: lf < rf}, nil
+	case "<=":
+		return ExpressionValue{Type: ExpressionBool, Bool: lf <= rf}, nil
+	case ">":
+		return ExpressionValue{Type: ExpressionBool, Bool: lf > rf}, nil
+	default:
+		return ExpressionValue{Type: ExpressionBool, Bool: lf >= rf}, nil
+	}
+}
+
+func expressionEqual(l, r ExpressionValue) (bool, error) {
+	t := l.Type
+	if t == ExpressionAny {
+		t = r.Type
+	}
+	switch t {
+	case ExpressionNumber:
+		lf, err := l.toNumber()
+		if err != nil {
+			return false, err
+		}
+		rf, err := r.toNumber()
+		return lf == rf, err
+	case ExpressionBool:
+		lb, err := l.toBool()
+		if err != nil {
+			return false, err
+		}
+		rb, err := r.toBool()
+		return lb == rb, err
+	default:
+		return l.String == r.String, nil
+	}
+}
+
+type functionNode struct {
+	name string
+	args []expressionNode
+}
+
+func (n functionNode) check(types map[string]ExpressionType) (ExpressionType, error) {
+	argTypes := make([]ExpressionType, len(n.args))
+	for i := range n.args {
+		t, err := n.args[i].check(types)
+		if err != nil {
+			return t, err
+		}
+		argTypes[i] = t
+	}
+
+	switch n.name {
+	case "sum", "mean", "min", "max":
+		if len(argTypes) == 0 {
+			return ExpressionNumber, fmt.Errorf("expression: %s needs at least one argument", n.name)
+		}
+		for i := range argTypes {
+			if !isNumeric(argTypes[i]) {
+				return ExpressionNumber, fmt.Errorf("expression: argument %d of %s must be a number, is %s", i+1, n.name, argTypes[i])
+			}
+		}
+		return ExpressionNumber, nil
+	case "round":
+		if len(argTypes) != 1 && len(argTypes) != 2 {
+			return ExpressionNumber, fmt.Errorf("expression: round needs one or two arguments")
+		}
+		for i := range argTypes {
+			if !isNumeric(argTypes[i]) {
+				return ExpressionNumber, fmt.Errorf("expression: argument %d of round must be a number, is %s", i+1, argTypes[i])
+			}
+		}
+		return ExpressionNumber, nil
+	case "abs":
+		if len(argTypes) != 1 || !isNumeric(argTypes[0]) {
+			return ExpressionNumber, fmt.Errorf("expression: abs needs exactly one number")
+		}
+		return ExpressionNumber, nil
+	case "if":
+		if len(argTypes) != 3 {
+			return ExpressionAny, fmt.Errorf("expression: if needs exactly three arguments")
+		}
+		if !isBoolean(argTypes[0]) {
+			return ExpressionAny, fmt.Errorf("expression: condition of if must be a bool, is %s", argTypes[0])
+		}
+		switch {
+		case argTypes[1] == argTypes[2]:
+			return argTypes[1], nil
+		case argTypes[1] == ExpressionAny || argTypes[2] == ExpressionAny:
+			return ExpressionAny, nil
+		default:
+			return ExpressionAny, fmt.Errorf("expression: both results of if must have the same type, are %s and %s", argTypes[1], argTypes[2])
+		}
+	case "empty", "number":
+		if len(n.args) != 1 {
+			return ExpressionBool, fmt.Errorf("expression: %s needs exactly one argument", n.name)
+		}
+		if _, ok := n.args[0].(variableNode); !ok {
+			return ExpressionBool, fmt.Errorf("expression: argument of %s must be a variable", n.name)
+		}
+		if n.name == "number" {
+			return ExpressionNumber, nil
+		}
+		return ExpressionBool, nil
+	default:
+		return ExpressionAny, fmt.Errorf("expression: unknown function %s", n.name)
+	}
+}
+
+func (n functionNode) eval(values map[string]string) (ExpressionValue, error) {
+	switch n.name {
+	case "if":
+		c, err := n.args[0].eval(values)
+		if err != nil {
+			return c, err
+		}
+		b, err := c.toBool()
+		if err != nil {
+			return c, err
+		}
+		if b {
+			return n.args[1].eval(values)
+		}
+		return n.args[2].eval(values)
+	case "empty":
+		return ExpressionValue{Type: ExpressionBool, Bool: strings.TrimSpace(values[n.args[0].(variableNode).name]) == ""}, nil
+	}
+
+	numbers := make([]float64, len(n.args))
+	for i := range n.args {
+		v, err := n.args[i].eval(values)
+		if err != nil {
+			return v, err
+		}
+		numbers[i], err = v.toNumber()
+		if err != nil {
+			return v, err
+		}
+	}
+
+	result := ExpressionValue{Type: ExpressionNumber}
+	switch n.name {
+	case "sum", "mean":
+		for i := range numbers {
+			result.Number += numbers[i]
+		}
+		if n.name == "mean" {
+			result.Number /= float64(len(numbers))
+		}
+	case "min":
+		result.Number = numbers[0]
+		for i := range numbers {
+			result.Number = math.Min(result.Number, numbers[i])
+		}
+	case "max":
+		result.Number = numbers[0]
+		for i := range numbers {
+			result.Number = math.Max(result.Number, numbers[i])
+		}
+	case "round":
+		factor := 1.0
+		if len(numbers) == 2 {
+			factor = math.Pow(10, math.Round(numbers[1]))
+		}
+		result.Number = math.Round(numbers[0]*factor) / factor
+	case "abs":
+		result.Number = math.Abs(numbers[0])
+	case "number":
+		result.Number = numbers[0]
+	}
+	return result, nil
+}
@@ -0,0 +1,254 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExpressionEvaluate(t *testing.T) {
+	values := map[string]string{
+		"a":     "2",
+		"b":     "3",
+		"s":     "text",
+		"n":     " 4.5 ",
+		"t":     "true",
+		"child": "1",
+		"x-1":   "10",
+	}
+
+	tests := []struct {
+		expression string
+		want       string
+	}{
+		// Precedence
+		{"1 + 2 * 3", "7"},
+		{"(1 + 2) * 3", "9"},
+		{"10 - 4 - 3", "3"},
+		{"12 / 3 / 2", "2"},
+		{"7 % 4 + 1", "4"},
+		{"-2 * 3", "-6"},
+		{"--2", "2"},
+		{"1 + 2 < 4", "true"},
+		{"true || false && false", "true"},
+		{"(true || false) && false", "false"},
+		{"!false && true", "true"},
+		{"!(1 < 2)", "false"},
+
+		// Variables and coercion of string values
+		{"a + b", "5"},
+		{"{a} * {b}", "6"},
+		{"{x-1} + 1", "11"},
+		{"n * 2", "9"},
+		{"a == 2", "true"},
+		{"a == '2'", "true"},
+		{"a == 2.0", "true"},
+		{"s == 'text'", "true"},
+		{"s != \"text\"", "false"},
+		{"t && true", "true"},
+		{"t == true", "true"},
+
+		// Functions
+		{"sum(a, b, 1)", "6"},
+		{"mean(a, b)", "2.5"},
+		{"min(a, b, -1)", "-1"},
+		{"max(a, b)", "3"},
+		{"round(2.345, 2)", "2.35"},
+		{"round(2.5)", "3"},
+		{"abs(-3)", "3"},
+		{"if(a > b, 'a', 'b')", "b"},
+		{"if(child == 1, a, b)", "2"},
+		{"empty(missing)", "true"},
+		{"empty(a)", "false"},
+		{"number(n)", "4.5"},
+
+		// Short circuit skips missing values
+		{"false && missing", "false"},
+		{"true || missing", "true"},
+		{"if(true, 1, missing)", "1"},
+	}
+
+	for _, tc := range tests {
+		e, err := CompileExpression(tc.expression)
+		if err != nil {
+			t.Errorf("%s: can not compile: %s", tc.expression, err.Error())
+			continue
+		}
+		v, err := e.Evaluate(values)
+		if err != nil {
+			t.Errorf("%s: can not evaluate: %s", tc.expression, err.Error())
+			continue
+		}
+		if v.Format() != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.expression, v.Format(), tc.want)
+		}
+	}
+}
+
+func TestExpressionEvaluateError(t *testing.T) {
+	values := map[string]string{
+		"a":     "2",
+		"zero":  "0",
+		"s":     "text",
+		"empty": "",
+	}
+
+	tests := []struct {
+		expression string
+		missing    bool
+	}{
+		// Division by zero
+		{"1 / 0", false},
+		{"a / zero", false},
+		{"a % 0", false},
+		{"a / (a - 2)", false},
+
+		// Values which can not be converted
+		{"s + 1", false},
+		{"s && true", false},
+		{"number(s)", false},
+		{"s < 2", false},
+
+		// Missing values
+		{"missing + 1", true},
+		{"empty * 2", true},
+		{"missing && true", true},
+		{"sum(a, missing)", true},
+		{"if(missing, 1, 2)", true},
+	}
+
+	for _, tc := range tests {
+		e, err := CompileExpression(tc.expression)
+		if err != nil {
+			t.Errorf("%s: can not compile: %s", tc.expression, err.Error())
+			continue
+		}
+		_, err = e.Evaluate(values)
+		if err == nil {
+			t.Errorf("%s: expected error", tc.expression)
+			continue
+		}
+		if errors.Is(err, ErrExpressionMissingValue) != tc.missing {
+			t.Errorf("%s: got error '%s', missing value expected: %t", tc.expression, err.Error(), tc.missing)
+		}
+	}
+}
+
+func TestCompileExpressionError(t *testing.T) {
+	tests := []string{
+		// Syntax
+		"",
+		"1 +",
+		"(1 + 2",
+		"1 + 2)",
+		"1 2",
+		"a ++ b",
+		"1 # 2",
+		"'unterminated",
+		"{unterminated",
+		"{}",
+		"sum(1, 2",
+		"sum(1,)",
+		"1 < 2 < 3",
+		"1 < 2 == true",
+
+		// Functions
+		"unknown(1)",
+		"sum()",
+		"round(1, 2, 3)",
+		"abs(1, 2)",
+		"if(true, 1)",
+		"empty(1)",
+		"number('1')",
+
+		// Types
+		"'a' + 1",
+		"-'a'",
+		"!1",
+		"true + 1",
+		"'a' < 'b'",
+		"1 == 'a'",
+		"true == 1",
+		"1 && true",
+		"if(1, 2, 3)",
+		"if(true, 1, 'a')",
+		"sum(1, 'a')",
+	}
+
+	for _, tc := range tests {
+		_, err := CompileExpression(tc)
+		if err == nil {
+			t.Errorf("'%s': expected error", tc)
+		}
+	}
+}
+
+func TestExpressionCheck(t *testing.T) {
+	types := map[string]ExpressionType{
+		"num":  ExpressionNumber,
+		"str":  ExpressionString,
+		"flag": ExpressionBool,
+	}
+
+	tests := []struct {
+		expression string
+		want       ExpressionType
+		fail       bool
+	}{
+		{"num + 1", ExpressionNumber, false},
+		{"unknown + 1", ExpressionNumber, false},
+		{"str == 'a'", ExpressionBool, false},
+		{"flag && num > 1", ExpressionBool, false},
+		{"if(flag, num, 2)", ExpressionNumber, false},
+		{"if(flag, num, unknown)", ExpressionAny, false},
+		{"num", ExpressionNumber, false},
+		{"unknown", ExpressionAny, false},
+		{"number(str) + 1", ExpressionNumber, false},
+		{"str + 1", ExpressionNumber, true},
+		{"num == 'a'", ExpressionBool, true},
+		{"flag + 1", ExpressionNumber, true},
+		{"!str", ExpressionBool, true},
+		{"if(num, 1, 2)", ExpressionAny, true},
+		{"if(flag, num, str)", ExpressionAny, true},
+		{"sum(num, str)", ExpressionNumber, true},
+	}
+
+	for _, tc := range tests {
+		e, err := CompileExpression(tc.expression)
+		if err != nil {
+			t.Errorf("%s: can not compile: %s", tc.expression, err.Error())
+			continue
+		}
+		got, err := e.Check(types)
+		if tc.fail {
+			if err == nil {
+				t.Errorf("%s: expected error", tc.expression)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: can not check: %s", tc.expression, err.Error())
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: got type %s, want %s", tc.expression, got, tc.want)
+		}
+		if e.Type() != tc.want {
+			t.Errorf("%s: Type() returns %s after Check, want %s", tc.expression, e.Type(), tc.want)
+		}
+	}
+}
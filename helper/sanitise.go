@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020 Marcus Soll
+// Copyright 2020,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -16,16 +16,20 @@
 package helper
 
 import (
+	"html"
 	"html/template"
 	"io"
+	"strings"
 
 	"github.com/microcosm-cc/bluemonday"
 )
 
 var defaultPolicy *bluemonday.Policy
 var cleanPolicy *bluemonday.Policy
+var strictPolicy *bluemonday.Policy
 
 func init() {
+	strictPolicy = bluemonday.StrictPolicy()
 	cleanPolicy = bluemonday.NewPolicy()
 	cleanPolicy.AllowElements("img", "abbr")
 	cleanPolicy.AllowAttrs("title").OnElements("abbr")
@@ -78,3 +82,9 @@ func SanitiseStringClean(s string) template.HTML {
 func SanitiseByteClean(b []byte) template.HTML {
 	return template.HTML(cleanPolicy.SanitizeBytes(b))
 }
+
+// StripTags returns the text content of s with all HTML removed.
+// The result is plain text and must be escaped before it is used in HTML.
+func StripTags(s string) string {
+	return strings.TrimSpace(html.UnescapeString(strictPolicy.Sanitize(s)))
+}
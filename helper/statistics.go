@@ -0,0 +1,473 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"math"
+	"sort"
+)
+
+// ChiSquareResult holds the result of a chi-square test of independence.
+type ChiSquareResult struct {
+	Statistic   float64
+	DF          int
+	P           float64
+	CramersV    float64
+	LowExpected float64 // Share of cells with an expected count below 5. The test is not reliable if this is larger than 0.2.
+}
+
+// ChiSquareTest performs a chi-square test of independence on a contingency table (table[row][column]).
+// Rows and columns without any observation are ignored.
+// The bool is false if the test can not be performed, e.g. because there are less than two non-empty rows or columns.
+func ChiSquareTest(table [][]int) (ChiSquareResult, bool) {
+	rowSum := make([]float64, len(table))
+	columnSum := make([]float64, 0)
+	n := 0.0
+	for r := range table {
+		for c := range table[r] {
+			for len(columnSum) <= c {
+				columnSum = append(columnSum, 0)
+			}
+			rowSum[r] += float64(table[r][c])
+			columnSum[c] += float64(table[r][c])
+			n += float64(table[r][c])
+		}
+	}
+
+	rows, columns := 0, 0
+	for r := range rowSum {
+		if rowSum[r] > 0 {
+			rows++
+		}
+	}
+	for c := range columnSum {
+		if columnSum[c] > 0 {
+			columns++
+		}
+	}
+	if rows < 2 || columns < 2 {
+		return ChiSquareResult{}, false
+	}
+
+	result := ChiSquareResult{DF: (rows - 1) * (columns - 1)}
+	low := 0
+	for r := range rowSum {
+		if rowSum[r] == 0 {
+			continue
+		}
+		for c := range columnSum {
+			if columnSum[c] == 0 {
+				continue
+			}
+			observed := 0.0
+			if c < len(table[r]) {
+				observed = float64(table[r][c])
+			}
+			expected := rowSum[r] * columnSum[c] / n
+			if expected < 5 {
+				low++
+			}
+			result.Statistic += (observed - expected) * (observed - expected) / expected
+		}
+	}
+
+	result.P = ChiSquareP(result.Statistic, result.DF)
+	result.CramersV = math.Sqrt(result.Statistic / (n * float64(min(rows, columns)-1)))
+	result.LowExpected = float64(low) / float64(rows*columns)
+	return result, true
+}
+
+// ChiSquareP returns the probability of a chi-square distributed value with df degrees of freedom being at least x (upper tail).
+func ChiSquareP(x float64, df int) float64 {
+	if x <= 0 || df <= 0 {
+		return 1
+	}
+	return regularisedGammaQ(float64(df)/2, x/2)
+}
+
+// regularisedGammaQ returns the regularised upper incomplete gamma function Q(a, x).
+// It uses a series expansion for small x and a continued fraction otherwise (see Numerical Recipes, chapter 6.2).
+func regularisedGammaQ(a, x float64) float64 {
+	const iterations = 500
+	const epsilon = 1e-15
+
+	lgamma, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lgamma)
+
+	if x < a+1 {
+		// Series for P(a, x)
+		sum := 1 / a
+		term := sum
+		for i := 1; i < iterations; i++ {
+			term *= x / (a + float64(i))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*epsilon {
+				break
+			}
+		}
+		return math.Max(0, 1-sum*prefix)
+	}
+
+	// Continued fraction for Q(a, x) (modified Lentz's method)
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < iterations; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return math.Min(1, prefix*h)
+}
+
+// Descriptive holds descriptive statistics of a sample.
+type Descriptive struct {
+	N      int
+	Mean   float64
+	SD     float64 // Sample standard deviation. 0 if N < 2.
+	Min    float64
+	Q1     float64
+	Median float64
+	Q3     float64
+	Max    float64
+
+	Suppressed         bool // The statistics are hidden to protect the privacy of participants, e.g. because N is too small
+	ExtremesSuppressed bool // Min, quartiles, median and max are hidden since they might reveal single values, e.g. because counts of values are suppressed. N, mean and SD are still shown.
+}
+
+// DescriptiveStatistics computes descriptive statistics of v.
+// Quartiles are linearly interpolated between the closest ranks (the default of most spreadsheet software).
+// v is not modified. All values are 0 if v is empty.
+func DescriptiveStatistics(v []float64) Descriptive {
+	d := Descriptive{N: len(v)}
+	if len(v) == 0 {
+		return d
+	}
+
+	sorted := make([]float64, len(v))
+	copy(sorted, v)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for i := range sorted {
+		sum += sorted[i]
+	}
+	d.Mean = sum / float64(len(sorted))
+
+	if len(sorted) > 1 {
+		squares := 0.0
+		for i := range sorted {
+			squares += (sorted[i] - d.Mean) * (sorted[i] - d.Mean)
+		}
+		d.SD = math.Sqrt(squares / float64(len(sorted)-1))
+	}
+
+	d.Min = sorted[0]
+	d.Max = sorted[len(sorted)-1]
+	d.Q1 = quantile(sorted, 0.25)
+	d.Median = quantile(sorted, 0.5)
+	d.Q3 = quantile(sorted, 0.75)
+	return d
+}
+
+// WeightedDescriptiveStatistics returns the descriptive statistics of v, where each value v[i] has the weight w[i].
+// Values with a weight of 0 or below are ignored. N is the number of values used.
+// The standard deviation is corrected for the number of values. The quartiles are interpolated like in DescriptiveStatistics,
+// with each value placed at the centre of its share of the total weight.
+// If w is nil, it is equal to DescriptiveStatistics(v).
+func WeightedDescriptiveStatistics(v, w []float64) Descriptive {
+	if w == nil {
+		return DescriptiveStatistics(v)
+	}
+
+	type weighted struct {
+		value  float64
+		weight float64
+	}
+	sorted := make([]weighted, 0, len(v))
+	for i := range v {
+		if i < len(w) && w[i] > 0 {
+			sorted = append(sorted, weighted{v[i], w[i]})
+		}
+	}
+	d := Descriptive{N: len(sorted)}
+	if len(sorted) == 0 {
+		return d
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].value < sorted[j].value })
+
+	sum, sumWeights := 0.0, 0.0
+	for i := range sorted {
+		sum += sorted[i].value * sorted[i].weight
+		sumWeights += sorted[i].weight
+	}
+	d.Mean = sum / sumWeights
+
+	if len(sorted) > 1 {
+		squares := 0.0
+		for i := range sorted {
+			squares += sorted[i].weight * (sorted[i].value - d.Mean) * (sorted[i].value - d.Mean)
+		}
+		n := float64(len(sorted))
+		d.SD = math.Sqrt(squares / sumWeights * n / (n - 1))
+	}
+
+	// Each value is placed at the centre of its weight, and the quantiles are interpolated between these positions
+	// the same way as in DescriptiveStatistics. With equal weights, both are identical.
+	positions := make([]float64, len(sorted))
+	cumulative := 0.0
+	for i := range sorted {
+		positions[i] = cumulative + sorted[i].weight/2
+		cumulative += sorted[i].weight
+	}
+	first, last := positions[0], positions[len(positions)-1]
+	quantile := func(q float64) float64 {
+		target := first + q*(last-first)
+		for i := 1; i < len(sorted); i++ {
+			if positions[i] >= target {
+				return sorted[i-1].value + (target-positions[i-1])/(positions[i]-positions[i-1])*(sorted[i].value-sorted[i-1].value)
+			}
+		}
+		return sorted[len(sorted)-1].value
+	}
+
+	d.Min = sorted[0].value
+	d.Max = sorted[len(sorted)-1].value
+	d.Q1 = quantile(0.25)
+	d.Median = quantile(0.5)
+	d.Q3 = quantile(0.75)
+	return d
+}
+
+// quantile returns the q quantile of the sorted, non-empty slice.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (pos-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+// TTestResult holds the result of Welch's t-test for two independent samples.
+type TTestResult struct {
+	T       float64
+	DF      float64
+	P       float64 // two-sided
+	CohensD float64 // Difference of the means divided by the pooled standard deviation
+}
+
+// WelchTTest performs Welch's t-test for the difference of the means of two independent samples.
+// The bool is false if the test can not be performed, e.g. because a sample has less than two values or both have no variance.
+func WelchTTest(a, b []float64) (TTestResult, bool) {
+	if len(a) < 2 || len(b) < 2 {
+		return TTestResult{}, false
+	}
+	da := DescriptiveStatistics(a)
+	db := DescriptiveStatistics(b)
+	na := float64(da.N)
+	nb := float64(db.N)
+	va := da.SD * da.SD / na
+	vb := db.SD * db.SD / nb
+	if va+vb == 0 {
+		return TTestResult{}, false
+	}
+
+	var result TTestResult
+	result.T = (da.Mean - db.Mean) / math.Sqrt(va+vb)
+	result.DF = (va + vb) * (va + vb) / (va*va/(na-1) + vb*vb/(nb-1))
+	result.P = StudentTP(result.T, result.DF)
+	pooled := math.Sqrt(((na-1)*da.SD*da.SD + (nb-1)*db.SD*db.SD) / (na + nb - 2))
+	if pooled > 0 {
+		result.CohensD = (da.Mean - db.Mean) / pooled
+	}
+	return result, true
+}
+
+// ANOVAResult holds the result of a one-way analysis of variance.
+type ANOVAResult struct {
+	F          float64
+	DFBetween  int
+	DFWithin   int
+	P          float64
+	EtaSquared float64 // Share of the variance explained by the groups
+}
+
+// OneWayANOVA performs a one-way analysis of variance of the groups.
+// Empty groups are ignored.
+// The bool is false if the test can not be performed, e.g. because there are less than two non-empty groups or there is no variance within the groups.
+func OneWayANOVA(groups [][]float64) (ANOVAResult, bool) {
+	sum := 0.0
+	n := 0
+	k := 0
+	for i := range groups {
+		if len(groups[i]) == 0 {
+			continue
+		}
+		k++
+		for j := range groups[i] {
+			sum += groups[i][j]
+			n++
+		}
+	}
+	if k < 2 || n <= k {
+		return ANOVAResult{}, false
+	}
+	mean := sum / float64(n)
+
+	between := 0.0
+	within := 0.0
+	for i := range groups {
+		if len(groups[i]) == 0 {
+			continue
+		}
+		d := DescriptiveStatistics(groups[i])
+		between += float64(d.N) * (d.Mean - mean) * (d.Mean - mean)
+		for j := range groups[i] {
+			within += (groups[i][j] - d.Mean) * (groups[i][j] - d.Mean)
+		}
+	}
+	if within == 0 {
+		return ANOVAResult{}, false
+	}
+
+	var result ANOVAResult
+	result.DFBetween = k - 1
+	result.DFWithin = n - k
+	result.F = (between / float64(result.DFBetween)) / (within / float64(result.DFWithin))
+	result.P = FP(result.F, float64(result.DFBetween), float64(result.DFWithin))
+	result.EtaSquared = between / (between + within)
+	return result, true
+}
+
+// StudentTP returns the two-sided probability of a t distributed value with df degrees of freedom being at least as extreme as t.
+func StudentTP(t, df float64) float64 {
+	if df <= 0 || math.IsNaN(t) {
+		return 1
+	}
+	return regularisedIncompleteBeta(df/2, 0.5, df/(df+t*t))
+}
+
+// FP returns the probability of an F distributed value with df1 and df2 degrees of freedom being at least f (upper tail).
+func FP(f, df1, df2 float64) float64 {
+	if f <= 0 || df1 <= 0 || df2 <= 0 {
+		return 1
+	}
+	return regularisedIncompleteBeta(df2/2, df1/2, df2/(df2+df1*f))
+}
+
+// HolmCorrection adjusts p-values for multiple comparisons with the Holm-Bonferroni method.
+// The adjusted values are returned in the order of p.
+func HolmCorrection(p []float64) []float64 {
+	order := make([]int, len(p))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return p[order[i]] < p[order[j]] })
+
+	result := make([]float64, len(p))
+	max := 0.0
+	for rank, i := range order {
+		adjusted := math.Min(1, float64(len(p)-rank)*p[i])
+		// Adjusted values must not decrease
+		max = math.Max(max, adjusted)
+		result[i] = max
+	}
+	return result
+}
+
+// regularisedIncompleteBeta returns the regularised incomplete beta function I_x(a, b).
+// It uses a continued fraction (see Numerical Recipes, chapter 6.4).
+func regularisedIncompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	prefix := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+
+	// The continued fraction converges quickly for x < (a+1)/(a+b+2), otherwise use the symmetry relation
+	if x > (a+1)/(a+b+2) {
+		return 1 - prefix*betaContinuedFraction(b, a, 1-x)/b
+	}
+	return prefix * betaContinuedFraction(a, b, x) / a
+}
+
+// betaContinuedFraction evaluates the continued fraction of the incomplete beta function (modified Lentz's method).
+func betaContinuedFraction(a, b, x float64) float64 {
+	const iterations = 500
+	const epsilon = 1e-15
+	const tiny = 1e-300
+
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for i := 1; i < iterations; i++ {
+		m := float64(i)
+		// Even step
+		an := m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+		d = 1 + an*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		// Odd step
+		an = -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+		d = 1 + an*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,307 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"math"
+	"testing"
+)
+
+// almostEqual compares floats with an absolute tolerance, since reference values are rounded.
+func almostEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestDescriptiveStatistics(t *testing.T) {
+	// Reference values: R summary() and sd(), which use the same quantile definition (type 7)
+	tests := []struct {
+		v                        []float64
+		mean, sd, q1, median, q3 float64
+	}{
+		{[]float64{1, 2, 3, 4}, 2.5, 1.290994, 1.75, 2.5, 3.25},
+		{[]float64{4, 1, 3, 2, 5}, 3, 1.581139, 2, 3, 4},
+		{[]float64{2, 7, 1, 8, 2, 8}, 4.666667, 3.326660, 2, 4.5, 7.75},
+		{[]float64{5}, 5, 0, 5, 5, 5},
+	}
+
+	for _, test := range tests {
+		d := DescriptiveStatistics(test.v)
+		if d.N != len(test.v) || !almostEqual(d.Mean, test.mean, 1e-6) || !almostEqual(d.SD, test.sd, 1e-6) || !almostEqual(d.Q1, test.q1, 1e-9) || !almostEqual(d.Median, test.median, 1e-9) || !almostEqual(d.Q3, test.q3, 1e-9) {
+			t.Errorf("DescriptiveStatistics(%v) = %+v", test.v, d)
+		}
+	}
+}
+
+func TestWeightedDescriptiveStatistics(t *testing.T) {
+	sets := [][]float64{
+		{1, 2, 3, 4},
+		{4, 1, 3, 2, 5},
+		{2, 7, 1, 8, 2, 8},
+		{5},
+	}
+
+	// Equal weights must not change anything, whatever their size
+	for _, v := range sets {
+		want := DescriptiveStatistics(v)
+		for _, weight := range []float64{1, 0.5, 3} {
+			w := make([]float64, len(v))
+			for i := range w {
+				w[i] = weight
+			}
+			got := WeightedDescriptiveStatistics(v, w)
+			if got.N != want.N || !almostEqual(got.Mean, want.Mean, 1e-9) || !almostEqual(got.SD, want.SD, 1e-9) || !almostEqual(got.Q1, want.Q1, 1e-9) || !almostEqual(got.Median, want.Median, 1e-9) || !almostEqual(got.Q3, want.Q3, 1e-9) {
+				t.Errorf("WeightedDescriptiveStatistics(%v, %v) = %+v, want %+v", v, w, got, want)
+			}
+		}
+	}
+
+	// Values with a weight of 0 are ignored
+	got := WeightedDescriptiveStatistics([]float64{1, 100, 2, 3, 4}, []float64{1, 0, 1, 1, 1})
+	want := DescriptiveStatistics([]float64{1, 2, 3, 4})
+	if got != want {
+		t.Errorf("WeightedDescriptiveStatistics with zero weight = %+v, want %+v", got, want)
+	}
+
+	// A heavy value pulls the mean and the quartiles towards it
+	got = WeightedDescriptiveStatistics([]float64{1, 2, 3}, []float64{1, 1, 10})
+	if !almostEqual(got.Mean, 33.0/12.0, 1e-9) {
+		t.Errorf("weighted mean = %v, want %v", got.Mean, 33.0/12.0)
+	}
+	if got.Median <= 2 || got.Median >= 3 || got.Q1 >= got.Median || got.Q3 <= got.Median {
+		t.Errorf("weighted quartiles not pulled towards heavy value: %+v", got)
+	}
+
+	// Only zero weights
+	got = WeightedDescriptiveStatistics([]float64{1, 2}, []float64{0, 0})
+	if got.N != 0 || math.IsNaN(got.Mean) {
+		t.Errorf("WeightedDescriptiveStatistics with only zero weights = %+v", got)
+	}
+}
+
+func TestChiSquareTest(t *testing.T) {
+	// Reference values: R chisq.test(table, correct = FALSE), Cramér's V = sqrt(X² / (n * (min(rows, columns) - 1)))
+	tests := []struct {
+		table     [][]int
+		statistic float64
+		df        int
+		p         float64
+		cramersV  float64
+	}{
+		// Example of the R documentation of chisq.test
+		{[][]int{{762, 327, 468}, {484, 239, 477}}, 30.070149, 2, 2.953589e-07, 0.104436},
+		{[][]int{{10, 20}, {30, 40}}, 0.793651, 1, 0.372998, 0.089087},
+		{[][]int{{12, 5, 9}, {7, 11, 6}, {3, 8, 14}}, 11.040836, 4, 0.026109, 0.271303},
+		// Empty rows and columns are ignored
+		{[][]int{{10, 0, 20}, {0, 0, 0}, {30, 0, 40}}, 0.793651, 1, 0.372998, 0.089087},
+	}
+
+	for _, test := range tests {
+		r, ok := ChiSquareTest(test.table)
+		if !ok {
+			t.Errorf("ChiSquareTest(%v) can not be performed", test.table)
+			continue
+		}
+		if !almostEqual(r.Statistic, test.statistic, 1e-6) || r.DF != test.df || !almostEqual(r.P, test.p, 1e-5*test.p) || !almostEqual(r.CramersV, test.cramersV, 1e-6) {
+			t.Errorf("ChiSquareTest(%v) = %+v, want statistic %v, df %d, p %v, V %v", test.table, r, test.statistic, test.df, test.p, test.cramersV)
+		}
+	}
+
+	r, ok := ChiSquareTest([][]int{{2, 3}, {1, 4}})
+	if !ok || r.LowExpected != 1 {
+		t.Errorf("ChiSquareTest with small expected counts = %+v, %v, want LowExpected 1", r, ok)
+	}
+
+	for _, table := range [][][]int{{}, {{5, 6}}, {{5}, {6}}, {{5, 0}, {6, 0}}} {
+		_, ok := ChiSquareTest(table)
+		if ok {
+			t.Errorf("ChiSquareTest(%v) should not be performed", table)
+		}
+	}
+}
+
+func TestChiSquareP(t *testing.T) {
+	// Closed forms: df=1: erfc(sqrt(x/2)), df=2: exp(-x/2), df=4: exp(-x/2)(1+x/2)
+	for _, x := range []float64{0.01, 0.5, 1, 3.84, 10, 40} {
+		tests := []struct {
+			df   int
+			want float64
+		}{
+			{1, math.Erfc(math.Sqrt(x / 2))},
+			{2, math.Exp(-x / 2)},
+			{4, math.Exp(-x/2) * (1 + x/2)},
+		}
+		for _, test := range tests {
+			got := ChiSquareP(x, test.df)
+			if !almostEqual(got, test.want, 1e-10) {
+				t.Errorf("ChiSquareP(%v, %d) = %v, want %v", x, test.df, got, test.want)
+			}
+		}
+	}
+	if ChiSquareP(0, 3) != 1 {
+		t.Errorf("ChiSquareP(0, 3) = %v, want 1", ChiSquareP(0, 3))
+	}
+}
+
+func TestWelchTTest(t *testing.T) {
+	// Reference values: the first one is the sleep data set of R (t.test(extra ~ group, data = sleep)),
+	// the second one is computed from the formulas with a numerically integrated p-value
+	tests := []struct {
+		a, b     []float64
+		t, df, p float64
+		cohensD  float64
+	}{
+		{
+			[]float64{0.7, -1.6, -0.2, -1.2, -0.1, 3.4, 3.7, 0.8, 0.0, 2.0},
+			[]float64{1.9, 0.8, 1.1, 0.1, -0.1, 4.4, 5.5, 1.6, 4.6, 3.4},
+			-1.860813, 17.776474, 0.079394, -0.832181,
+		},
+		{
+			[]float64{19.1, 21.3, 18.7, 22.4, 20.0, 23.5},
+			[]float64{24.2, 26.8, 22.1, 27.5, 25.0, 28.9, 23.7, 26.3},
+			-4.283437, 11.718051, 0.001120, -2.258135,
+		},
+	}
+
+	for _, test := range tests {
+		r, ok := WelchTTest(test.a, test.b)
+		if !ok {
+			t.Errorf("WelchTTest(%v, %v) can not be performed", test.a, test.b)
+			continue
+		}
+		if !almostEqual(r.T, test.t, 1e-6) || !almostEqual(r.DF, test.df, 1e-6) || !almostEqual(r.P, test.p, 1e-6) || !almostEqual(r.CohensD, test.cohensD, 1e-6) {
+			t.Errorf("WelchTTest(%v, %v) = %+v, want t %v, df %v, p %v, d %v", test.a, test.b, r, test.t, test.df, test.p, test.cohensD)
+		}
+
+		// Swapping the samples only changes the sign
+		s, _ := WelchTTest(test.b, test.a)
+		if !almostEqual(s.T, -r.T, 1e-12) || !almostEqual(s.DF, r.DF, 1e-12) || !almostEqual(s.P, r.P, 1e-12) {
+			t.Errorf("WelchTTest is not symmetric: %+v and %+v", r, s)
+		}
+	}
+
+	for _, test := range [][2][]float64{{{1}, {1, 2}}, {{1, 1}, {2, 2}}} {
+		_, ok := WelchTTest(test[0], test[1])
+		if ok {
+			t.Errorf("WelchTTest(%v, %v) should not be performed", test[0], test[1])
+		}
+	}
+}
+
+func TestStudentTP(t *testing.T) {
+	// Closed forms: df=1 (Cauchy): 1 - 2 atan(|t|) / pi, df=2: 1 - |t| / sqrt(t² + 2)
+	for _, x := range []float64{0, 0.3, 1, -2.5, 12} {
+		if got, want := StudentTP(x, 1), 1-2*math.Atan(math.Abs(x))/math.Pi; !almostEqual(got, want, 1e-10) {
+			t.Errorf("StudentTP(%v, 1) = %v, want %v", x, got, want)
+		}
+		if got, want := StudentTP(x, 2), 1-math.Abs(x)/math.Sqrt(x*x+2); !almostEqual(got, want, 1e-10) {
+			t.Errorf("StudentTP(%v, 2) = %v, want %v", x, got, want)
+		}
+	}
+}
+
+func TestOneWayANOVA(t *testing.T) {
+	// Reference values: the first one is the PlantGrowth data set of R (summary(aov(weight ~ group, data = PlantGrowth))),
+	// the second one is computed from the formulas with the closed form of the p-value for two degrees of freedom between the groups
+	tests := []struct {
+		groups              [][]float64
+		f                   float64
+		dfBetween, dfWithin int
+		p, etaSquared       float64
+	}{
+		{
+			[][]float64{
+				{4.17, 5.58, 5.18, 6.11, 4.50, 4.61, 5.17, 4.53, 5.33, 5.14},
+				{4.81, 4.17, 4.41, 3.59, 5.87, 3.83, 6.03, 4.89, 4.32, 4.69},
+				{6.31, 5.12, 5.54, 5.50, 5.37, 5.29, 4.92, 6.15, 5.80, 5.26},
+			},
+			4.846088, 2, 27, 0.015910, 0.264148,
+		},
+		{[][]float64{{1, 2, 3}, {4, 5, 6, 7}, {2, 9}}, 2.333333, 2, 6, 0.177979, 0.4375},
+		// Empty groups are ignored
+		{[][]float64{{1, 2, 3}, {}, {4, 5, 6, 7}, {2, 9}}, 2.333333, 2, 6, 0.177979, 0.4375},
+	}
+
+	for _, test := range tests {
+		r, ok := OneWayANOVA(test.groups)
+		if !ok {
+			t.Errorf("OneWayANOVA(%v) can not be performed", test.groups)
+			continue
+		}
+		if !almostEqual(r.F, test.f, 1e-6) || r.DFBetween != test.dfBetween || r.DFWithin != test.dfWithin || !almostEqual(r.P, test.p, 1e-6) || !almostEqual(r.EtaSquared, test.etaSquared, 1e-6) {
+			t.Errorf("OneWayANOVA(%v) = %+v, want F %v, df %d/%d, p %v, eta² %v", test.groups, r, test.f, test.dfBetween, test.dfWithin, test.p, test.etaSquared)
+		}
+	}
+
+	for _, groups := range [][][]float64{{{1, 2, 3}}, {{1}, {2}}, {{1, 1}, {2, 2}}} {
+		_, ok := OneWayANOVA(groups)
+		if ok {
+			t.Errorf("OneWayANOVA(%v) should not be performed", groups)
+		}
+	}
+}
+
+func TestFP(t *testing.T) {
+	// Closed form for df1=2: (1 + 2f / df2)^(-df2 / 2)
+	for _, f := range []float64{0.1, 1, 4.846088, 20} {
+		for _, df2 := range []float64{3, 10, 27} {
+			if got, want := FP(f, 2, df2), math.Pow(1+2*f/df2, -df2/2); !almostEqual(got, want, 1e-10) {
+				t.Errorf("FP(%v, 2, %v) = %v, want %v", f, df2, got, want)
+			}
+		}
+	}
+}
+
+func TestHolmCorrection(t *testing.T) {
+	// Reference values: R p.adjust(p, method = "holm")
+	tests := []struct {
+		p, want []float64
+	}{
+		{[]float64{0.01, 0.04, 0.03, 0.005}, []float64{0.03, 0.06, 0.06, 0.02}},
+		{[]float64{0.2, 0.5, 0.01}, []float64{0.4, 0.5, 0.03}},
+		{[]float64{0.3, 0.6, 0.9}, []float64{0.9, 1, 1}},
+		{[]float64{0.02, 0.02}, []float64{0.04, 0.04}},
+		{[]float64{0.04}, []float64{0.04}},
+		{[]float64{}, []float64{}},
+	}
+
+	for _, test := range tests {
+		got := HolmCorrection(test.p)
+		if len(got) != len(test.want) {
+			t.Errorf("HolmCorrection(%v) = %v, want %v", test.p, got, test.want)
+			continue
+		}
+		for i := range got {
+			if !almostEqual(got[i], test.want[i], 1e-12) {
+				t.Errorf("HolmCorrection(%v) = %v, want %v", test.p, got, test.want)
+				break
+			}
+		}
+	}
+
+	// Adjusted values keep the order of the raw values, are never smaller than them and never larger than 1
+	p := []float64{0.001, 0.8, 0.04, 0.011, 0.3, 0.012, 0.0499, 0.2}
+	adjusted := HolmCorrection(p)
+	for i := range p {
+		if adjusted[i] < p[i] || adjusted[i] > 1 {
+			t.Errorf("HolmCorrection(%v)[%d] = %v out of range", p, i, adjusted[i])
+		}
+		for j := range p {
+			if p[i] < p[j] && adjusted[i] > adjusted[j] {
+				t.Errorf("HolmCorrection(%v) is not monotone: %v", p, adjusted)
+			}
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"bytes"
+	"html/template"
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WordCount holds how often a word (or a pair of words) occurs.
+type WordCount struct {
+	Word  string
+	Count int
+}
+
+var textAnswersTemplate = template.Must(template.New("textAnswersTemplate").Parse(`{{if .Words}}
+<details>
+<summary>show word frequencies</summary>
+<div class="flex-container">
+<div class="flex-item">
+<table>
+<thead>
+<tr>
+<th>Word</th>
+<th>Number</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Words}}
+<tr>
+<td>{{$e.Word}}</td>
+<td>{{$e.Count}}</td>
+</tr>
+{{end}}
+</tbody>
+</table>
+</div>
+{{if .Bigrams}}
+<div class="flex-item">
+<table>
+<thead>
+<tr>
+<th>Word pair</th>
+<th>Number</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Bigrams}}
+<tr>
+<td>{{$e.Word}}</td>
+<td>{{$e.Count}}</td>
+</tr>
+{{end}}
+</tbody>
+</table>
+</div>
+{{end}}
+</div>
+</details>
+{{end}}
+<details>
+<summary>show results ({{len .Answers}})</summary>
+<p hidden id="{{.ID}}_controls"><input type="search" id="{{.ID}}_search" placeholder="Search answers"> <span id="{{.ID}}_info"></span></p>
+<ol id="{{.ID}}_answers">
+{{range $i, $e := .Answers}}
+<li value="{{$e.Number}}">{{$e.Text}}</li>
+{{end}}
+</ol>
+<p hidden id="{{.ID}}_pages"><button type="button" id="{{.ID}}_previous">previous</button> <span id="{{.ID}}_page"></span> <button type="button" id="{{.ID}}_next">next</button></p>
+<script>
+(function() {
+	var id = {{.ID}};
+	var pageSize = {{.PageSize}};
+	var items = document.getElementById(id + "_answers").children;
+	var search = document.getElementById(id + "_search");
+	var page = 0;
+	function update() {
+		var query = search.value.toLowerCase();
+		var matching = [];
+		for (var i = 0; i < items.length; i++) {
+			items[i].hidden = true;
+			if (query === "" || items[i].textContent.toLowerCase().indexOf(query) !== -1) {
+				matching.push(items[i]);
+			}
+		}
+		var pages = Math.max(1, Math.ceil(matching.length / pageSize));
+		page = Math.min(Math.max(page, 0), pages - 1);
+		for (var i = page * pageSize; i < Math.min(matching.length, (page + 1) * pageSize); i++) {
+			matching[i].hidden = false;
+		}
+		document.getElementById(id + "_info").textContent = matching.length + " of " + items.length + " answers";
+		document.getElementById(id + "_page").textContent = "page " + (page + 1) + " of " + pages;
+		document.getElementById(id + "_pages").hidden = pages < 2;
+	}
+	search.addEventListener("input", function() { page = 0; update(); });
+	document.getElementById(id + "_previous").addEventListener("click", function() { page--; update(); });
+	document.getElementById(id + "_next").addEventListener("click", function() { page++; update(); });
+	document.getElementById(id + "_controls").hidden = false;
+	update();
+})();
+</script>
+</details>
+`))
+
+type textAnswersTemplateStruct struct {
+	ID       string
+	PageSize int
+	Words    []WordCount
+	Bigrams  []WordCount
+	Answers  []textAnswersTemplateStructInner
+}
+
+type textAnswersTemplateStructInner struct {
+	Number int
+	Text   string
+}
+
+// Words splits s into lower case words. Stop words of the language as well as words with a single character are removed.
+// Removed words and punctuation are replaced by a single empty string so that callers can detect which words were adjacent.
+func Words(s, language string) []string {
+	stop := stopWords[language]
+	result := make([]string, 0)
+	separate := func() {
+		if len(result) > 0 && result[len(result)-1] != "" {
+			result = append(result, "")
+		}
+	}
+	word := make([]rune, 0)
+	addWord := func() {
+		w := strings.Trim(string(word), "'’")
+		word = word[:0]
+		if w == "" {
+			return
+		}
+		if utf8.RuneCountInString(w) < 2 || stop[strings.ReplaceAll(w, "’", "'")] {
+			separate()
+			return
+		}
+		result = append(result, w)
+	}
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || r == '\'' || r == '’':
+			word = append(word, r)
+		case unicode.IsSpace(r):
+			addWord()
+		default:
+			addWord()
+			separate()
+		}
+	}
+	addWord()
+	if len(result) > 0 && result[len(result)-1] == "" {
+		result = result[:len(result)-1]
+	}
+	return result
+}
+
+// WordFrequencies counts how often the words and pairs of adjacent words occur in the texts.
+// Stop words are removed based on the language (see Words). At most limit entries are returned for each, sorted by the number of occurrences.
+func WordFrequencies(texts []string, language string, limit int) (words []WordCount, bigrams []WordCount) {
+	wordCount := make(map[string]int)
+	bigramCount := make(map[string]int)
+	for t := range texts {
+		w := Words(texts[t], language)
+		for i := range w {
+			if w[i] == "" {
+				continue
+			}
+			wordCount[w[i]]++
+			if i > 0 && w[i-1] != "" {
+				bigramCount[strings.Join([]string{w[i-1], w[i]}, " ")]++
+			}
+		}
+	}
+	return sortWordCount(wordCount, limit), sortWordCount(bigramCount, limit)
+}
+
+func sortWordCount(m map[string]int, limit int) []WordCount {
+	result := make([]WordCount, 0, len(m))
+	for k := range m {
+		result = append(result, WordCount{Word: k, Count: m[k]})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Word < result[j].Word
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// TextAnswers returns a save HTML fragment showing free text answers.
+// It contains the most frequent words and word pairs as well as the answers with a search box and pagination.
+// id must be unique on the page.
+func TextAnswers(answers []string, id, language string) template.HTML {
+	words, bigrams := WordFrequencies(answers, language, 25)
+	td := textAnswersTemplateStruct{
+		ID:       id,
+		PageSize: 50,
+		Words:    words,
+		Bigrams:  bigrams,
+		Answers:  make([]textAnswersTemplateStructInner, len(answers)),
+	}
+	for i := range answers {
+		td.Answers[i] = textAnswersTemplateStructInner{Number: i + 1, Text: answers[i]}
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := textAnswersTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("text answers: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2022 Marcus Soll
+// Copyright 2020,2022,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -29,7 +29,11 @@ import (
 	"syscall"
 	"time"
 
+	// Time zone database for appointments, since it might not be available on the server
+	_ "time/tzdata"
+
 	// Register types
+	_ "github.com/Top-Ranger/questiongo/blobstore"
 	_ "github.com/Top-Ranger/questiongo/datasafe"
 	_ "github.com/Top-Ranger/questiongo/format"
 	_ "github.com/Top-Ranger/questiongo/passwordmethods"
@@ -49,6 +53,9 @@ type Config struct {
 	DataFolder            string
 	DataSafe              string
 	DataSafeConfig        string
+	BlobStore             string
+	BlobStoreConfig       string
+	MaxRequestSize        int64
 	LogFailedLogin        bool
 	ServerPath            string
 	ReloadPasswordsMethod string
@@ -78,6 +85,11 @@ func loadConfig(path string) (Config, error) {
 	}
 	c.ServerPath = strings.TrimSuffix(c.ServerPath, "/")
 
+	if c.MaxRequestSize <= 0 {
+		log.Println("load config: MaxRequestSize not set, using 50 MiB")
+		c.MaxRequestSize = 50 << 20
+	}
+
 	if c.ReloadPasswordsMethod != "" && len(c.ReloadPasswords) != 0 {
 		ok := registry.PasswordMethodExists(c.ReloadPasswordsMethod)
 		if !ok {
@@ -150,6 +162,26 @@ func main() {
 		log.Panicln(err)
 	}
 
+	var blobstore registry.BlobStore
+	if config.BlobStore != "" {
+		blobstore, ok = registry.GetBlobStore(config.BlobStore)
+		if !ok {
+			log.Panicf("main: Unknown blob store %s", config.BlobStore)
+		}
+
+		b, err = os.ReadFile(config.BlobStoreConfig)
+		if err != nil {
+			log.Panicln(err)
+		}
+
+		err = blobstore.LoadConfig(b)
+		if err != nil {
+			log.Panicln(err)
+		}
+	} else {
+		log.Println("main: no blob store configured, file uploads are disabled")
+	}
+
 	RunServer()
 
 	s := make(chan os.Signal, 1)
@@ -159,7 +191,11 @@ func main() {
 
 	for range s {
 		StopServer()
+		flushAndCloseFieldwork()
 		datasafe.FlushAndClose()
+		if blobstore != nil {
+			blobstore.FlushAndClose()
+		}
 		return
 	}
 }
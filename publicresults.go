@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"sync"
+	"time"
+)
+
+// QuestionnairePublicResults enables a public results page without password.
+// Only the questions listed in Questions are shown, in the given order.
+// The page is rendered at most once every CacheSeconds (defaults to defaultPublicResultsCache).
+type QuestionnairePublicResults struct {
+	Questions    []string
+	CacheSeconds int
+}
+
+// defaultPublicResultsCache is the time in seconds a public results page is cached if none is given.
+const defaultPublicResultsCache = 60
+
+// checkPublicResults validates the public results of the questionnaire and resolves the shown questions.
+// It must be called after all questions are loaded.
+func (q *Questionnaire) checkPublicResults() error {
+	if q.PublicResults == nil {
+		return nil
+	}
+	if len(q.PublicResults.Questions) == 0 {
+		return fmt.Errorf("public results: no questions given")
+	}
+	if q.PublicResults.CacheSeconds < 0 {
+		return fmt.Errorf("public results: CacheSeconds must be positive, is %d", q.PublicResults.CacheSeconds)
+	}
+	if q.PublicResults.CacheSeconds == 0 {
+		q.PublicResults.CacheSeconds = defaultPublicResultsCache
+	}
+
+	index := make(map[string]int, len(q.allQuestions))
+	for i := range q.allQuestions {
+		index[q.allQuestions[i].GetID()] = i
+	}
+
+	q.publicQuestions = make([]int, len(q.PublicResults.Questions))
+	for p := range q.PublicResults.Questions {
+		i, ok := index[q.PublicResults.Questions[p]]
+		if !ok {
+			return fmt.Errorf("public results: unknown question %s", q.PublicResults.Questions[p])
+		}
+		q.publicQuestions[p] = i
+	}
+	return nil
+}
+
+// GetPublicResults returns a save html fragment containing the results of each question shown on the public results page.
+// Weighting and small-cell suppression apply as on the normal results page, but sections only meant for analysts (e.g. the coded answers) are left out.
+func (q Questionnaire) GetPublicResults() ([]template.HTML, error) {
+	if q.PublicResults == nil {
+		return nil, fmt.Errorf("public results are not enabled")
+	}
+	return q.getResults(q.publicQuestions, ResultFilter{}, false)
+}
+
+// publicResultsCacheEntry holds a rendered public results page.
+type publicResultsCacheEntry struct {
+	page    []byte
+	expires time.Time
+}
+
+var publicResultsCacheLock sync.Mutex
+var publicResultsCache = make(map[string]publicResultsCacheEntry)
+
+// getPublicResultsCache returns the cached page of a questionnaire if it is not expired.
+func getPublicResultsCache(key string) ([]byte, bool) {
+	publicResultsCacheLock.Lock()
+	defer publicResultsCacheLock.Unlock()
+	e, ok := publicResultsCache[key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.page, true
+}
+
+// setPublicResultsCache caches the page of a questionnaire for the given duration.
+func setPublicResultsCache(key string, page []byte, d time.Duration) {
+	publicResultsCacheLock.Lock()
+	defer publicResultsCacheLock.Unlock()
+	publicResultsCache[key] = publicResultsCacheEntry{page: page, expires: time.Now().Add(d)}
+}
+
+// clearPublicResultsCache removes all cached pages, e.g. after the questionnaires are reloaded.
+func clearPublicResultsCache() {
+	publicResultsCacheLock.Lock()
+	defer publicResultsCacheLock.Unlock()
+	publicResultsCache = make(map[string]publicResultsCacheEntry)
+}
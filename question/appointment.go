@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020 Marcus Soll
+// Copyright 2020,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -19,14 +19,17 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"html"
 	"html/template"
 	"log"
 	"math"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Top-Ranger/questiongo/helper"
 	"github.com/Top-Ranger/questiongo/registry"
 	"github.com/Top-Ranger/questiongo/translation"
 )
@@ -36,6 +39,8 @@ var appointmentDateFormatWrite = "02.01.2006 15:04"
 var appointmentDateFormatWriteNoTime = "02.01.2006"
 var appointmentDateFormatID = "02.01.2006T15:04"
 var appointmentDateFormatIDNoTime = "02.01.2006"
+var appointmentDateFormatCapacity = "2006-01-02T15:04"
+var appointmentDateFormatCapacityNoTime = "2006-01-02"
 
 func init() {
 	err := registry.RegisterQuestionType(FactoryAppointment, "appointment")
@@ -83,11 +88,19 @@ func FactoryAppointment(data []byte, id string, language string) (registry.Quest
 		return nil, fmt.Errorf("appointment: Can not get translation for language '%s' (%s)", language, id)
 	}
 
-	fd, err := time.Parse(appointmentDateFormatRead, a.FirstDate)
+	loc := time.UTC
+	if a.TimeZone != "" {
+		loc, err = time.LoadLocation(a.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("appointment: Unknown time zone '%s' - %s", a.TimeZone, err.Error())
+		}
+	}
+
+	fd, err := time.ParseInLocation(appointmentDateFormatRead, a.FirstDate, loc)
 	if err != nil {
 		return nil, fmt.Errorf("appointment: can not parse '%s' - %s", a.FirstDate, err.Error())
 	}
-	ld, err := time.Parse(appointmentDateFormatRead, a.LastDate)
+	ld, err := time.ParseInLocation(appointmentDateFormatRead, a.LastDate, loc)
 	if err != nil {
 		return nil, fmt.Errorf("appointment: can not parse '%s' - %s", a.LastDate, err.Error())
 	}
@@ -107,7 +120,7 @@ func FactoryAppointment(data []byte, id string, language string) (registry.Quest
 		w[day] = true
 	}
 
-	t := make([][]int, 0)
+	t := make([][]int, 0) // hour, minute, duration in minutes
 
 	test := make(map[string]bool)
 	for i := range a.Time {
@@ -116,30 +129,29 @@ func FactoryAppointment(data []byte, id string, language string) (registry.Quest
 				return nil, fmt.Errorf("appointment: time '%s' found twice", a.Time[i])
 			}
 			test["notime"] = true
-			t = append(t, []int{-1, -1})
+			t = append(t, []int{-1, -1, 0})
 			continue
 		}
 
-		tn := make([]int, 2)
-		split := strings.Split(a.Time[i], ":")
-		if len(split) != 2 {
+		tn := make([]int, 3)
+		split := strings.Split(a.Time[i], "-")
+		if len(split) > 2 {
 			return nil, fmt.Errorf("appointment: Can not parse '%s' as time", a.Time[i])
 		}
-		tn[0], err = strconv.Atoi(split[0])
-		if err != nil {
-			return nil, fmt.Errorf("appointment: Can not parse '%s' as time - %s", a.Time[i], err.Error())
-		}
-		tn[1], err = strconv.Atoi(split[1])
+		tn[0], tn[1], err = appointmentParseClock(split[0])
 		if err != nil {
 			return nil, fmt.Errorf("appointment: Can not parse '%s' as time - %s", a.Time[i], err.Error())
 		}
-
-		if tn[0] < 0 || tn[0] > 23 {
-			return nil, fmt.Errorf("appointment: Hour of time '%s' must be between 0 and 23", a.Time[i])
-		}
-
-		if tn[1] < 0 || tn[1] > 59 {
-			return nil, fmt.Errorf("appointment: Minute of time '%s' must be between 0 and 59", a.Time[i])
+		if len(split) == 2 {
+			// Time range
+			endHour, endMinute, err := appointmentParseClock(split[1])
+			if err != nil {
+				return nil, fmt.Errorf("appointment: Can not parse '%s' as time - %s", a.Time[i], err.Error())
+			}
+			tn[2] = (endHour*60 + endMinute) - (tn[0]*60 + tn[1])
+			if tn[2] <= 0 {
+				return nil, fmt.Errorf("appointment: End of time range '%s' must be after start", a.Time[i])
+			}
 		}
 
 		// Ensure time format is identical
@@ -163,6 +175,16 @@ func FactoryAppointment(data []byte, id string, language string) (registry.Quest
 		}
 	}
 
+	if a.Capacity < 0 {
+		return nil, fmt.Errorf("appointment: Capacity must not be negative")
+	}
+	for k := range a.SlotCapacity {
+		if a.SlotCapacity[k] < 0 {
+			return nil, fmt.Errorf("appointment: SlotCapacity of '%s' must not be negative", k)
+		}
+	}
+	usedCapacity := make(map[string]bool)
+
 	a.dates = make([]appointmentDate, 0)
 	sort.Strings(a.Time)
 
@@ -186,25 +208,106 @@ func FactoryAppointment(data []byte, id string, language string) (registry.Quest
 				if t[i][0] == -1 {
 					// Special value "notime"
 					a.dates = append(a.dates, appointmentDate{
-						ID:      fmt.Sprintf("%s_%s_notime", id, newTime.Format(appointmentDateFormatIDNoTime)),
-						Display: a.formatTimeDisplay(newTime, appointmentDateFormatWriteNoTime),
-						time:    newTime,
+						ID:       fmt.Sprintf("%s_%s_notime", id, newTime.Format(appointmentDateFormatIDNoTime)),
+						Display:  a.formatTimeDisplay(newTime, appointmentDateFormatWriteNoTime),
+						time:     newTime,
+						notime:   true,
+						capacity: a.slotCapacity(newTime.Format(appointmentDateFormatCapacityNoTime), usedCapacity),
 					})
 				} else {
-					a.dates = append(a.dates, appointmentDate{
-						ID:      fmt.Sprintf("%s_%s", id, newTime.Format(appointmentDateFormatID)),
-						Display: a.formatTimeDisplay(newTime, appointmentDateFormatWrite),
-						time:    newTime,
-					})
+					date := appointmentDate{
+						ID:       fmt.Sprintf("%s_%s", id, newTime.Format(appointmentDateFormatID)),
+						Display:  a.formatTimeDisplay(newTime, appointmentDateFormatWrite),
+						time:     newTime,
+						capacity: a.slotCapacity(newTime.Format(appointmentDateFormatCapacity), usedCapacity),
+					}
+					if t[i][2] > 0 {
+						date.end = newTime.Add(time.Duration(t[i][2]) * time.Minute)
+						date.Display = fmt.Sprintf("%s-%s", date.Display, date.end.Format("15:04"))
+					}
+					if a.TimeZone != "" {
+						date.Display = fmt.Sprintf("%s (%s)", date.Display, a.TimeZone)
+						date.Start = newTime.Format(time.RFC3339)
+						if !date.end.IsZero() {
+							date.End = date.end.Format(time.RFC3339)
+						}
+					}
+					a.dates = append(a.dates, date)
 				}
 			}
 		}
 		fd = fd.AddDate(0, 0, 1)
 	}
 
+	for k := range a.SlotCapacity {
+		if !usedCapacity[k] {
+			return nil, fmt.Errorf("appointment: SlotCapacity '%s' does not match any date", k)
+		}
+	}
+
 	return &a, nil
 }
 
+// appointmentParseClock parses a time in the format 15:04.
+func appointmentParseClock(s string) (int, int, error) {
+	split := strings.Split(strings.TrimSpace(s), ":")
+	if len(split) != 2 {
+		return 0, 0, fmt.Errorf("can not parse '%s' as time", s)
+	}
+	hour, err := strconv.Atoi(split[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	minute, err := strconv.Atoi(split[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	if hour < 0 || hour > 23 {
+		return 0, 0, fmt.Errorf("hour of time '%s' must be between 0 and 23", s)
+	}
+	if minute < 0 || minute > 59 {
+		return 0, 0, fmt.Errorf("minute of time '%s' must be between 0 and 59", s)
+	}
+	return hour, minute, nil
+}
+
+// appointmentICSEscape escapes text for iCalendar files (RFC 5545).
+func appointmentICSEscape(s string) string {
+	r := strings.NewReplacer("\\", "\\\\", ";", "\\;", ",", "\\,", "\r\n", "\\n", "\n", "\\n", "\r", "")
+	return r.Replace(s)
+}
+
+// ics returns an iCalendar file containing date as an event.
+func (a appointment) ics(date appointmentDate, summary string) string {
+	const icsTime = "20060102T150405Z"
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//QuestionGo!//appointment//EN",
+		"BEGIN:VEVENT",
+		fmt.Sprintf("UID:%s@questiongo", strings.ReplaceAll(date.ID, ":", "")),
+		fmt.Sprintf("DTSTAMP:%s", time.Now().UTC().Format(icsTime)),
+	}
+	if date.notime {
+		day := time.Date(date.time.Year(), date.time.Month(), date.time.Day(), 0, 0, 0, 0, time.UTC)
+		lines = append(lines, fmt.Sprintf("DTSTART;VALUE=DATE:%s", day.Format("20060102")), fmt.Sprintf("DTEND;VALUE=DATE:%s", day.AddDate(0, 0, 1).Format("20060102")))
+	} else {
+		// Without a time zone, dates are floating (local time of the participant)
+		format := func(t time.Time) string {
+			if a.TimeZone == "" {
+				return t.Format("20060102T150405")
+			}
+			return t.UTC().Format(icsTime)
+		}
+		lines = append(lines, fmt.Sprintf("DTSTART:%s", format(date.time)))
+		if !date.end.IsZero() {
+			lines = append(lines, fmt.Sprintf("DTEND:%s", format(date.end)))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("SUMMARY:%s", appointmentICSEscape(summary)), "END:VEVENT", "END:VCALENDAR", "")
+	return strings.Join(lines, "\r\n")
+}
+
 var appointmentDayMap = map[string]time.Weekday{
 	"mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday, "sun": time.Sunday,
 	"mo": time.Monday, "tu": time.Tuesday, "we": time.Wednesday, "th": time.Thursday, "fr": time.Friday, "sa": time.Saturday, "su": time.Sunday,
@@ -246,14 +349,48 @@ func appointmentPoints(answer string) float64 {
 }
 
 var appointmentTemplate = template.Must(template.New("appointmentTemplate").Parse(`{{.Text}}
+{{if .ShowResults}}
+<details open>
+<summary>{{.Translation.AppointmentCurrentVotes}} ({{len .Votes}})</summary>
+<div style="width: 100%; overflow-x: auto;">
+<table>
+<thead>
+<tr>
+<th></th>
+{{range $i, $e := .Data }}
+<th>{{$e.Display}}</th>
+{{end}}
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Votes }}
+<tr>
+<td style="white-space:nowrap;"><strong>{{$e.Name}}</strong></td>
+{{range $I, $E := $e.Answers }}
+<td class="centre" title="{{$e.Name}} - {{(index $.Data $I).Display}}" {{if index $E 0}}bgcolor="{{index $E 0}}"{{end}}>{{index $E 1}}</td>
+{{end}}
+</tr>
+{{end}}
+<tr>
+<td class="th-cell" style="white-space:nowrap;"><strong>✓</strong></td>
+{{range $i, $e := .Yes }}
+<td class="centre th-cell">{{$e}}</td>
+{{end}}
+</tr>
+</tbody>
+</table>
+</div>
+</details>
+<br>
+{{end}}
 <table style="border: none;">
 <tr style="border: none; background-color: inherit;">
 <td style="border: none;"><label for="{{.ID}}_name">{{.Translation.AppointmentName}} {{if .NameRequired}}<em>({{.Translation.AppointmentRequired}})</em>{{else}}<em>({{.Translation.AppointmentOptional}})</em>{{end}}:</label></td>
-<td style="border: none;"><input type="text" id="{{.ID}}_name" name="{{.ID}}_name" placeholder="{{.Translation.AppointmentName}}" maxlength="150" {{if .NameRequired}}required{{end}}></td>
+<td style="border: none;"><input type="text" id="{{.ID}}_name" name="{{.ID}}_name" placeholder="{{.Translation.AppointmentName}}" maxlength="150" {{if .Name}}value="{{.Name}}"{{end}} {{if .NameRequired}}required{{end}}></td>
 </tr>
 <tr style="border: none; background-color: inherit;">
 <td style="border: none;"><label for="{{.ID}}_comment">{{.Translation.AppointmentComment}} <em>({{.Translation.AppointmentOptional}})</em>:</label></td>
-<td style="border: none;"><input type="text" id="{{.ID}}_comment" name="{{.ID}}_comment" placeholder="{{.Translation.AppointmentComment}}" maxlength="500"></td>
+<td style="border: none;"><input type="text" id="{{.ID}}_comment" name="{{.ID}}_comment" placeholder="{{.Translation.AppointmentComment}}" maxlength="500" {{if .Comment}}value="{{.Comment}}"{{end}}></td>
 </tr>
 </table>
 <br>
@@ -262,11 +399,14 @@ var appointmentTemplate = template.Must(template.New("appointmentTemplate").Pars
 <tr>
 <th></th>
 <th>✓ ({{.Translation.AppointmentYes}})</th>
+{{if not .SignUpSheet}}
 <th>👎 ({{.Translation.AppointmentOnlyIfNeeded}})</th>
 <th>X ({{.Translation.AppointmentNo}})</th>
 <th>? ({{.Translation.AppointmentCanNotSay}})</th>
+{{end}}
 </tr>
 </thead>
+{{if not .SignUpSheet}}
 <tr>
 <td></td>
 <td class="centre" bgcolor="#709C34"><button form="detach from form" onclick="e=document.getElementById('{{.ID}}_tbody');l=e.getElementsByTagName('input');for(var i=0;i<l.length;i++){if(l[i].type==='radio'&&l[i].value==='✓'&&!l[i].disabled){l[i].checked=true}}">{{.Translation.AppointmentAll}} ✓</button></td>
@@ -274,22 +414,48 @@ var appointmentTemplate = template.Must(template.New("appointmentTemplate").Pars
 <td class="centre" bgcolor="#6C1239"><button form="detach from form" onclick="e=document.getElementById('{{.ID}}_tbody');l=e.getElementsByTagName('input');for(var i=0;i<l.length;i++){if(l[i].type==='radio'&&l[i].value==='X'&&!l[i].disabled){l[i].checked=true}}">{{.Translation.AppointmentAll}} X</button></td>
 <td class="centre" bgcolor="#F7F7F7"><button form="detach from form" onclick="e=document.getElementById('{{.ID}}_tbody');l=e.getElementsByTagName('input');for(var i=0;i<l.length;i++){if(l[i].type==='radio'&&l[i].value==='?'&&!l[i].disabled){l[i].checked=true}}">{{.Translation.AppointmentAll}} ?</button></td>
 </tr>
+{{end}}
 <tbody id="{{.ID}}_tbody">
 {{range $i, $e := .Data }}
 <tr>
-<td class="noselect">{{if $e.Disabled}}<s>{{else}}<strong>{{end}}{{$e.Display}}{{if $e.Disabled}}</s>{{else}}</strong>{{end}}</td>
-<td class="centre" bgcolor="#709C34" title="{{$e.Display}} - ✓" onclick="e=document.getElementById('{{$e.ID}}_✓');if(!e.disabled){e.checked=true;}" onmouseenter="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_✓');if(!e.disabled){e.checked=true;}}" onmousedown="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_✓');if(!e.disabled){e.checked=true;}}"><input title="{{$e.Display}} - ✓" type="radio" id="{{$e.ID}}_✓" name="{{$e.ID}}" value="✓" {{if $e.Disabled}} disabled {{end}}></td>
-<td class="centre" bgcolor="#9A9A9A" title="{{$e.Display}} - 👎" onclick="e=document.getElementById('{{$e.ID}}_👎');if(!e.disabled){e.checked=true;}" onmouseenter="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_👎');if(!e.disabled){e.checked=true;}}" onmousedown="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_👎');if(!e.disabled){e.checked=true;}}"><input title="{{$e.Display}} - 👎" type="radio" id="{{$e.ID}}_👎" name="{{$e.ID}}" value="👎" {{if $e.Disabled}} disabled {{end}}></td>
-<td class="centre" bgcolor="#6C1239" title="{{$e.Display}} - X" onclick="e=document.getElementById('{{$e.ID}}_X');if(!e.disabled){e.checked=true;}" onmouseenter="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_X');if(!e.disabled){e.checked=true;}}" onmousedown="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_X');if(!e.disabled){e.checked=true;}}"><input title="{{$e.Display}} - X" type="radio" id="{{$e.ID}}_X" name="{{$e.ID}}" value="X" {{if $e.Disabled}} disabled {{end}}></td>
-<td class="centre" bgcolor="#F7F7F7" title="{{$e.Display}} - ?" onclick="e=document.getElementById('{{$e.ID}}_?');if(!e.disabled){e.checked=true;}" onmouseenter="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_?');if(!e.disabled){e.checked=true;}}" onmousedown="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_?');if(!e.disabled){e.checked=true;}}"><input title="{{$e.Display}} - ?" type="radio" id="{{$e.ID}}_?" name="{{$e.ID}}" value="?" {{if $e.Disabled}} disabled {{end}}></td>
+<td class="noselect">{{if $e.Disabled}}<s>{{else}}<strong>{{end}}<span {{if $e.Start}}data-appointment-start="{{$e.Start}}" data-appointment-end="{{$e.End}}" title="{{$e.Display}}"{{end}}>{{$e.Display}}</span>{{if $e.Disabled}}</s>{{else}}</strong>{{end}}{{if $e.Capacity}} <small>({{printf $.Translation.AppointmentSeatsFree $e.Free $e.Capacity}})</small>{{end}}</td>
+{{if $.SignUpSheet}}
+<td class="centre" bgcolor="#709C34" title="{{$e.Display}} - ✓"><input title="{{$e.Display}} - ✓" type="checkbox" id="{{$e.ID}}_✓" name="{{$e.ID}}" value="✓" {{if eq $e.Answer "✓"}} checked {{end}}{{if $e.Disabled}} disabled {{end}}></td>
+{{else}}
+<td class="centre" bgcolor="#709C34" title="{{$e.Display}} - ✓" onclick="e=document.getElementById('{{$e.ID}}_✓');if(!e.disabled){e.checked=true;}" onmouseenter="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_✓');if(!e.disabled){e.checked=true;}}" onmousedown="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_✓');if(!e.disabled){e.checked=true;}}"><input title="{{$e.Display}} - ✓" type="radio" id="{{$e.ID}}_✓" name="{{$e.ID}}" value="✓" {{if eq $e.Answer "✓"}} checked {{end}}{{if $e.Disabled}} disabled {{end}}></td>
+<td class="centre" bgcolor="#9A9A9A" title="{{$e.Display}} - 👎" onclick="e=document.getElementById('{{$e.ID}}_👎');if(!e.disabled){e.checked=true;}" onmouseenter="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_👎');if(!e.disabled){e.checked=true;}}" onmousedown="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_👎');if(!e.disabled){e.checked=true;}}"><input title="{{$e.Display}} - 👎" type="radio" id="{{$e.ID}}_👎" name="{{$e.ID}}" value="👎" {{if eq $e.Answer "👎"}} checked {{end}}{{if $e.Disabled}} disabled {{end}}></td>
+<td class="centre" bgcolor="#6C1239" title="{{$e.Display}} - X" onclick="e=document.getElementById('{{$e.ID}}_X');if(!e.disabled){e.checked=true;}" onmouseenter="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_X');if(!e.disabled){e.checked=true;}}" onmousedown="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_X');if(!e.disabled){e.checked=true;}}"><input title="{{$e.Display}} - X" type="radio" id="{{$e.ID}}_X" name="{{$e.ID}}" value="X" {{if eq $e.Answer "X"}} checked {{end}}{{if $e.Disabled}} disabled {{end}}></td>
+<td class="centre" bgcolor="#F7F7F7" title="{{$e.Display}} - ?" onclick="e=document.getElementById('{{$e.ID}}_?');if(!e.disabled){e.checked=true;}" onmouseenter="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_?');if(!e.disabled){e.checked=true;}}" onmousedown="if(event.buttons&1 != 0){e=document.getElementById('{{$e.ID}}_?');if(!e.disabled){e.checked=true;}}"><input title="{{$e.Display}} - ?" type="radio" id="{{$e.ID}}_?" name="{{$e.ID}}" value="?" {{if eq $e.Answer "?"}} checked {{end}}{{if $e.Disabled}} disabled {{end}}></td>
+{{end}}
 </tr>
 {{end}}
 </tbody>
 </table>
+{{if .TimeZone}}
+<p><small>{{.Translation.AppointmentLocalTime}}</small></p>
+<script>
+{
+  let dates = document.getElementById({{.ID}} + '_tbody').querySelectorAll('span[data-appointment-start]');
+  let format = {weekday: 'long', year: 'numeric', month: '2-digit', day: '2-digit', hour: '2-digit', minute: '2-digit'};
+  for(let i = 0; i < dates.length; i++) {
+    let start = new Date(dates[i].dataset.appointmentStart);
+    let text = start.toLocaleString(undefined, format);
+    if(dates[i].dataset.appointmentEnd) {
+      let end = new Date(dates[i].dataset.appointmentEnd);
+      text += ' - ' + end.toLocaleTimeString(undefined, {hour: '2-digit', minute: '2-digit'});
+    }
+    dates[i].textContent = text;
+  }
+}
+</script>
+{{end}}
 `))
 
 var appointmentStatisticsTemplate = template.Must(template.New("appointmentStatisticsTemplate").Parse(`{{.Text}}
-<p><strong>Best Date:</strong> {{.Best}}</p>
+{{if .Suppressed}}
+<p>[suppressed]</p>
+{{else}}
+<p><strong>Best Date:</strong> {{.Best}}{{if .ICS}} (<a href="{{.ICS}}" download="{{.ICSName}}">.ics</a>){{end}}</p>
 <details>
 <summary>detailed results</summary>
 <div style="width: 100%; overflow-x: auto;">
@@ -317,17 +483,33 @@ var appointmentStatisticsTemplate = template.Must(template.New("appointmentStati
 <td class="centre{{if eq $i $.BestNumber}} th-cell{{end}}" title="{{index $.Dates $i}} - {{printf "%.2f" $e}}">{{printf "%.2f" $e}}</td>
 {{end}}
 </tr>
+{{if .Seats}}
+<tr>
+<td class="th-cell" style="white-space:nowrap;"><strong>Seats taken</strong></td>
+{{range $i, $e := .Seats }}
+<td class="centre" title="{{index $.Dates $i}} - {{$e}}">{{$e}}</td>
+{{end}}
+</tr>
+{{end}}
 </tbody>
 </table>
 </div>
 </details>
+{{end}}
 `))
 
 type appointmentTemplateStruct struct {
 	ID           string
 	Text         template.HTML
 	NameRequired bool
+	Name         string
+	Comment      string
+	SignUpSheet  bool
+	TimeZone     string
 	Data         []appointmentDate
+	ShowResults  bool
+	Votes        []appointmentStatisticsTemplateStructInner
+	Yes          []int
 	Translation  translation.Translation
 }
 
@@ -337,7 +519,11 @@ type appointmentStatisticsTemplateStruct struct {
 	Dates      []string
 	Data       []appointmentStatisticsTemplateStructInner
 	Points     []float64
+	Seats      []string
 	BestNumber int
+	ICS        template.URL
+	ICSName    string
+	Suppressed bool
 }
 
 type appointmentStatisticsTemplateStructInner struct {
@@ -350,7 +536,15 @@ type appointmentDate struct {
 	ID       string
 	Display  string
 	Disabled bool
+	Capacity int
+	Free     int
+	Start    string // RFC 3339, only set if a time zone is configured
+	End      string // RFC 3339, only set if a time zone is configured and the date has a duration
+	Answer   string // Previous answer when editing
 	time     time.Time
+	end      time.Time // zero if the date has no duration
+	notime   bool
+	capacity int // 0 means unlimited
 }
 
 type appointment struct {
@@ -363,12 +557,88 @@ type appointment struct {
 	Days                []string
 	Time                []string
 	ExceptDays          []string
+	Capacity            int            // Maximum number of ✓ per date, 0 means unlimited
+	SlotCapacity        map[string]int // Capacity of single dates (2006-01-02T15:04, or 2006-01-02 for notime), overrides Capacity
+	SignUpSheet         bool           // Only allow ✓
+	TimeZone            string         // IANA time zone of the dates, e.g. Europe/Berlin. Participants see the dates in their local time zone.
+	AllowEdit           bool           // Participants get a personal link to change their answer
+	ShowResults         bool           // Show the current votes to participants (without comments)
+	HideNames           bool           // Do not show the names of other participants when ShowResults is set
 
 	id          string
 	dates       []appointmentDate
+	hasCapacity bool
+	dataSource  func(exclude string) ([]string, error)
 	Translation translation.Translation
 }
 
+func (a *appointment) slotCapacity(key string, used map[string]bool) int {
+	c, ok := a.SlotCapacity[key]
+	if ok {
+		used[key] = true
+	} else {
+		c = a.Capacity
+	}
+	if c > 0 {
+		a.hasCapacity = true
+	}
+	return c
+}
+
+// SetDataSource is needed to check the capacity against stored answers.
+func (a *appointment) SetDataSource(get func(exclude string) ([]string, error)) {
+	a.dataSource = get
+}
+
+// SerialiseSave returns true if slots have a capacity, since ValidateInput checks it against the stored answers.
+func (a appointment) SerialiseSave() bool {
+	return a.hasCapacity
+}
+
+// taken returns the number of ✓ for each date.
+func (a appointment) taken(data []string) []int {
+	result := make([]int, len(a.dates))
+	for d := range data {
+		var results map[string]string
+		err := json.Unmarshal([]byte(data[d]), &results)
+		if err != nil {
+			continue
+		}
+		for i := range a.dates {
+			if results[a.dates[i].ID] == "✓" {
+				result[i]++
+			}
+		}
+	}
+	return result
+}
+
+// votes returns the stored answers as shown to participants. Comments are never included.
+func (a appointment) votes(data []string) []appointmentStatisticsTemplateStructInner {
+	result := make([]appointmentStatisticsTemplateStructInner, 0, len(data))
+	for d := range data {
+		var results map[string]string
+		err := json.Unmarshal([]byte(data[d]), &results)
+		if err != nil {
+			continue
+		}
+
+		inner := appointmentStatisticsTemplateStructInner{
+			Name:    results[fmt.Sprintf("%s_name", a.id)],
+			Answers: make([][]string, len(a.dates)),
+		}
+		if a.HideNames || inner.Name == "" {
+			inner.Name = fmt.Sprintf(a.Translation.AppointmentParticipant, len(result)+1)
+		}
+		for i := range a.dates {
+			s := results[a.dates[i].ID]
+			inner.Answers[i] = []string{appointmentColour(s), s}
+		}
+		result = append(result, inner)
+	}
+	return result
+}
+
 func (a appointment) formatTimeDisplay(t time.Time, format string) string {
 	var weekday string
 	switch t.Weekday() {
@@ -395,22 +665,79 @@ func (a appointment) GetID() string {
 }
 
 func (a appointment) GetHTML() template.HTML {
+	return a.html(nil)
+}
+
+// Editable returns whether participants can change their answer.
+func (a appointment) Editable() bool {
+	return a.AllowEdit
+}
+
+// GetEditHTML returns the question prefilled with a stored answer.
+func (a appointment) GetEditHTML(data string) template.HTML {
+	var results map[string]string
+	err := json.Unmarshal([]byte(data), &results)
+	if err != nil {
+		log.Printf("appointment: Error unmarshalling %s - %s", data, err.Error())
+	}
+	if results == nil {
+		results = make(map[string]string)
+	}
+	return a.html(results)
+}
+
+// html returns the question. previous holds the answer which is edited and is nil otherwise.
+func (a appointment) html(previous map[string]string) template.HTML {
 	f, _ := registry.GetFormatType(a.Format)
 
 	td := appointmentTemplateStruct{
 		ID:           a.id,
 		Text:         f.Format([]byte(a.Text)),
 		NameRequired: a.NameRequired,
+		Name:         previous[fmt.Sprintf("%s_name", a.id)],
+		Comment:      previous[fmt.Sprintf("%s_comment", a.id)],
+		SignUpSheet:  a.SignUpSheet,
+		TimeZone:     a.TimeZone,
 		Data:         make([]appointmentDate, len(a.dates)),
 		Translation:  a.Translation,
 	}
 
 	now := time.Now()
 
+	taken := make([]int, len(a.dates))
+	if (a.hasCapacity || a.ShowResults) && a.dataSource != nil {
+		data, err := a.dataSource("")
+		if err != nil {
+			log.Printf("appointment: Can not get stored answers (%s): %s", a.id, err.Error())
+		} else {
+			taken = a.taken(data)
+			if a.ShowResults {
+				td.ShowResults = true
+				td.Votes = a.votes(data)
+				td.Yes = taken
+			}
+		}
+	}
+
 	for i := range a.dates {
 		td.Data[i].ID = a.dates[i].ID
 		td.Data[i].Display = a.dates[i].Display
+		td.Data[i].Start = a.dates[i].Start
+		td.Data[i].End = a.dates[i].End
+		td.Data[i].Answer = previous[a.dates[i].ID]
 		td.Data[i].Disabled = a.DisallowVotesInPast && a.dates[i].time.Before(now)
+		if a.dates[i].capacity > 0 {
+			td.Data[i].Capacity = a.dates[i].capacity
+			td.Data[i].Free = a.dates[i].capacity - taken[i]
+			if td.Data[i].Answer == "✓" {
+				// The seat of the edited answer is still available to the participant
+				td.Data[i].Free++
+			}
+			if td.Data[i].Free <= 0 {
+				td.Data[i].Free = 0
+				td.Data[i].Disabled = true
+			}
+		}
 	}
 
 	output := bytes.NewBuffer(make([]byte, 0))
@@ -453,6 +780,12 @@ func (a appointment) GetStatistics(data []string) [][]string {
 }
 
 func (a appointment) GetStatisticsDisplay(data []string) template.HTML {
+	return a.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results. Since the results list the names of the participants, they are hidden like free text answers if there are too few participants.
+// Weights are not used, since the votes of all participants count the same when choosing a date.
+func (a appointment) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
 	f, _ := registry.GetFormatType(a.Format)
 
 	td := appointmentStatisticsTemplateStruct{
@@ -493,6 +826,23 @@ func (a appointment) GetStatisticsDisplay(data []string) template.HTML {
 		td.Data = append(td.Data, inner)
 	}
 
+	td.Suppressed = options.Suppressed(len(td.Data))
+	if td.Suppressed {
+		td.Data = nil
+	}
+
+	if a.hasCapacity && !td.Suppressed {
+		taken := a.taken(data)
+		td.Seats = make([]string, len(a.dates))
+		for i := range a.dates {
+			if a.dates[i].capacity > 0 {
+				td.Seats[i] = fmt.Sprintf("%d/%d", taken[i], a.dates[i].capacity)
+			} else {
+				td.Seats[i] = strconv.Itoa(taken[i])
+			}
+		}
+	}
+
 	bestPoints := math.Inf(-1)
 
 	for i := range td.Points {
@@ -503,6 +853,12 @@ func (a appointment) GetStatisticsDisplay(data []string) template.HTML {
 		}
 	}
 
+	if len(a.dates) > 0 && len(td.Data) > 0 {
+		summary := strings.TrimSpace(html.UnescapeString(string(helper.SanitiseStringClean(string(f.FormatClean([]byte(a.Text)))))))
+		td.ICS = template.URL("data:text/calendar;charset=utf-8," + url.PathEscape(a.ics(a.dates[td.BestNumber], summary)))
+		td.ICSName = fmt.Sprintf("%s.ics", a.id)
+	}
+
 	output := bytes.NewBuffer(make([]byte, 0))
 	err := appointmentStatisticsTemplate.Execute(output, td)
 	if err != nil {
@@ -512,30 +868,61 @@ func (a appointment) GetStatisticsDisplay(data []string) template.HTML {
 }
 
 func (a appointment) ValidateInput(data map[string][]string) error {
-	if !a.NameRequired {
-		return nil
-	}
-	if len(data[fmt.Sprintf("%s_name", a.id)]) == 0 {
-		return fmt.Errorf("appointment: No name found")
-	}
-	if len(data[fmt.Sprintf("%s_name", a.id)][0]) == 0 {
-		return fmt.Errorf("appointment: Name has zero length")
+	return a.validate(data, "")
+}
+
+// ValidateUpdate validates a changed answer. The seats of the previous answer are available again.
+func (a appointment) ValidateUpdate(data map[string][]string, responseID string) error {
+	return a.validate(data, responseID)
+}
+
+// validate validates an answer. The stored answer of the response exclude is not counted against the capacity.
+func (a appointment) validate(data map[string][]string, exclude string) error {
+	if a.NameRequired {
+		if len(data[fmt.Sprintf("%s_name", a.id)]) == 0 {
+			return fmt.Errorf("appointment: No name found")
+		}
+		if len(data[fmt.Sprintf("%s_name", a.id)][0]) == 0 {
+			return fmt.Errorf("appointment: Name has zero length")
+		}
 	}
 
 	now := time.Now()
+	needCapacity := false
 	for i := range a.dates {
 		if len(data[a.dates[i].ID]) != 0 {
 			if a.DisallowVotesInPast && a.dates[i].time.Before(now) {
 				return fmt.Errorf("appointment: answer '%s' is in past (currently: %s)", a.dates[i].ID, now.Format(appointmentDateFormatWrite))
 			}
 			switch data[a.dates[i].ID][0] {
-			case "✓", "👎", "X", "?":
-				// Valid answer
+			case "✓":
+				needCapacity = needCapacity || a.dates[i].capacity > 0
+			case "👎", "X", "?":
+				if a.SignUpSheet {
+					return fmt.Errorf("appointment: Answer '%s' not allowed in sign-up sheet", data[a.dates[i].ID][0])
+				}
 			default:
 				return fmt.Errorf("appointment: Unknown answer '%s'", data[a.dates[i].ID][0])
 			}
 		}
 	}
+
+	if needCapacity {
+		// Saving is serialised for questions with a data source, so the stored answers can not change until the answer is saved
+		if a.dataSource == nil {
+			return fmt.Errorf("appointment: Can not check capacity without data source")
+		}
+		stored, err := a.dataSource(exclude)
+		if err != nil {
+			return fmt.Errorf("appointment: Can not get stored answers: %w", err)
+		}
+		taken := a.taken(stored)
+		for i := range a.dates {
+			if a.dates[i].capacity > 0 && len(data[a.dates[i].ID]) != 0 && data[a.dates[i].ID][0] == "✓" && taken[i] >= a.dates[i].capacity {
+				return fmt.Errorf("appointment: Date '%s' is full", a.dates[i].ID)
+			}
+		}
+	}
 	return nil
 }
 
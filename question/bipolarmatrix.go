@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2025 Marcus Soll
+// Copyright 2020,2025,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -103,13 +103,15 @@ var bipolarmatrixStatisticsTemplate = template.Must(template.New("matrixStatisti
 <tr>
 <td><strong>{{$e.Low}}</strong></td>
 {{range $I, $E := $e.Result }}
-<td>{{printf "%.2f" $E}}</td>
+<td>{{if index $e.Suppressed $I}}[suppressed]{{else}}{{printf "%.2f" $E}}{{end}}</td>
 {{end}}
 <td><strong>{{$e.High}}</strong></td>
 </tr>
 {{end}}
 </tbody>
 </table>
+{{.Descriptive}}
+{{.BoxPlot}}
 {{.Image}}
 `))
 
@@ -128,16 +130,19 @@ type bipolarmatrixTemplateStruct struct {
 }
 
 type bipolarmatrixStatisticsTemplateStructInner struct {
-	Low    template.HTML
-	High   template.HTML
-	Result []float64
+	Low        template.HTML
+	High       template.HTML
+	Result     []float64
+	Suppressed []bool
 }
 
 type bipolarmatrixStatisticTemplateStruct struct {
-	Title  template.HTML
-	Header []template.HTML
-	Data   []bipolarmatrixStatisticsTemplateStructInner
-	Image  template.HTML
+	Title       template.HTML
+	Header      []template.HTML
+	Data        []bipolarmatrixStatisticsTemplateStructInner
+	Descriptive template.HTML
+	BoxPlot     template.HTML
+	Image       template.HTML
 }
 
 type bipolarmatrix struct {
@@ -227,11 +232,21 @@ func (m bipolarmatrix) GetStatistics(data []string) [][]string {
 }
 
 func (m bipolarmatrix) GetStatisticsDisplay(data []string) template.HTML {
-	count := 0
-	countAnswer := make([][]int, len(m.Questions))
+	return m.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted percentages and statistics if the options contain weights. Small counts are suppressed.
+func (m bipolarmatrix) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	count := 0.0
+	countAnswer := make([][]float64, len(m.Questions))
+	number := make([][]int, len(m.Questions))
 	for i := range m.Questions {
-		countAnswer[i] = make([]int, len(m.AnswerIDs)+1)
+		countAnswer[i] = make([]float64, len(m.AnswerIDs)+1)
+		number[i] = make([]int, len(m.AnswerIDs)+1)
 	}
+	numeric, isNumeric := numericAnswerIDs(m.AnswerIDs)
+	values := make([][]float64, len(m.Questions))
+	weights := make([][]float64, len(m.Questions))
 
 	for d := range data {
 		rarray := make([]string, len(m.Questions))
@@ -242,19 +257,27 @@ func (m bipolarmatrix) GetStatisticsDisplay(data []string) template.HTML {
 		if len(rarray) != len(m.Questions) {
 			continue
 		}
-		count++
+		count += options.Weight(d)
 
 		for i := range m.Questions {
 			found := false
 			for j := range m.AnswerIDs {
 				if rarray[i] == m.AnswerIDs[j] {
-					countAnswer[i][j]++
+					countAnswer[i][j] += options.Weight(d)
+					number[i][j]++
+					if isNumeric {
+						values[i] = append(values[i], numeric[j])
+						if options.Weighted() {
+							weights[i] = append(weights[i], options.Weight(d))
+						}
+					}
 					found = true
 					break
 				}
 			}
 			if !found {
-				countAnswer[i][len(m.AnswerIDs)]++
+				countAnswer[i][len(m.AnswerIDs)] += options.Weight(d)
+				number[i][len(m.AnswerIDs)]++
 			}
 		}
 	}
@@ -273,28 +296,54 @@ func (m bipolarmatrix) GetStatisticsDisplay(data []string) template.HTML {
 		labelValues[i] = string(td.Header[i])
 	}
 	td.Header[len(m.AnswerIDs)] = "[no answer]"
-	v := make([][]int, 0, len(m.Questions))
+	v := make([][]float64, 0, len(m.Questions))
 
 	for i := range m.Questions {
-		vinner := make([]int, len(m.AnswerIDs))
+		vinner := make([]float64, len(m.AnswerIDs))
 		low := f.FormatClean([]byte(m.Questions[i][1]))
 		high := f.FormatClean([]byte(m.Questions[i][2]))
 		inner := bipolarmatrixStatisticsTemplateStructInner{
-			Low:    low,
-			High:   high,
-			Result: make([]float64, len(m.AnswerIDs)+1),
+			Low:        low,
+			High:       high,
+			Result:     make([]float64, len(m.AnswerIDs)+1),
+			Suppressed: make([]bool, len(m.AnswerIDs)+1),
 		}
 		labelBars[i] = string(fmt.Sprintf("%s - %s", string(low), string(high)))
 		for j := range m.AnswerIDs {
-			inner.Result[j] = float64(countAnswer[i][j]) / float64(count)
-			vinner[j] = countAnswer[i][j]
+			inner.Result[j] = countAnswer[i][j] / count
+			inner.Suppressed[j] = options.Suppressed(number[i][j])
+			if !inner.Suppressed[j] {
+				vinner[j] = countAnswer[i][j]
+			}
 		}
-		inner.Result[len(m.AnswerIDs)] = float64(countAnswer[i][len(m.AnswerIDs)]) / float64(count)
+		inner.Result[len(m.AnswerIDs)] = countAnswer[i][len(m.AnswerIDs)] / count
+		inner.Suppressed[len(m.AnswerIDs)] = options.Suppressed(number[i][len(m.AnswerIDs)])
 
 		td.Data = append(td.Data, inner)
 		v = append(v, vinner)
 	}
 	td.Image = helper.Stacked100Chart(v, fmt.Sprintf("%s_bar", m.id), labelBars, labelValues, "")
+	if isNumeric {
+		labels := make([]string, len(m.Questions))
+		d := make([]helper.Descriptive, len(m.Questions))
+		for i := range m.Questions {
+			labels[i] = helper.StripTags(labelBars[i])
+			d[i] = helper.WeightedDescriptiveStatistics(values[i], weights[i])
+			if options.Suppressed(d[i].N) {
+				d[i] = helper.Descriptive{Suppressed: true}
+				continue
+			}
+			// Extremes and quartiles might be the value of a suppressed count
+			for _, s := range td.Data[i].Suppressed[:len(m.AnswerIDs)] {
+				if s {
+					d[i].ExtremesSuppressed = true
+					break
+				}
+			}
+		}
+		td.Descriptive = helper.DescriptiveTable(labels, d)
+		td.BoxPlot = helper.BoxPlot(labels, d)
+	}
 	output := bytes.NewBuffer(make([]byte, 0))
 	err := bipolarmatrixStatisticsTemplate.Execute(output, td)
 	if err != nil {
@@ -0,0 +1,355 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package question
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"math/rand"
+	"strings"
+
+	"github.com/Top-Ranger/questiongo/registry"
+)
+
+func init() {
+	err := registry.RegisterQuestionType(FactoryCheckboxMatrix, "checkbox matrix")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// FactoryCheckboxMatrix is the factory for checkbox matrix questions.
+func FactoryCheckboxMatrix(data []byte, id string, language string) (registry.Question, error) {
+	var m checkboxMatrix
+	err := json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, err
+	}
+	m.id = id
+
+	// Sanity checks
+	testID := make(map[string]bool)
+	for i := range m.Answers {
+		if len(m.Answers[i]) != 2 {
+			return nil, fmt.Errorf("checkboxmatrix: Answer %d must have exactly 2 values (id, text) (%s)", i, id)
+		}
+		if testID[m.Answers[i][0]] {
+			return nil, fmt.Errorf("checkboxmatrix: ID %s found twice (%s)", m.Answers[i][0], id)
+		}
+		if strings.Contains(m.Answers[i][0], "_") {
+			return nil, fmt.Errorf("checkboxmatrix: ID %s must not have '_' (%s)", m.Answers[i][0], id)
+		}
+		testID[m.Answers[i][0]] = true
+	}
+
+	testID = make(map[string]bool)
+	for i := range m.Questions {
+		if len(m.Questions[i]) != 2 {
+			return nil, fmt.Errorf("checkboxmatrix: Question %d must have exactly 2 values (id, text) (%s)", i, id)
+		}
+		if testID[m.Questions[i][0]] {
+			return nil, fmt.Errorf("checkboxmatrix: ID %s found twice (%s)", m.Questions[i][0], id)
+		}
+		if strings.Contains(m.Questions[i][0], "_") {
+			return nil, fmt.Errorf("checkboxmatrix: ID %s must not have '_' (%s)", m.Questions[i][0], id)
+		}
+		testID[m.Questions[i][0]] = true
+	}
+
+	_, ok := registry.GetFormatType(m.Format)
+	if !ok {
+		return nil, fmt.Errorf("checkboxmatrix: Unknown format type %s (%s)", m.Format, id)
+	}
+
+	return &m, nil
+}
+
+var checkboxMatrixTemplate = template.Must(template.New("checkboxMatrixTemplate").Parse(`{{.Title}}<br>
+<table>
+<thead>
+<tr>
+<th></th>
+{{range $i, $e := .Header }}
+<th class="centre">{{$e}}</th>
+{{end}}
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Data }}
+<tr>
+<td>{{$e.Question}}</td>
+{{range $I, $E := $.Answer }}
+<td class="centre" title="{{$e.Question}} - {{index $E 1}}" onclick="if(event.target===this){var e=document.getElementById('{{$.GID}}_{{$e.QID}}_{{index $E 0}}');e.checked=!e.checked;}"><input title="{{$e.Question}} - {{index $E 1}}" type="checkbox" id="{{$.GID}}_{{$e.QID}}_{{index $E 0}}" name="{{$.GID}}_{{$e.QID}}_{{index $E 0}}"></td>
+{{end}}
+</tr>
+{{end}}
+</tbody>
+</table>
+`))
+
+var checkboxMatrixStatisticsTemplate = template.Must(template.New("checkboxMatrixStatisticsTemplate").Parse(`{{.Title}}<br>
+<table>
+<thead>
+<tr>
+<th>Question</th>
+{{range $i, $e := .Header }}
+<th>{{$e}}</th>
+{{end}}
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Data }}
+<tr>
+<td>{{$e.Question}}</td>
+{{range $I, $E := $e.Result }}
+{{if $E.Suppressed}}
+<td class="centre">[suppressed]</td>
+{{else}}
+<td class="centre" style="background-color: rgba(175, 152, 255, {{printf "%.2f" $E.Percent}});" title="{{$E.Number}}">{{printf "%.2f" $E.Percent}}<br><small>({{$E.Number}})</small></td>
+{{end}}
+{{end}}
+</tr>
+{{end}}
+<tr>
+<td class="th-cell">[number answers]</td>
+<td>{{if .SumSuppressed}}[suppressed]{{else}}{{.Sum}}{{end}}</td>
+</tr>
+</tbody>
+</table>
+`))
+
+type checkboxMatrixTemplateStructInner struct {
+	Question template.HTML
+	QID      string
+}
+
+type checkboxMatrixTemplateStruct struct {
+	Title  template.HTML
+	Header []template.HTML
+	Data   []checkboxMatrixTemplateStructInner
+	Answer [][]string
+	GID    string
+}
+
+type checkboxMatrixStatisticsTemplateStructCell struct {
+	Number     int
+	Percent    float64
+	Suppressed bool
+}
+
+type checkboxMatrixStatisticsTemplateStructInner struct {
+	Question template.HTML
+	Result   []checkboxMatrixStatisticsTemplateStructCell
+}
+
+type checkboxMatrixStatisticsTemplateStruct struct {
+	Title         template.HTML
+	Header        []template.HTML
+	Data          []checkboxMatrixStatisticsTemplateStructInner
+	Sum           int
+	SumSuppressed bool
+}
+
+type checkboxMatrix struct {
+	Random    bool
+	Format    string
+	Title     string
+	Answers   [][]string
+	Questions [][]string
+
+	id string
+}
+
+func (m checkboxMatrix) parseResult(data string) ([][]bool, bool) {
+	if strings.HasPrefix(data, "ERROR") {
+		return nil, false
+	}
+	var result [][]bool
+	err := json.Unmarshal([]byte(data), &result)
+	if err != nil || len(result) != len(m.Questions) {
+		return nil, false
+	}
+	for i := range result {
+		if len(result[i]) != len(m.Answers) {
+			return nil, false
+		}
+	}
+	return result, true
+}
+
+func (m checkboxMatrix) GetID() string {
+	return m.id
+}
+
+func (m checkboxMatrix) GetHTML() template.HTML {
+	f, _ := registry.GetFormatType(m.Format)
+	td := checkboxMatrixTemplateStruct{
+		Title:  f.Format([]byte(m.Title)),
+		Header: make([]template.HTML, len(m.Answers)),
+		Data:   make([]checkboxMatrixTemplateStructInner, 0, len(m.Questions)),
+		Answer: make([][]string, len(m.Answers)),
+		GID:    m.id,
+	}
+	for i := range m.Questions {
+		td.Data = append(td.Data, checkboxMatrixTemplateStructInner{
+			QID:      m.Questions[i][0],
+			Question: f.FormatClean([]byte(m.Questions[i][1])),
+		})
+	}
+	for i := range m.Answers {
+		td.Header[i] = f.Format([]byte(m.Answers[i][1]))
+		td.Answer[i] = []string{m.Answers[i][0], m.Answers[i][1]}
+	}
+
+	if m.Random {
+		rand.Shuffle(len(td.Data), func(i, j int) {
+			td.Data[i], td.Data[j] = td.Data[j], td.Data[i]
+		})
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := checkboxMatrixTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("checkboxmatrix: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (m checkboxMatrix) GetStatisticsHeader() []string {
+	header := make([]string, 0, len(m.Questions)*len(m.Answers))
+	for i := range m.Questions {
+		for j := range m.Answers {
+			header = append(header, fmt.Sprintf("%s_%s_%s", m.id, m.Questions[i][0], m.Answers[j][0]))
+		}
+	}
+	return header
+}
+
+func (m checkboxMatrix) GetStatistics(data []string) [][]string {
+	result := make([][]string, len(data))
+	for d := range data {
+		r := make([]string, 0, len(m.Questions)*len(m.Answers))
+		cells, ok := m.parseResult(data[d])
+		for i := range m.Questions {
+			for j := range m.Answers {
+				switch {
+				case !ok:
+					r = append(r, "error")
+				case cells[i][j]:
+					r = append(r, "true")
+				default:
+					r = append(r, "false")
+				}
+			}
+		}
+		result[d] = r
+	}
+	return result
+}
+
+func (m checkboxMatrix) GetStatisticsDisplay(data []string) template.HTML {
+	return m.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted percentages if the options contain weights. Small counts are suppressed.
+func (m checkboxMatrix) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	count := 0
+	countAnswer := make([][]int, len(m.Questions))
+	weightAnswer := make([][]float64, len(m.Questions))
+	for i := range m.Questions {
+		countAnswer[i] = make([]int, len(m.Answers))
+		weightAnswer[i] = make([]float64, len(m.Answers))
+	}
+	total := 0.0
+
+	for d := range data {
+		cells, ok := m.parseResult(data[d])
+		if !ok {
+			continue
+		}
+		count++
+		total += options.Weight(d)
+		for i := range cells {
+			for j := range cells[i] {
+				if cells[i][j] {
+					countAnswer[i][j]++
+					weightAnswer[i][j] += options.Weight(d)
+				}
+			}
+		}
+	}
+
+	f, _ := registry.GetFormatType(m.Format)
+	td := checkboxMatrixStatisticsTemplateStruct{
+		Title:         f.Format([]byte(m.Title)),
+		Header:        make([]template.HTML, len(m.Answers)),
+		Data:          make([]checkboxMatrixStatisticsTemplateStructInner, 0, len(m.Questions)),
+		Sum:           count,
+		SumSuppressed: options.Suppressed(count),
+	}
+	for i := range m.Answers {
+		td.Header[i] = f.FormatClean([]byte(m.Answers[i][1]))
+	}
+
+	for i := range m.Questions {
+		inner := checkboxMatrixStatisticsTemplateStructInner{
+			Question: f.FormatClean([]byte(m.Questions[i][1])),
+			Result:   make([]checkboxMatrixStatisticsTemplateStructCell, len(m.Answers)),
+		}
+		for j := range m.Answers {
+			inner.Result[j].Number = countAnswer[i][j]
+			inner.Result[j].Suppressed = options.Suppressed(countAnswer[i][j])
+			if total != 0 {
+				inner.Result[j].Percent = weightAnswer[i][j] / total
+			}
+		}
+		td.Data = append(td.Data, inner)
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := checkboxMatrixStatisticsTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("checkboxmatrix: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (m checkboxMatrix) ValidateInput(data map[string][]string) error {
+	return nil
+}
+
+func (m checkboxMatrix) IgnoreRecord(data map[string][]string) bool {
+	return false
+}
+
+func (m checkboxMatrix) GetDatabaseEntry(data map[string][]string) string {
+	result := make([][]bool, len(m.Questions))
+	for i := range m.Questions {
+		result[i] = make([]bool, len(m.Answers))
+		for j := range m.Answers {
+			_, ok := data[fmt.Sprintf("%s_%s_%s", m.id, m.Questions[i][0], m.Answers[j][0])]
+			result[i][j] = ok
+		}
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Sprintf("ERROR: %s", err.Error())
+	}
+	return string(b)
+}
@@ -0,0 +1,349 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package question
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Top-Ranger/questiongo/helper"
+	"github.com/Top-Ranger/questiongo/registry"
+)
+
+func init() {
+	err := registry.RegisterQuestionType(FactoryComputed, "computed")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// FactoryComputed is the factory for computed pseudo-questions.
+// Computed values can be used in screen-out rules (ScreenOut) and in page skip conditions (SkipIf) of pages after the ones containing their references.
+func FactoryComputed(data []byte, id string, language string) (registry.Question, error) {
+	var c computed
+	err := json.Unmarshal(data, &c)
+	if err != nil {
+		return nil, err
+	}
+	c.id = id
+
+	c.expression, err = helper.CompileExpression(c.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("computed: Invalid expression: %w (%s)", err, id)
+	}
+
+	// The final type is only known after CheckReferences
+	if c.ScreenOut && c.expression.Type() != helper.ExpressionBool && c.expression.Type() != helper.ExpressionAny {
+		return nil, fmt.Errorf("computed: ScreenOut needs an expression of type bool, is %s (%s)", c.expression.Type(), id)
+	}
+
+	_, ok := registry.GetFormatType(c.Format)
+	if !ok {
+		return nil, fmt.Errorf("computed: Unknown format type %s (%s)", c.Format, id)
+	}
+
+	return &c, nil
+}
+
+var computedStatisticsTemplate = template.Must(template.New("computedStatisticsTemplate").Parse(`{{.Title}}<br>
+<p><small>{{.Expression}}</small></p>
+{{if .Numeric}}
+<table>
+<tbody>
+{{if .CountSuppressed}}
+<tr>
+<td class="th-cell">[average]</td>
+<td>[suppressed]</td>
+</tr>
+{{else}}
+<tr>
+<td class="th-cell">[average]</td>
+<td>{{printf "%.2f" .Average}}</td>
+</tr>
+<tr>
+<td class="th-cell">[minimum]</td>
+<td>{{printf "%.2f" .Min}}</td>
+</tr>
+<tr>
+<td class="th-cell">[maximum]</td>
+<td>{{printf "%.2f" .Max}}</td>
+</tr>
+{{end}}
+{{else}}
+<table>
+<thead>
+<tr>
+<th>Value</th>
+<th>Number</th>
+<th>Percent</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Data }}
+<tr>
+<td>{{$e.Value}}</td>
+<td>{{$e.Number}}</td>
+<td>{{printf "%.2f" $e.Percent}}</td>
+</tr>
+{{end}}
+{{if .ValuesSuppressed}}
+<tr>
+<td class="th-cell">[values with small counts]</td>
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+</tr>
+{{end}}
+{{end}}
+<tr>
+<td class="th-cell">[number answers]</td>
+<td>{{if .CountSuppressed}}[suppressed]{{else}}{{.Count}}{{end}}</td>
+</tr>
+<tr>
+<td class="th-cell">[no value]</td>
+<td>{{if .NoValueSuppressed}}[suppressed]{{else}}{{.NoValue}}{{end}}</td>
+</tr>
+</tbody>
+</table>
+`))
+
+type computedStatisticsTemplateStructInner struct {
+	Value   string
+	Number  int
+	Percent float64
+}
+
+type computedStatisticsTemplateStruct struct {
+	Title             template.HTML
+	Expression        string
+	Numeric           bool
+	Average           float64
+	Min               float64
+	Max               float64
+	Data              []computedStatisticsTemplateStructInner
+	ValuesSuppressed  bool
+	Count             int
+	CountSuppressed   bool
+	NoValue           int
+	NoValueSuppressed bool
+}
+
+type computed struct {
+	Format     string
+	Title      string
+	Expression string
+	ScreenOut  bool
+
+	id         string
+	expression *helper.Expression
+}
+
+func (c computed) GetID() string {
+	return c.id
+}
+
+func (c computed) GetHTML() template.HTML {
+	return ""
+}
+
+func (c computed) GetStatisticsHeader() []string {
+	return []string{c.id}
+}
+
+func (c computed) GetStatistics(data []string) [][]string {
+	result := make([][]string, len(data))
+	for i := range data {
+		result[i] = []string{data[i]}
+	}
+	return result
+}
+
+func (c computed) GetStatisticsDisplay(data []string) template.HTML {
+	return c.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted percentages and average if the options contain weights. Small counts are suppressed.
+func (c computed) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	f, _ := registry.GetFormatType(c.Format)
+
+	td := computedStatisticsTemplateStruct{
+		Title:      f.Format([]byte(c.Title)),
+		Expression: c.Expression,
+		Numeric:    c.expression.Type() == helper.ExpressionNumber,
+		Min:        math.Inf(1),
+		Max:        math.Inf(-1),
+	}
+
+	answer := make(map[string]int)
+	weightAnswer := make(map[string]float64)
+	sum := 0.0
+	for i := range data {
+		if data[i] == "" {
+			td.NoValue++
+			continue
+		}
+		if td.Numeric {
+			v, err := strconv.ParseFloat(data[i], 64)
+			if err != nil {
+				td.NoValue++
+				continue
+			}
+			td.Average += v * options.Weight(i)
+			td.Min = math.Min(td.Min, v)
+			td.Max = math.Max(td.Max, v)
+		}
+		td.Count++
+		answer[data[i]]++
+		weightAnswer[data[i]] += options.Weight(i)
+		sum += options.Weight(i)
+	}
+
+	if td.Count != 0 && sum > 0 {
+		td.Average /= sum
+	} else if td.Count != 0 {
+		// All answers have a weight of 0
+		td.Average = 0
+	} else {
+		td.Min = 0
+		td.Max = 0
+	}
+
+	td.CountSuppressed = options.Suppressed(td.Count)
+	td.NoValueSuppressed = options.Suppressed(td.NoValue)
+	for k := range answer {
+		if options.Suppressed(answer[k]) {
+			td.ValuesSuppressed = true
+			continue
+		}
+		percent := 0.0
+		if sum > 0 {
+			percent = weightAnswer[k] / sum
+		}
+		td.Data = append(td.Data, computedStatisticsTemplateStructInner{Value: k, Number: answer[k], Percent: percent})
+	}
+	sort.Slice(td.Data, func(i, j int) bool {
+		if td.Data[i].Number != td.Data[j].Number {
+			return td.Data[i].Number > td.Data[j].Number
+		}
+		return td.Data[i].Value < td.Data[j].Value
+	})
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := computedStatisticsTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("computed: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (c computed) ValidateInput(data map[string][]string) error {
+	return nil
+}
+
+func (c computed) IgnoreRecord(data map[string][]string) bool {
+	return false
+}
+
+func (c computed) GetDatabaseEntry(data map[string][]string) string {
+	return ""
+}
+
+// IsNumeric returns whether the expression results in a number.
+func (c computed) IsNumeric() bool {
+	return c.expression.Type() == helper.ExpressionNumber
+}
+
+// GetValues returns the computed number of each entry.
+func (c computed) GetValues(data []string) []float64 {
+	result := make([]float64, len(data))
+	for i := range data {
+		v, err := strconv.ParseFloat(data[i], 64)
+		if err != nil {
+			result[i] = math.NaN()
+			continue
+		}
+		result[i] = v
+	}
+	return result
+}
+
+func (c computed) References() []string {
+	return c.expression.Variables()
+}
+
+// CheckReferences type checks the expression. ScreenOut still needs an expression of type bool afterwards.
+func (c *computed) CheckReferences(types map[string]registry.ValueType) (registry.ValueType, error) {
+	expressionTypes := make(map[string]helper.ExpressionType, len(types))
+	for k, v := range types {
+		switch v {
+		case registry.ValueNumber:
+			expressionTypes[k] = helper.ExpressionNumber
+		case registry.ValueString:
+			expressionTypes[k] = helper.ExpressionString
+		case registry.ValueBool:
+			expressionTypes[k] = helper.ExpressionBool
+		}
+	}
+
+	t, err := c.expression.Check(expressionTypes)
+	if err != nil {
+		choices := make([]string, 0)
+		for _, r := range c.expression.Variables() {
+			if types[r] == registry.ValueString {
+				choices = append(choices, r)
+			}
+		}
+		if len(choices) > 0 {
+			return registry.ValueUnknown, fmt.Errorf("computed: Invalid expression: %w - note that the answers of choice questions (%s) are strings, use number(...) to calculate with them (%s)", err, strings.Join(choices, ", "), c.id)
+		}
+		return registry.ValueUnknown, fmt.Errorf("computed: Invalid expression: %w (%s)", err, c.id)
+	}
+	if c.ScreenOut && t != helper.ExpressionBool {
+		return registry.ValueUnknown, fmt.Errorf("computed: ScreenOut needs an expression of type bool, is %s (%s)", t, c.id)
+	}
+
+	switch t {
+	case helper.ExpressionNumber:
+		return registry.ValueNumber, nil
+	case helper.ExpressionString:
+		return registry.ValueString, nil
+	case helper.ExpressionBool:
+		return registry.ValueBool, nil
+	default:
+		return registry.ValueUnknown, nil
+	}
+}
+
+func (c computed) Compute(values map[string]string) (string, bool) {
+	v, err := c.expression.Evaluate(values)
+	if err != nil {
+		// Missing values are expected (e.g. optional questions), so only log real errors
+		if err != helper.ErrExpressionMissingValue {
+			log.Printf("computed: Can not compute value (%s): %s", c.id, err.Error())
+		}
+		return "", false
+	}
+	if c.ScreenOut {
+		return v.Format(), v.Bool
+	}
+	return v.Format(), false
+}
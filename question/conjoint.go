@@ -0,0 +1,866 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package question
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/Top-Ranger/questiongo/helper"
+	"github.com/Top-Ranger/questiongo/registry"
+	"github.com/Top-Ranger/questiongo/translation"
+)
+
+const (
+	conjointNone = "none"
+
+	// conjointMaxIterations is the maximum number of Newton steps of the part-worth estimation.
+	conjointMaxIterations = 50
+)
+
+func init() {
+	err := registry.RegisterQuestionType(FactoryConjoint, "conjoint")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// FactoryConjoint is the factory for choice-based conjoint questions.
+func FactoryConjoint(data []byte, id string, language string) (registry.Question, error) {
+	var c conjoint
+	err := json.Unmarshal(data, &c)
+	if err != nil {
+		return nil, err
+	}
+	c.id = id
+
+	c.translation, err = translation.GetTranslation(language)
+	if err != nil {
+		return nil, fmt.Errorf("conjoint: Can not get translation for language '%s' (%s)", language, id)
+	}
+
+	// Sanity checks
+	if len(c.Attributes) == 0 {
+		return nil, fmt.Errorf("conjoint: At least one attribute is needed (%s)", id)
+	}
+	combinations := 1
+	testID := make(map[string]bool)
+	for i := range c.Attributes {
+		if c.Attributes[i].ID == "" {
+			return nil, fmt.Errorf("conjoint: Attribute %d has no ID (%s)", i, id)
+		}
+		if testID[c.Attributes[i].ID] {
+			return nil, fmt.Errorf("conjoint: ID %s found twice (%s)", c.Attributes[i].ID, id)
+		}
+		if strings.Contains(c.Attributes[i].ID, "_") {
+			return nil, fmt.Errorf("conjoint: ID %s must not have '_' (%s)", c.Attributes[i].ID, id)
+		}
+		testID[c.Attributes[i].ID] = true
+
+		if len(c.Attributes[i].Levels) < 2 {
+			return nil, fmt.Errorf("conjoint: Attribute %s needs at least 2 levels (%s)", c.Attributes[i].ID, id)
+		}
+		testLevel := make(map[string]bool)
+		for j := range c.Attributes[i].Levels {
+			if len(c.Attributes[i].Levels[j]) != 2 {
+				return nil, fmt.Errorf("conjoint: Level %d of attribute %s must have exactly 2 values (id, text) (%s)", j, c.Attributes[i].ID, id)
+			}
+			if testLevel[c.Attributes[i].Levels[j][0]] {
+				return nil, fmt.Errorf("conjoint: Level ID %s found twice in attribute %s (%s)", c.Attributes[i].Levels[j][0], c.Attributes[i].ID, id)
+			}
+			testLevel[c.Attributes[i].Levels[j][0]] = true
+		}
+		if combinations < 1000000 {
+			combinations *= len(c.Attributes[i].Levels)
+		}
+	}
+
+	if c.Tasks < 1 {
+		return nil, fmt.Errorf("conjoint: Tasks must be at least 1, is %d (%s)", c.Tasks, id)
+	}
+	if c.Alternatives < 2 {
+		return nil, fmt.Errorf("conjoint: Alternatives must be at least 2, is %d (%s)", c.Alternatives, id)
+	}
+	if c.Alternatives > combinations {
+		return nil, fmt.Errorf("conjoint: Alternatives (%d) must not be larger than the number of possible profiles (%d) (%s)", c.Alternatives, combinations, id)
+	}
+
+	_, ok := registry.GetFormatType(c.Format)
+	if !ok {
+		return nil, fmt.Errorf("conjoint: Unknown format type %s (%s)", c.Format, id)
+	}
+
+	return &c, nil
+}
+
+var conjointTemplate = template.Must(template.New("conjointTemplate").Funcs(template.FuncMap{"inc": func(i int) int { return i + 1 }}).Parse(`{{.Question}}<br>
+{{range $i, $e := .Tasks }}
+<p><em>{{printf $.Translation.ConjointTask $e.Number (len $.Tasks)}}</em></p>
+<input type="hidden" name="{{$.QID}}_{{$e.Number}}_design" value="{{$e.Design}}">
+<table>
+<thead>
+<tr>
+<th></th>
+{{range $I, $E := $e.Profiles }}
+<th class="centre">{{printf $.Translation.ConjointOption (inc $I)}}</th>
+{{end}}
+</tr>
+</thead>
+<tbody>
+{{range $A, $attribute := $.Attributes }}
+<tr>
+<td>{{$attribute}}</td>
+{{range $I, $E := $e.Profiles }}
+<td class="centre">{{index $E $A}}</td>
+{{end}}
+</tr>
+{{end}}
+<tr>
+<td></td>
+{{range $I, $E := $e.Profiles }}
+<td class="centre"><input type="radio" id="{{$.QID}}_{{$e.Number}}_{{inc $I}}" name="{{$.QID}}_{{$e.Number}}" value="{{inc $I}}" title="{{printf $.Translation.ConjointOption (inc $I)}}" {{if $.Required}}required{{end}}></td>
+{{end}}
+</tr>
+</tbody>
+</table>
+{{if $.NoneOption}}
+<input type="radio" id="{{$.QID}}_{{$e.Number}}_none" name="{{$.QID}}_{{$e.Number}}" value="none" {{if $.Required}}required{{end}}><label for="{{$.QID}}_{{$e.Number}}_none">{{$.Translation.ConjointNone}}</label><br>
+{{end}}
+{{end}}
+`))
+
+var conjointStatisticsTemplate = template.Must(template.New("conjointStatisticsTemplate").Funcs(template.FuncMap{"percent": func(f float64) string { return fmt.Sprintf("%.2f", 100*f) }}).Parse(`{{.Question}}<br>
+{{if .CountSuppressed}}
+<p>[suppressed]</p>
+{{else}}
+<table>
+<thead>
+<tr>
+<th>Attribute</th>
+<th>Level</th>
+<th>Shown</th>
+<th>Chosen</th>
+<th>Choice share</th>
+<th>Part-worth</th>
+<th>Importance</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Data }}
+{{range $I, $E := $e.Levels }}
+<tr>
+<td>{{if eq $I 0}}{{$e.Attribute}}{{end}}</td>
+<td>{{$E.Level}}</td>
+<td>{{$E.Shown}}</td>
+{{if $E.Suppressed}}
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+{{else}}
+<td>{{$E.Chosen}}</td>
+<td>{{printf "%.2f" $E.Share}}</td>
+{{end}}
+<td>{{if $.Estimated}}{{printf "%.3f" $E.Utility}}{{else}}-{{end}}</td>
+<td>{{if eq $I 0}}{{if $.Estimated}}{{percent $e.Importance}}%{{else}}-{{end}}{{end}}</td>
+</tr>
+{{end}}
+{{end}}
+{{if .NoneOption}}
+<tr>
+<td class="th-cell">[none]</td>
+<td></td>
+<td>{{.Tasks}}</td>
+{{if .NoneSuppressed}}
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+{{else}}
+<td>{{.None}}</td>
+<td>{{printf "%.2f" .NoneShare}}</td>
+{{end}}
+<td>{{if .Estimated}}{{printf "%.3f" .NoneUtility}}{{else}}-{{end}}</td>
+<td></td>
+</tr>
+{{end}}
+<tr>
+<td class="th-cell">[number answers]</td>
+<td>{{.Count}}</td>
+</tr>
+<tr>
+<td class="th-cell">[answered tasks]</td>
+<td>{{.Tasks}}</td>
+</tr>
+</tbody>
+</table>
+<p><small>Part-worths are estimated with a multinomial logit model and centred per attribute.</small></p>
+{{.Image}}
+{{end}}
+`))
+
+type conjointTemplateStructTask struct {
+	Number   int
+	Design   string
+	Profiles [][]template.HTML
+}
+
+type conjointTemplateStruct struct {
+	Question    template.HTML
+	QID         string
+	Required    bool
+	NoneOption  bool
+	Attributes  []template.HTML
+	Tasks       []conjointTemplateStructTask
+	Translation translation.Translation
+}
+
+type conjointStatisticsTemplateStructLevel struct {
+	Level      template.HTML
+	Shown      int
+	Chosen     int
+	Share      float64
+	Utility    float64
+	Suppressed bool
+}
+
+type conjointStatisticsTemplateStructAttribute struct {
+	Attribute  template.HTML
+	Levels     []conjointStatisticsTemplateStructLevel
+	Importance float64
+}
+
+type conjointStatisticsTemplateStruct struct {
+	Question        template.HTML
+	Data            []conjointStatisticsTemplateStructAttribute
+	NoneOption      bool
+	None            int
+	NoneSuppressed  bool
+	NoneShare       float64
+	NoneUtility     float64
+	Estimated       bool
+	Count           int
+	CountSuppressed bool
+	Tasks           int
+	Image           template.HTML
+}
+
+type conjointAttribute struct {
+	ID     string
+	Text   string
+	Levels [][]string
+}
+
+// conjointTask holds the profiles shown in a task (level IDs per alternative and attribute) together with the choice.
+type conjointTask struct {
+	Profiles [][]string
+	Choice   string
+}
+
+type conjoint struct {
+	Format       string
+	Question     string
+	Required     bool
+	NoneOption   bool
+	Tasks        int
+	Alternatives int
+	Attributes   []conjointAttribute
+
+	id          string
+	translation translation.Translation
+}
+
+func (c conjoint) parseResult(data string) ([]conjointTask, bool) {
+	if data == "" || strings.HasPrefix(data, "ERROR") {
+		return nil, false
+	}
+	var result []conjointTask
+	err := json.Unmarshal([]byte(data), &result)
+	if err != nil || len(result) != c.Tasks {
+		return nil, false
+	}
+	return result, true
+}
+
+// levelIndex returns the index of a level of an attribute, or -1 if it does not exist.
+func (c conjoint) levelIndex(attribute int, level string) int {
+	for i := range c.Attributes[attribute].Levels {
+		if c.Attributes[attribute].Levels[i][0] == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// validProfiles reports whether the profiles match the attributes and levels of the question and all profiles differ.
+func (c conjoint) validProfiles(profiles [][]string) bool {
+	if len(profiles) != c.Alternatives {
+		return false
+	}
+	seen := make(map[string]bool, len(profiles))
+	for p := range profiles {
+		if len(profiles[p]) != len(c.Attributes) {
+			return false
+		}
+		for a := range profiles[p] {
+			if c.levelIndex(a, profiles[p][a]) == -1 {
+				return false
+			}
+		}
+		key := strings.Join(profiles[p], "\x00")
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
+	}
+	return true
+}
+
+func (c conjoint) GetID() string {
+	return c.id
+}
+
+func (c conjoint) GetHTML() template.HTML {
+	f, _ := registry.GetFormatType(c.Format)
+
+	td := conjointTemplateStruct{
+		Question:    f.Format([]byte(c.Question)),
+		QID:         c.id,
+		Required:    c.Required,
+		NoneOption:  c.NoneOption,
+		Attributes:  make([]template.HTML, len(c.Attributes)),
+		Tasks:       make([]conjointTemplateStructTask, c.Tasks),
+		Translation: c.translation,
+	}
+	for a := range c.Attributes {
+		td.Attributes[a] = f.FormatClean([]byte(c.Attributes[a].Text))
+	}
+
+	for t := range td.Tasks {
+		// Random profiles, all alternatives of a task must differ
+		profiles := make([][]string, 0, c.Alternatives)
+		seen := make(map[string]bool)
+		for tries := 0; len(profiles) < c.Alternatives; tries++ {
+			profile := make([]string, len(c.Attributes))
+			for a := range c.Attributes {
+				profile[a] = c.Attributes[a].Levels[rand.Intn(len(c.Attributes[a].Levels))][0]
+			}
+			key := strings.Join(profile, "\x00")
+			if seen[key] && tries < 1000 {
+				continue
+			}
+			seen[key] = true
+			profiles = append(profiles, profile)
+		}
+
+		b, err := json.Marshal(profiles)
+		if err != nil {
+			log.Printf("conjoint: Can not encode design (%s)", err.Error())
+		}
+		td.Tasks[t] = conjointTemplateStructTask{
+			Number:   t + 1,
+			Design:   string(b),
+			Profiles: make([][]template.HTML, len(profiles)),
+		}
+		for p := range profiles {
+			td.Tasks[t].Profiles[p] = make([]template.HTML, len(c.Attributes))
+			for a := range c.Attributes {
+				td.Tasks[t].Profiles[p][a] = f.FormatClean([]byte(c.Attributes[a].Levels[c.levelIndex(a, profiles[p][a])][1]))
+			}
+		}
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := conjointTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("conjoint: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (c conjoint) GetStatisticsHeader() []string {
+	header := make([]string, 0, c.Tasks*(1+c.Alternatives*len(c.Attributes)))
+	for t := 0; t < c.Tasks; t++ {
+		header = append(header, fmt.Sprintf("%s_%d_choice", c.id, t+1))
+		for p := 0; p < c.Alternatives; p++ {
+			for a := range c.Attributes {
+				header = append(header, fmt.Sprintf("%s_%d_%d_%s", c.id, t+1, p+1, c.Attributes[a].ID))
+			}
+		}
+	}
+	return header
+}
+
+func (c conjoint) GetStatistics(data []string) [][]string {
+	result := make([][]string, len(data))
+	for d := range data {
+		r := make([]string, 0, c.Tasks*(1+c.Alternatives*len(c.Attributes)))
+		tasks, ok := c.parseResult(data[d])
+		for t := 0; t < c.Tasks; t++ {
+			valid := ok && c.validProfiles(tasks[t].Profiles)
+			if ok {
+				r = append(r, tasks[t].Choice)
+			} else {
+				r = append(r, "error")
+			}
+			for p := 0; p < c.Alternatives; p++ {
+				for a := range c.Attributes {
+					if valid {
+						r = append(r, tasks[t].Profiles[p][a])
+					} else {
+						r = append(r, "")
+					}
+				}
+			}
+		}
+		result[d] = r
+	}
+	return result
+}
+
+func (c conjoint) GetStatisticsDisplay(data []string) template.HTML {
+	return c.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results. All results are suppressed if there are too few answers, otherwise small numbers of choices are suppressed.
+// Weights are not used, since the estimation is based on the single choices.
+func (c conjoint) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	f, _ := registry.GetFormatType(c.Format)
+
+	td := conjointStatisticsTemplateStruct{
+		Question:   f.Format([]byte(c.Question)),
+		Data:       make([]conjointStatisticsTemplateStructAttribute, len(c.Attributes)),
+		NoneOption: c.NoneOption,
+	}
+	for a := range c.Attributes {
+		td.Data[a].Attribute = f.FormatClean([]byte(c.Attributes[a].Text))
+		td.Data[a].Levels = make([]conjointStatisticsTemplateStructLevel, len(c.Attributes[a].Levels))
+		for l := range c.Attributes[a].Levels {
+			td.Data[a].Levels[l].Level = f.FormatClean([]byte(c.Attributes[a].Levels[l][1]))
+		}
+	}
+
+	// Observations for the estimation: level indices per alternative and the chosen alternative (len(profiles) = none)
+	type observation struct {
+		profiles [][]int
+		choice   int
+	}
+	observations := make([]observation, 0)
+
+	for d := range data {
+		tasks, ok := c.parseResult(data[d])
+		if !ok {
+			continue
+		}
+		td.Count++
+		for t := range tasks {
+			if tasks[t].Choice == "" || !c.validProfiles(tasks[t].Profiles) {
+				continue
+			}
+			o := observation{profiles: make([][]int, len(tasks[t].Profiles)), choice: -1}
+			if tasks[t].Choice == conjointNone {
+				o.choice = len(tasks[t].Profiles)
+			} else if choice, err := strconv.Atoi(tasks[t].Choice); err == nil && choice >= 1 && choice <= len(tasks[t].Profiles) {
+				o.choice = choice - 1
+			}
+			if o.choice == -1 {
+				continue
+			}
+			td.Tasks++
+			for p := range tasks[t].Profiles {
+				o.profiles[p] = make([]int, len(c.Attributes))
+				for a := range c.Attributes {
+					l := c.levelIndex(a, tasks[t].Profiles[p][a])
+					o.profiles[p][a] = l
+					td.Data[a].Levels[l].Shown++
+					if o.choice == p {
+						td.Data[a].Levels[l].Chosen++
+					}
+				}
+			}
+			if o.choice == len(tasks[t].Profiles) {
+				td.None++
+			}
+			observations = append(observations, o)
+		}
+	}
+
+	for a := range td.Data {
+		for l := range td.Data[a].Levels {
+			if td.Data[a].Levels[l].Shown != 0 {
+				td.Data[a].Levels[l].Share = float64(td.Data[a].Levels[l].Chosen) / float64(td.Data[a].Levels[l].Shown)
+			}
+		}
+	}
+	if td.Tasks != 0 {
+		td.NoneShare = float64(td.None) / float64(td.Tasks)
+	}
+
+	td.CountSuppressed = options.Suppressed(td.Count)
+	if td.CountSuppressed {
+		observations = nil
+	}
+	// The choices of the levels of each attribute and the none option add up to the answered tasks
+	cells := func(a int) []int {
+		result := make([]int, len(td.Data[a].Levels)+1)
+		for l := range td.Data[a].Levels {
+			result[l] = td.Data[a].Levels[l].Chosen
+		}
+		result[len(td.Data[a].Levels)] = td.None
+		return result
+	}
+	for a := range td.Data {
+		if options.SuppressedCells(cells(a))[len(td.Data[a].Levels)] {
+			td.NoneSuppressed = true
+		}
+	}
+	for a := range td.Data {
+		counts := cells(a)
+		suppressed := options.SuppressedCells(counts)
+		if td.NoneSuppressed && !suppressed[len(counts)-1] {
+			// The none option is suppressed due to another attribute, so a level must be suppressed as well if none is yet
+			suppressed[len(counts)-1] = true
+			smallest, levelSuppressed := -1, false
+			for l := range td.Data[a].Levels {
+				levelSuppressed = levelSuppressed || suppressed[l]
+				if counts[l] != 0 && (smallest == -1 || counts[l] < counts[smallest]) {
+					smallest = l
+				}
+			}
+			if !levelSuppressed && smallest != -1 {
+				suppressed[smallest] = true
+			}
+		}
+		for l := range td.Data[a].Levels {
+			td.Data[a].Levels[l].Suppressed = suppressed[l]
+		}
+	}
+
+	// Multinomial logit with dummy coding (first level of each attribute is the reference) and a constant for the none option
+	offset := make([]int, len(c.Attributes))
+	parameters := 0
+	for a := range c.Attributes {
+		offset[a] = parameters
+		parameters += len(c.Attributes[a].Levels) - 1
+	}
+	noneParameter := -1
+	if c.NoneOption {
+		noneParameter = parameters
+		parameters++
+	}
+	features := func(o observation, alternative int) []float64 {
+		x := make([]float64, parameters)
+		if alternative == len(o.profiles) {
+			x[noneParameter] = 1
+			return x
+		}
+		for a, l := range o.profiles[alternative] {
+			if l > 0 {
+				x[offset[a]+l-1] = 1
+			}
+		}
+		return x
+	}
+
+	if len(observations) > 0 {
+		beta := make([]float64, parameters)
+		converged := false
+		for iteration := 0; iteration < conjointMaxIterations; iteration++ {
+			gradient := make([]float64, parameters)
+			information := make([][]float64, parameters)
+			for i := range information {
+				information[i] = make([]float64, parameters)
+				// Small ridge for numerical stability (e.g. levels never shown)
+				information[i][i] = 1e-6
+			}
+			for _, o := range observations {
+				alternatives := len(o.profiles)
+				if c.NoneOption {
+					alternatives++
+				}
+				x := make([][]float64, alternatives)
+				utility := make([]float64, alternatives)
+				maxUtility := math.Inf(-1)
+				for j := range x {
+					x[j] = features(o, j)
+					for k := range beta {
+						utility[j] += beta[k] * x[j][k]
+					}
+					maxUtility = math.Max(maxUtility, utility[j])
+				}
+				sum := 0.0
+				probability := make([]float64, alternatives)
+				for j := range probability {
+					probability[j] = math.Exp(utility[j] - maxUtility)
+					sum += probability[j]
+				}
+				mean := make([]float64, parameters)
+				for j := range probability {
+					probability[j] /= sum
+					for k := range mean {
+						mean[k] += probability[j] * x[j][k]
+					}
+				}
+				for k := range gradient {
+					gradient[k] += x[o.choice][k] - mean[k]
+				}
+				for j := range probability {
+					for k := range mean {
+						dk := x[j][k] - mean[k]
+						if dk == 0 {
+							continue
+						}
+						for m := range mean {
+							information[k][m] += probability[j] * dk * (x[j][m] - mean[m])
+						}
+					}
+				}
+			}
+			step, ok := conjointSolve(information, gradient)
+			if !ok {
+				break
+			}
+			change := 0.0
+			for k := range beta {
+				// Limit step size to keep the estimation stable with separated data
+				step[k] = math.Max(-5, math.Min(5, step[k]))
+				beta[k] += step[k]
+				change = math.Max(change, math.Abs(step[k]))
+			}
+			if change < 1e-8 {
+				converged = true
+				break
+			}
+		}
+
+		valid := true
+		for k := range beta {
+			if math.IsNaN(beta[k]) || math.IsInf(beta[k], 0) {
+				valid = false
+			}
+		}
+		if valid {
+			td.Estimated = true
+			if !converged {
+				log.Printf("conjoint: Estimation did not converge (%s)", c.id)
+			}
+			totalRange := 0.0
+			ranges := make([]float64, len(c.Attributes))
+			for a := range c.Attributes {
+				mean := 0.0
+				for l := range td.Data[a].Levels {
+					if l > 0 {
+						td.Data[a].Levels[l].Utility = beta[offset[a]+l-1]
+					}
+					mean += td.Data[a].Levels[l].Utility
+				}
+				mean /= float64(len(td.Data[a].Levels))
+				minUtility, maxUtility := math.Inf(1), math.Inf(-1)
+				for l := range td.Data[a].Levels {
+					td.Data[a].Levels[l].Utility -= mean
+					minUtility = math.Min(minUtility, td.Data[a].Levels[l].Utility)
+					maxUtility = math.Max(maxUtility, td.Data[a].Levels[l].Utility)
+				}
+				ranges[a] = maxUtility - minUtility
+				totalRange += ranges[a]
+			}
+			if totalRange > 0 {
+				for a := range td.Data {
+					td.Data[a].Importance = ranges[a] / totalRange
+				}
+			}
+			if c.NoneOption {
+				td.NoneUtility = beta[noneParameter]
+			}
+		}
+	}
+
+	if td.Estimated {
+		v := make([]helper.ChartValue, len(td.Data))
+		for a := range td.Data {
+			v[a].Label = string(td.Data[a].Attribute)
+			v[a].Value = 100 * td.Data[a].Importance
+		}
+		td.Image = helper.BarChart(v, c.id, string(f.FormatClean([]byte(c.Question))))
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := conjointStatisticsTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("conjoint: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (c conjoint) ValidateInput(data map[string][]string) error {
+	for t := 0; t < c.Tasks; t++ {
+		design := data[fmt.Sprintf("%s_%d_design", c.id, t+1)]
+		choice := data[fmt.Sprintf("%s_%d", c.id, t+1)]
+
+		if len(choice) == 0 || choice[0] == "" {
+			if c.Required {
+				return fmt.Errorf("conjoint (%s): No input found for task %d", c.id, t+1)
+			}
+			continue
+		}
+
+		if len(design) == 0 {
+			return fmt.Errorf("conjoint (%s): No design found for task %d", c.id, t+1)
+		}
+		var profiles [][]string
+		err := json.Unmarshal([]byte(design[0]), &profiles)
+		if err != nil || !c.validProfiles(profiles) {
+			return fmt.Errorf("conjoint (%s): Invalid design for task %d", c.id, t+1)
+		}
+
+		if choice[0] == conjointNone {
+			if !c.NoneOption {
+				return fmt.Errorf("conjoint (%s): None option not allowed in task %d", c.id, t+1)
+			}
+			continue
+		}
+		n, err := strconv.Atoi(choice[0])
+		if err != nil || n < 1 || n > c.Alternatives {
+			return fmt.Errorf("conjoint (%s): Invalid choice '%s' in task %d", c.id, choice[0], t+1)
+		}
+	}
+	return nil
+}
+
+func (c conjoint) IgnoreRecord(data map[string][]string) bool {
+	return false
+}
+
+func (c conjoint) GetDatabaseEntry(data map[string][]string) string {
+	result := make([]conjointTask, c.Tasks)
+	for t := range result {
+		if v := data[fmt.Sprintf("%s_%d", c.id, t+1)]; len(v) > 0 {
+			result[t].Choice = v[0]
+		}
+		if v := data[fmt.Sprintf("%s_%d_design", c.id, t+1)]; len(v) > 0 {
+			var profiles [][]string
+			if json.Unmarshal([]byte(v[0]), &profiles) == nil && c.validProfiles(profiles) {
+				result[t].Profiles = profiles
+			}
+		}
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Sprintf("ERROR: %s", err.Error())
+	}
+	return string(b)
+}
+
+// WriteZipFiles writes the design matrix in long format (one row per respondent, task and alternative).
+// The respondent is the number of the record in the unfiltered results.
+func (c conjoint) WriteZipFiles(data []string, records []int, create func(name string) (io.Writer, error)) error {
+	f, err := create(fmt.Sprintf("%s_design.csv", c.id))
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+
+	header := []string{"respondent", "task", "alternative"}
+	for a := range c.Attributes {
+		header = append(header, c.Attributes[a].ID)
+	}
+	header = append(header, "chosen")
+	err = w.Write(helper.EscapeCSVLine(header))
+	if err != nil {
+		return err
+	}
+
+	for d := range data {
+		tasks, ok := c.parseResult(data[d])
+		if !ok {
+			continue
+		}
+		for t := range tasks {
+			if !c.validProfiles(tasks[t].Profiles) {
+				continue
+			}
+			for p := range tasks[t].Profiles {
+				line := []string{strconv.Itoa(records[d]), strconv.Itoa(t + 1), strconv.Itoa(p + 1)}
+				line = append(line, tasks[t].Profiles[p]...)
+				if tasks[t].Choice == strconv.Itoa(p+1) {
+					line = append(line, "1")
+				} else {
+					line = append(line, "0")
+				}
+				err = w.Write(helper.EscapeCSVLine(line))
+				if err != nil {
+					return err
+				}
+			}
+			if c.NoneOption {
+				line := []string{strconv.Itoa(records[d]), strconv.Itoa(t + 1), conjointNone}
+				line = append(line, make([]string, len(c.Attributes))...)
+				if tasks[t].Choice == conjointNone {
+					line = append(line, "1")
+				} else {
+					line = append(line, "0")
+				}
+				err = w.Write(helper.EscapeCSVLine(line))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// conjointSolve solves a*x = b using Gaussian elimination with partial pivoting.
+// a and b are modified.
+func conjointSolve(a [][]float64, b []float64) ([]float64, bool) {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+		for row := col + 1; row < n; row++ {
+			factor := a[row][col] / a[col][col]
+			for k := col; k < n; k++ {
+				a[row][k] -= factor * a[col][k]
+			}
+			b[row] -= factor * b[col]
+		}
+	}
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := b[row]
+		for k := row + 1; k < n; k++ {
+			sum -= a[row][k] * x[k]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x, true
+}
@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2022 Marcus Soll
+// Copyright 2020,2022,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -22,11 +22,13 @@ import (
 	"html/template"
 	"log"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Top-Ranger/questiongo/helper"
 	"github.com/Top-Ranger/questiongo/registry"
+	"github.com/Top-Ranger/questiongo/translation"
 )
 
 func init() {
@@ -45,6 +47,42 @@ func FactoryDate(data []byte, id string, language string) (registry.Question, er
 	}
 	d.id = id
 
+	d.translation, err = translation.GetTranslation(language)
+	if err != nil {
+		return nil, fmt.Errorf("date: Can not get translation for language '%s' (%s)", language, id)
+	}
+
+	// Sanity checks
+	for _, v := range []string{d.MinDate, d.MaxDate} {
+		if v == "" {
+			continue
+		}
+		_, err = dateResolve(v, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("date: %w (%s)", err, id)
+		}
+	}
+
+	weekdays := make(map[string]int, 7)
+	for i := time.Sunday; i <= time.Saturday; i++ {
+		weekdays[strings.ToLower(i.String())] = int(i)
+	}
+	for i := range d.DisallowedWeekdays {
+		w, ok := weekdays[strings.ToLower(d.DisallowedWeekdays[i])]
+		if !ok {
+			return nil, fmt.Errorf("date: Unknown weekday %s (%s)", d.DisallowedWeekdays[i], id)
+		}
+		d.weekdays = append(d.weekdays, w)
+	}
+
+	switch d.Aggregation {
+	case "":
+		d.Aggregation = "day"
+	case "day", "week", "month", "year":
+	default:
+		return nil, fmt.Errorf("date: Unknown aggregation %s (%s)", d.Aggregation, id)
+	}
+
 	_, ok := registry.GetFormatType(d.Format)
 	if !ok {
 		return nil, fmt.Errorf("date: Unknown format type %s (%s)", d.Format, id)
@@ -54,7 +92,27 @@ func FactoryDate(data []byte, id string, language string) (registry.Question, er
 }
 
 var dateTemplate = template.Must(template.New("dateTemplate").Parse(`<label for="{{.QID}}">{{.Question}}</label><br>
-<input type="date" id="{{.QID}}" name="{{.QID}}" placeholder="yyyy-mm-dd" pattern="^\d{4}-\d{2}-\d{2}$" {{if .Required}} required {{end}}>
+{{if .DateTime}}
+<input type="datetime-local" id="{{.QID}}" name="{{.QID}}" placeholder="yyyy-mm-ddThh:mm" pattern="^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}$" {{if .Min}}min="{{.Min}}T00:00"{{end}} {{if .Max}}max="{{.Max}}T23:59"{{end}} {{if .Required}} required {{end}}>
+{{else}}
+<input type="date" id="{{.QID}}" name="{{.QID}}" placeholder="yyyy-mm-dd" pattern="^\d{4}-\d{2}-\d{2}$" {{if .Min}}min="{{.Min}}"{{end}} {{if .Max}}max="{{.Max}}"{{end}} {{if .Required}} required {{end}}>
+{{end}}
+{{if .Weekdays}}
+<script>
+{
+  let input = document.getElementById({{.QID}});
+  let weekdays = {{.Weekdays}};
+  input.addEventListener('input', function() {
+    let d = new Date(input.value.slice(0, 10) + 'T00:00');
+    if(input.value !== '' && weekdays.includes(d.getDay())) {
+      input.setCustomValidity({{.Translation.DateWeekdayNotAllowed}});
+    } else {
+      input.setCustomValidity('');
+    }
+  });
+}
+</script>
+{{end}}
 `))
 
 var dateStatisticsTemplate = template.Must(template.New("dateStatisticTemplate").Parse(`{{.Question}}<br>
@@ -70,13 +128,25 @@ var dateStatisticsTemplate = template.Must(template.New("dateStatisticTemplate")
 {{range $i, $e := .Data }}
 <tr>
 <td {{if $e.Special}}class="th-cell"{{end}}>{{$e.Date}}</td>
+{{if $e.Suppressed}}
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+{{else}}
 <td>{{$e.Number}}</td>
 <td>{{printf "%.2f" $e.Percent}}</td>
+{{end}}
+</tr>
+{{end}}
+{{if .DatesSuppressed}}
+<tr>
+<td class="th-cell">[dates with small counts]</td>
+<td>[suppressed]</td>
+<td>[suppressed]</td>
 </tr>
 {{end}}
 <tr>
 <td class="th-cell">[number answers]</td>
-<td>{{.Sum}}</td>
+<td>{{if .SumSuppressed}}[suppressed]{{else}}{{.Sum}}{{end}}</td>
 </tr>
 </tbody>
 </table>
@@ -85,23 +155,31 @@ var dateStatisticsTemplate = template.Must(template.New("dateStatisticTemplate")
 `))
 
 type dateTemplateStruct struct {
-	Question template.HTML
-	QID      string
-	Required bool
+	Question    template.HTML
+	QID         string
+	Required    bool
+	DateTime    bool
+	Min         string
+	Max         string
+	Weekdays    []int
+	Translation translation.Translation
 }
 
 type dateStatisticTemplateStructInner struct {
-	Date    string
-	Number  int
-	Special bool
-	Percent float64
+	Date       string
+	Number     int
+	Special    bool
+	Percent    float64
+	Suppressed bool
 }
 
 type dateStatisticTemplateStruct struct {
-	Question template.HTML
-	Data     []dateStatisticTemplateStructInner
-	Image    template.HTML
-	Sum      int
+	Question        template.HTML
+	Data            []dateStatisticTemplateStructInner
+	DatesSuppressed bool
+	Image           template.HTML
+	Sum             int
+	SumSuppressed   bool
 }
 type dateStatisticTemplateStructInnerSort []dateStatisticTemplateStructInner
 
@@ -118,11 +196,93 @@ func (d dateStatisticTemplateStructInnerSort) Swap(i, j int) {
 }
 
 type dateQuestion struct {
-	Format   string
-	Question string
-	Required bool
+	Format             string
+	Question           string
+	Required           bool
+	MinDate            string // date, "today" or relative to today (e.g. "-18y", "+2w")
+	MaxDate            string // date, "today" or relative to today (e.g. "-18y", "+2w")
+	DisallowedWeekdays []string
+	DateTime           bool
+	Aggregation        string // statistics: "day", "week", "month" or "year"
+
+	id          string
+	weekdays    []int
+	translation translation.Translation
+}
+
+// dateResolve returns the date described by value.
+// value is either a date (2006-01-02), "today" or an offset to today consisting of a sign, a number and a unit (d, w, m, y).
+// The result is always at midnight UTC.
+func dateResolve(value string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if value == "today" {
+		return today, nil
+	}
+	if len(value) >= 3 && (value[0] == '+' || value[0] == '-') {
+		n, err := strconv.Atoi(value[1 : len(value)-1])
+		if err == nil && n >= 0 {
+			if value[0] == '-' {
+				n = -n
+			}
+			switch value[len(value)-1] {
+			case 'd':
+				return today.AddDate(0, 0, n), nil
+			case 'w':
+				return today.AddDate(0, 0, 7*n), nil
+			case 'm':
+				return today.AddDate(0, n, 0), nil
+			case 'y':
+				return today.AddDate(n, 0, 0), nil
+			}
+		}
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s'", value)
+	}
+	return t, nil
+}
 
-	id string
+func (d dateQuestion) layout() string {
+	if d.DateTime {
+		return "2006-01-02T15:04"
+	}
+	return "2006-01-02"
+}
+
+// bounds returns the current minimum and maximum date in the format 2006-01-02. Unset bounds are empty.
+func (d dateQuestion) bounds() (string, string) {
+	now := time.Now()
+	min, max := "", ""
+	if d.MinDate != "" {
+		t, err := dateResolve(d.MinDate, now)
+		if err == nil {
+			min = t.Format("2006-01-02")
+		}
+	}
+	if d.MaxDate != "" {
+		t, err := dateResolve(d.MaxDate, now)
+		if err == nil {
+			max = t.Format("2006-01-02")
+		}
+	}
+	return min, max
+}
+
+// bucket returns the label of the statistics bucket of t as well as the start of the bucket in ISO 8601 format.
+func (d dateQuestion) bucket(t time.Time) (string, string) {
+	switch d.Aggregation {
+	case "week":
+		start := t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+		y, w := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", y, w), start.Format("2006-01-02")
+	case "month":
+		return t.Format("2006-01"), t.Format("2006-01") + "-01"
+	case "year":
+		return t.Format("2006"), t.Format("2006") + "-01-01"
+	default:
+		return t.Format("2006-01-02"), t.Format("2006-01-02")
+	}
 }
 
 func (d dateQuestion) GetID() string {
@@ -133,10 +293,14 @@ func (d dateQuestion) GetHTML() template.HTML {
 	f, _ := registry.GetFormatType(d.Format)
 
 	td := dateTemplateStruct{
-		Question: f.Format([]byte(d.Question)),
-		QID:      d.id,
-		Required: d.Required,
+		Question:    f.Format([]byte(d.Question)),
+		QID:         d.id,
+		Required:    d.Required,
+		DateTime:    d.DateTime,
+		Weekdays:    d.weekdays,
+		Translation: d.translation,
 	}
+	td.Min, td.Max = d.bounds()
 
 	output := bytes.NewBuffer(make([]byte, 0))
 	err := dateTemplate.Execute(output, td)
@@ -159,8 +323,16 @@ func (d dateQuestion) GetStatistics(data []string) [][]string {
 }
 
 func (d dateQuestion) GetStatisticsDisplay(data []string) template.HTML {
+	return d.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted percentages if the options contain weights.
+// Dates with small counts are combined into a single row and left out of the chart.
+func (d dateQuestion) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
 	f, _ := registry.GetFormatType(d.Format)
 	answer := make(map[string]int)
+	weightAnswer := make(map[string]float64)
+	sum := 0.0
 
 	td := dateStatisticTemplateStruct{
 		Question: f.Format([]byte(d.Question)),
@@ -168,31 +340,63 @@ func (d dateQuestion) GetStatisticsDisplay(data []string) template.HTML {
 		Sum:      0,
 	}
 
+	chartTime := make(map[string]string)
 	for i := range data {
 		if data[i] == "" {
 			answer["[no answer]"]++
+			weightAnswer["[no answer]"] += options.Weight(i)
 			td.Sum++
+			sum += options.Weight(i)
 		} else if strings.HasPrefix(data[i], "[invalid input]") {
 			answer["[invalid input]"]++
+			weightAnswer["[invalid input]"] += options.Weight(i)
 		} else {
-			answer[data[i]]++
+			t, err := time.Parse(d.layout(), data[i])
+			if err != nil {
+				answer["[invalid input]"]++
+				weightAnswer["[invalid input]"] += options.Weight(i)
+				continue
+			}
+			label, start := d.bucket(t)
+			answer[label]++
+			weightAnswer[label] += options.Weight(i)
+			chartTime[label] = start
 			td.Sum++
+			sum += options.Weight(i)
 		}
 	}
 
 	for k := range answer {
-		td.Data = append(td.Data, dateStatisticTemplateStructInner{Date: k, Number: answer[k], Special: strings.HasPrefix(string(k), "["), Percent: float64(answer[k]) / float64(td.Sum)})
+		special := strings.HasPrefix(string(k), "[")
+		suppressed := options.Suppressed(answer[k])
+		if suppressed && !special {
+			td.DatesSuppressed = true
+			continue
+		}
+		td.Data = append(td.Data, dateStatisticTemplateStructInner{Date: k, Number: answer[k], Special: special, Percent: weightAnswer[k] / sum, Suppressed: suppressed})
 	}
+	td.SumSuppressed = options.Suppressed(td.Sum)
 
 	sort.Sort(dateStatisticTemplateStructInnerSort(td.Data))
 
-	v := make([]helper.ChartValue, len(td.Data))
+	v := make([]helper.ChartValue, 0, len(td.Data))
 	for i := range td.Data {
-		v[i].Label = td.Data[i].Date
-		v[i].Value = float64(td.Data[i].Number)
+		if td.Data[i].Special {
+			continue
+		}
+		v = append(v, helper.ChartValue{Label: chartTime[td.Data[i].Date], Value: weightAnswer[td.Data[i].Date]})
 	}
 
-	td.Image = helper.BarChart(v, d.id, string(f.FormatClean([]byte(d.Question))))
+	displayFormat := ""
+	switch d.Aggregation {
+	case "week":
+		displayFormat = "GGGG-[W]WW"
+	case "month":
+		displayFormat = "YYYY-MM"
+	case "year":
+		displayFormat = "YYYY"
+	}
+	td.Image = helper.TimeChart(v, d.id, string(f.FormatClean([]byte(d.Question))), d.Aggregation, displayFormat)
 
 	output := bytes.NewBuffer(make([]byte, 0))
 	err := dateStatisticsTemplate.Execute(output, td)
@@ -205,11 +409,24 @@ func (d dateQuestion) GetStatisticsDisplay(data []string) template.HTML {
 func (d dateQuestion) ValidateInput(data map[string][]string) error {
 	if len(data[d.id]) >= 1 && data[d.id][0] != "" {
 		// Validate Date
-		_, err := time.Parse("2006-01-02", data[d.id][0])
-		if err == nil {
-			return nil
+		t, err := time.Parse(d.layout(), data[d.id][0])
+		if err != nil {
+			return fmt.Errorf("date: Can not parse date '%s'", data[d.id][0])
+		}
+		day := t.Format("2006-01-02")
+		min, max := d.bounds()
+		if min != "" && day < min {
+			return fmt.Errorf("date: Date '%s' before minimum %s", data[d.id][0], min)
+		}
+		if max != "" && day > max {
+			return fmt.Errorf("date: Date '%s' after maximum %s", data[d.id][0], max)
+		}
+		for i := range d.weekdays {
+			if int(t.Weekday()) == d.weekdays[i] {
+				return fmt.Errorf("date: Weekday of '%s' not allowed", data[d.id][0])
+			}
 		}
-		return fmt.Errorf("date: Can not parse date '%s'", data[d.id][0])
+		return nil
 	}
 	if d.Required {
 		return fmt.Errorf("date: Required, but no input found")
@@ -227,7 +444,7 @@ func (d dateQuestion) GetDatabaseEntry(data map[string][]string) string {
 			return ""
 		}
 		// Validate Date
-		_, err := time.Parse("2006-01-02", data[d.id][0])
+		_, err := time.Parse(d.layout(), data[d.id][0])
 		if err == nil {
 			return data[d.id][0]
 		}
@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020 Marcus Soll
+// Copyright 2020,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -85,6 +85,11 @@ func (d display) GetStatisticsDisplay(data []string) template.HTML {
 	return template.HTML(strings.Join([]string{"<div>", formatted, "</div>", "<p><em>Display has no results</em></p>"}, "\n"))
 }
 
+// GetStatisticsDisplayOptions returns the same as GetStatisticsDisplay, since display has no results.
+func (d display) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	return d.GetStatisticsDisplay(data)
+}
+
 func (d display) ValidateInput(data map[string][]string) error {
 	return nil
 }
@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2022 Marcus Soll
+// Copyright 2020,2022,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -79,8 +79,13 @@ var displayRandomGroupStatisticsTemplate = template.Must(template.New("displayRa
 {{range $i, $e := .Data }}
 <tr>
 <td>{{$e.Group}}</td>
+{{if $e.Suppressed}}
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+{{else}}
 <td>{{$e.Result}}</td>
 <td>{{printf "%.2f" $e.Percent}}</td>
+{{end}}
 </tr>
 {{end}}
 </tbody>
@@ -102,9 +107,10 @@ type displayRandomGroupStatisticsTemplateStruct struct {
 }
 
 type displayRandomGroupStatisticsTemplateStructInner struct {
-	Group   string
-	Result  int
-	Percent float64
+	Group      string
+	Result     int
+	Percent    float64
+	Suppressed bool
 }
 
 type displayRandomGroup struct {
@@ -149,14 +155,21 @@ func (drg displayRandomGroup) GetStatistics(data []string) [][]string {
 }
 
 func (drg displayRandomGroup) GetStatisticsDisplay(data []string) template.HTML {
-	count := 0
+	return drg.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted percentages if the options contain weights. Small counts are suppressed.
+func (drg displayRandomGroup) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	count := 0.0
 	countAnswer := make([]int, len(drg.Text))
+	weightAnswer := make([]float64, len(drg.Text))
 
 	for d := range data {
 		for i := range drg.Text {
 			if data[d] == drg.Text[i][0] {
 				countAnswer[i]++
-				count++
+				weightAnswer[i] += options.Weight(d)
+				count += options.Weight(d)
 				break
 			}
 		}
@@ -170,11 +183,15 @@ func (drg displayRandomGroup) GetStatisticsDisplay(data []string) template.HTML
 
 	for i := range drg.Text {
 		v[i].Label = string(drg.Text[i][0])
-		v[i].Value = float64(countAnswer[i])
+		v[i].Value = weightAnswer[i]
 		inner := displayRandomGroupStatisticsTemplateStructInner{
 			Group:   drg.Text[i][0],
 			Result:  countAnswer[i],
-			Percent: float64(countAnswer[i]) / float64(count),
+			Percent: weightAnswer[i] / count,
+		}
+		if options.Suppressed(countAnswer[i]) {
+			inner.Suppressed = true
+			v[i].Value = 0
 		}
 		td.Data = append(td.Data, inner)
 	}
@@ -189,6 +206,30 @@ func (drg displayRandomGroup) GetStatisticsDisplay(data []string) template.HTML
 	return template.HTML(output.Bytes())
 }
 
+// GetCategoryLabels returns the groups as categories.
+func (drg displayRandomGroup) GetCategoryLabels() []string {
+	labels := make([]string, len(drg.Text))
+	for i := range drg.Text {
+		labels[i] = drg.Text[i][0]
+	}
+	return labels
+}
+
+// GetCategories returns the index of the group of each entry.
+func (drg displayRandomGroup) GetCategories(data []string) []int {
+	result := make([]int, len(data))
+	for d := range data {
+		result[d] = -1
+		for i := range drg.Text {
+			if data[d] == drg.Text[i][0] {
+				result[d] = i
+				break
+			}
+		}
+	}
+	return result
+}
+
 func (drg displayRandomGroup) ValidateInput(data map[string][]string) error {
 	r, ok := data[drg.id]
 	if !ok || len(r) == 0 {
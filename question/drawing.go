@@ -0,0 +1,402 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package question
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/Top-Ranger/questiongo/registry"
+	"github.com/Top-Ranger/questiongo/translation"
+)
+
+func init() {
+	err := registry.RegisterQuestionType(FactoryDrawing, "drawing")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// FactoryDrawing is the factory for drawing questions (e.g. signatures).
+func FactoryDrawing(data []byte, id string, language string) (registry.Question, error) {
+	var d drawing
+	err := json.Unmarshal(data, &d)
+	if err != nil {
+		return nil, err
+	}
+	d.id = id
+
+	d.translation, err = translation.GetTranslation(language)
+	if err != nil {
+		return nil, fmt.Errorf("drawing: Can not get translation for language '%s' (%s)", language, id)
+	}
+
+	// Defaults
+	if d.Width == 0 {
+		d.Width = 600
+	}
+	if d.Height == 0 {
+		d.Height = 200
+	}
+	if d.StrokeWidth == 0 {
+		d.StrokeWidth = 2
+	}
+	if d.MaxSize == 0 {
+		d.MaxSize = 50000
+	}
+
+	if d.Width < 0 || d.Height < 0 || d.StrokeWidth < 0 || d.MaxSize < 0 {
+		return nil, fmt.Errorf("drawing: Width, Height, StrokeWidth and MaxSize must not be negative (%s)", id)
+	}
+
+	_, ok := registry.GetFormatType(d.Format)
+	if !ok {
+		return nil, fmt.Errorf("drawing: Unknown format type %s (%s)", d.Format, id)
+	}
+
+	return &d, nil
+}
+
+var drawingTemplate = template.Must(template.New("drawingTemplate").Parse(`{{.Question}}<br>
+<canvas id="{{.QID}}_canvas" width="{{.Width}}" height="{{.Height}}" style="border: 1px solid; max-width: 100%; touch-action: none; background-color: white;"></canvas><br>
+<button type="button" id="{{.QID}}_clear">{{.Translation.DrawingClear}}</button>
+<input type="text" id="{{.QID}}" name="{{.QID}}" value="" tabindex="-1" style="opacity: 0; width: 1px; height: 1px; border: 0; padding: 0;" {{if .Required}}required{{end}}>
+<script>
+{
+  let canvas = document.getElementById({{.QID}} + '_canvas');
+  let input = document.getElementById({{.QID}});
+  let ctx = canvas.getContext('2d');
+  ctx.lineWidth = {{.StrokeWidth}};
+  ctx.lineCap = 'round';
+  ctx.lineJoin = 'round';
+  let maxSize = {{.MaxSize}};
+  let strokes = [];
+  let stroke = null;
+  let last = null;
+
+  function position(event) {
+    let rect = canvas.getBoundingClientRect();
+    let x = Math.round((event.clientX - rect.left) * canvas.width / rect.width);
+    let y = Math.round((event.clientY - rect.top) * canvas.height / rect.height);
+    return [Math.min(Math.max(x, 0), canvas.width), Math.min(Math.max(y, 0), canvas.height)];
+  }
+
+  function update() {
+    // Relative coordinates keep the path data short
+    let path = strokes.join(' ');
+    if(path.length > maxSize) {
+      input.setCustomValidity({{.Translation.DrawingTooLarge}});
+    } else {
+      input.setCustomValidity('');
+    }
+    input.value = path;
+  }
+
+  canvas.addEventListener('pointerdown', function(event) {
+    event.preventDefault();
+    canvas.setPointerCapture(event.pointerId);
+    last = position(event);
+    stroke = 'M ' + last[0] + ' ' + last[1] + ' l 0 0';
+    ctx.beginPath();
+    ctx.moveTo(last[0], last[1]);
+    ctx.lineTo(last[0], last[1]);
+    ctx.stroke();
+  });
+
+  canvas.addEventListener('pointermove', function(event) {
+    if(stroke === null) {
+      return;
+    }
+    let p = position(event);
+    // Skip very small movements
+    if(Math.abs(p[0] - last[0]) + Math.abs(p[1] - last[1]) < 2) {
+      return;
+    }
+    stroke += ' ' + (p[0] - last[0]) + ' ' + (p[1] - last[1]);
+    ctx.beginPath();
+    ctx.moveTo(last[0], last[1]);
+    ctx.lineTo(p[0], p[1]);
+    ctx.stroke();
+    last = p;
+  });
+
+  function end() {
+    if(stroke === null) {
+      return;
+    }
+    strokes.push(stroke);
+    stroke = null;
+    update();
+  }
+  canvas.addEventListener('pointerup', end);
+  canvas.addEventListener('pointercancel', end);
+
+  document.getElementById({{.QID}} + '_clear').addEventListener('click', function() {
+    ctx.clearRect(0, 0, canvas.width, canvas.height);
+    strokes = [];
+    stroke = null;
+    update();
+  });
+}
+</script>
+`))
+
+var drawingStatisticsTemplate = template.Must(template.New("drawingStatisticsTemplate").Parse(`{{.Question}}<br>
+{{if .Suppressed}}
+<p>[drawings suppressed]</p>
+{{else}}
+<details>
+<summary>show results ({{len .Data}})</summary>
+<div style="display: flex; flex-wrap: wrap;">
+{{range $i, $e := .Data }}
+<figure style="margin: 0.5em;">
+<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 {{$.Width}} {{$.Height}}" width="200" style="border: 1px solid; background-color: white;"><path d="{{$e.Path}}" fill="none" stroke="black" stroke-width="{{$.StrokeWidth}}" stroke-linecap="round" stroke-linejoin="round"/></svg>
+<figcaption><small>#{{$e.Number}}</small></figcaption>
+</figure>
+{{end}}
+</div>
+</details>
+{{end}}
+`))
+
+// drawingSVGTemplate is used for the standalone SVG files of the zip export.
+// It uses text/template since the path data is validated and the output is not HTML.
+var drawingSVGTemplate = texttemplate.Must(texttemplate.New("drawingSVGTemplate").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}">
+<rect width="100%" height="100%" fill="white"/>
+<path d="{{.Path}}" fill="none" stroke="black" stroke-width="{{.StrokeWidth}}" stroke-linecap="round" stroke-linejoin="round"/>
+</svg>
+`))
+
+type drawingTemplateStruct struct {
+	Question    template.HTML
+	QID         string
+	Required    bool
+	Width       int
+	Height      int
+	StrokeWidth float64
+	MaxSize     int
+	Translation translation.Translation
+}
+
+type drawingStatisticsTemplateStructInner struct {
+	Number int
+	Path   string
+}
+
+type drawingStatisticsTemplateStruct struct {
+	Question    template.HTML
+	Width       int
+	Height      int
+	StrokeWidth float64
+	Data        []drawingStatisticsTemplateStructInner
+	Suppressed  bool
+}
+
+type drawingSVGTemplateStruct struct {
+	Width       int
+	Height      int
+	StrokeWidth float64
+	Path        string
+}
+
+type drawing struct {
+	Format      string
+	Question    string
+	Required    bool
+	Width       int
+	Height      int
+	StrokeWidth float64
+	MaxSize     int // maximum length of the path data in bytes
+
+	id          string
+	translation translation.Translation
+}
+
+// validatePath checks whether path only consists of strokes in the form "M x y l dx dy dx dy ...", which all stay inside the canvas.
+func (d drawing) validatePath(path string) error {
+	if len(path) > d.MaxSize {
+		return fmt.Errorf("path too large (%d > %d)", len(path), d.MaxSize)
+	}
+	token := strings.Fields(path)
+	x, y := 0, 0
+	for i := 0; i < len(token); {
+		if token[i] != "M" || i+3 >= len(token) || token[i+3] != "l" {
+			return fmt.Errorf("invalid stroke at token %d", i)
+		}
+		var err error
+		x, err = strconv.Atoi(token[i+1])
+		if err != nil {
+			return fmt.Errorf("invalid coordinate at token %d", i+1)
+		}
+		y, err = strconv.Atoi(token[i+2])
+		if err != nil {
+			return fmt.Errorf("invalid coordinate at token %d", i+2)
+		}
+		if x < 0 || x > d.Width || y < 0 || y > d.Height {
+			return fmt.Errorf("coordinate outside of canvas at token %d", i+1)
+		}
+		i += 4
+		points := 0
+		for i < len(token) && token[i] != "M" {
+			if i+1 >= len(token) {
+				return fmt.Errorf("incomplete coordinate at token %d", i)
+			}
+			dx, err := strconv.Atoi(token[i])
+			if err != nil {
+				return fmt.Errorf("invalid coordinate at token %d", i)
+			}
+			dy, err := strconv.Atoi(token[i+1])
+			if err != nil {
+				return fmt.Errorf("invalid coordinate at token %d", i+1)
+			}
+			x += dx
+			y += dy
+			if x < 0 || x > d.Width || y < 0 || y > d.Height {
+				return fmt.Errorf("coordinate outside of canvas at token %d", i)
+			}
+			i += 2
+			points++
+		}
+		if points == 0 {
+			return fmt.Errorf("stroke without points before token %d", i)
+		}
+	}
+	return nil
+}
+
+func (d drawing) GetID() string {
+	return d.id
+}
+
+func (d drawing) GetHTML() template.HTML {
+	f, _ := registry.GetFormatType(d.Format)
+
+	td := drawingTemplateStruct{
+		Question:    f.Format([]byte(d.Question)),
+		QID:         d.id,
+		Required:    d.Required,
+		Width:       d.Width,
+		Height:      d.Height,
+		StrokeWidth: d.StrokeWidth,
+		MaxSize:     d.MaxSize,
+		Translation: d.translation,
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := drawingTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("drawing: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (d drawing) GetStatisticsHeader() []string {
+	return []string{d.id}
+}
+
+func (d drawing) GetStatistics(data []string) [][]string {
+	result := make([][]string, len(data))
+	for i := range data {
+		result[i] = []string{data[i]}
+	}
+	return result
+}
+
+func (d drawing) GetStatisticsDisplay(data []string) template.HTML {
+	return d.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results. Like free text answers, the drawings are hidden if there are too few of them to protect the privacy of participants.
+// Weights are not used, since there is nothing to count.
+func (d drawing) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	f, _ := registry.GetFormatType(d.Format)
+
+	td := drawingStatisticsTemplateStruct{
+		Question:    f.Format([]byte(d.Question)),
+		Width:       d.Width,
+		Height:      d.Height,
+		StrokeWidth: d.StrokeWidth,
+		Data:        make([]drawingStatisticsTemplateStructInner, 0, len(data)),
+	}
+	for i := range data {
+		if data[i] == "" || d.validatePath(data[i]) != nil {
+			continue
+		}
+		td.Data = append(td.Data, drawingStatisticsTemplateStructInner{Number: i + 1, Path: data[i]})
+	}
+	td.Suppressed = options.Suppressed(len(td.Data))
+	if td.Suppressed {
+		td.Data = nil
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := drawingStatisticsTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("drawing: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (d drawing) ValidateInput(data map[string][]string) error {
+	if len(data[d.id]) == 0 || data[d.id][0] == "" {
+		if d.Required {
+			return fmt.Errorf("drawing (%s): Required, but no input found", d.id)
+		}
+		return nil
+	}
+	err := d.validatePath(data[d.id][0])
+	if err != nil {
+		return fmt.Errorf("drawing (%s): Invalid drawing: %w", d.id, err)
+	}
+	return nil
+}
+
+func (d drawing) IgnoreRecord(data map[string][]string) bool {
+	return false
+}
+
+func (d drawing) GetDatabaseEntry(data map[string][]string) string {
+	if len(data[d.id]) >= 1 {
+		return data[d.id][0]
+	}
+	return ""
+}
+
+// WriteZipFiles writes each drawing as an SVG file. The number in the file name is the number of the record in the unfiltered results.
+func (d drawing) WriteZipFiles(data []string, records []int, create func(name string) (io.Writer, error)) error {
+	for i := range data {
+		if data[i] == "" || d.validatePath(data[i]) != nil {
+			continue
+		}
+		w, err := create(fmt.Sprintf("%s_%d.svg", d.id, records[i]))
+		if err != nil {
+			return err
+		}
+		err = drawingSVGTemplate.Execute(w, drawingSVGTemplateStruct{Width: d.Width, Height: d.Height, StrokeWidth: d.StrokeWidth, Path: data[i]})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
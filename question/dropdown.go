@@ -0,0 +1,624 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package question
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Top-Ranger/questiongo/helper"
+	"github.com/Top-Ranger/questiongo/registry"
+	"github.com/Top-Ranger/questiongo/translation"
+)
+
+// dropdownOtherID is the answer id used for the "other" entry.
+const dropdownOtherID = "other"
+
+func init() {
+	err := registry.RegisterQuestionType(FactoryDropdown, "dropdown")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// FactoryDropdown is the factory for dropdown questions with long option lists.
+func FactoryDropdown(data []byte, id string, language string) (registry.Question, error) {
+	var d dropdown
+	err := json.Unmarshal(data, &d)
+	if err != nil {
+		return nil, err
+	}
+	d.id = id
+
+	d.translation, err = translation.GetTranslation(language)
+	if err != nil {
+		return nil, fmt.Errorf("dropdown: Can not get translation for language '%s' (%s)", language, id)
+	}
+
+	if d.TopN == 0 {
+		d.TopN = 10
+	}
+	if d.TopN < 0 {
+		return nil, fmt.Errorf("dropdown: TopN must not be negative (%s)", id)
+	}
+
+	if d.OptionsFile != "" {
+		if len(d.Options) != 0 {
+			return nil, fmt.Errorf("dropdown: Only one of Options and OptionsFile might be set (%s)", id)
+		}
+	} else {
+		err = d.checkOptions()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, ok := registry.GetFormatType(d.Format)
+	if !ok {
+		return nil, fmt.Errorf("dropdown: Unknown format type %s (%s)", d.Format, id)
+	}
+
+	return &d, nil
+}
+
+var dropdownTemplate = template.Must(template.New("dropdownTemplate").Parse(`<label for="{{.QID}}">{{.Question}}</label><br>
+{{if .Searchable}}
+<input type="search" id="{{.QID}}_search" placeholder="{{.Translation.DropdownSearch}}" title="{{.Translation.DropdownSearch}}" autocomplete="off"><br>
+{{end}}
+<select id="{{.QID}}" name="{{.QID}}" {{if .Required}}required{{end}}>
+<option value="">{{.Translation.DropdownSelect}}</option>
+{{range $i, $g := .Groups }}
+{{if $g.Name}}<optgroup label="{{$g.Name}}">{{end}}
+{{range $I, $e := $g.Options }}
+<option value="{{$e.ID}}">{{$e.Text}}</option>
+{{end}}
+{{if $g.Name}}</optgroup>{{end}}
+{{end}}
+{{if .Other}}
+<option value="{{.OtherID}}">{{.OtherText}}</option>
+{{end}}
+</select>
+{{if .Other}}
+<div id="{{.QID}}_other_div" hidden>
+<label for="{{.QID}}_other">{{.OtherText}}</label><br>
+<input type="text" id="{{.QID}}_other" name="{{.QID}}_other">
+</div>
+{{end}}
+<script>
+{
+  let select = document.getElementById({{.QID}});
+  {{if .Other}}
+  let other = document.getElementById({{.QID}} + '_other_div');
+  let otherInput = document.getElementById({{.QID}} + '_other');
+  select.addEventListener('change', function() {
+    other.hidden = select.value !== {{.OtherID}};
+    otherInput.required = {{.Required}} && !other.hidden;
+  });
+  {{end}}
+  {{if .Searchable}}
+  document.getElementById({{.QID}} + '_search').addEventListener('input', function(event) {
+    let search = event.target.value.toLowerCase();
+    let options = select.querySelectorAll('option');
+    let first = null;
+    for(let i = 0; i < options.length; i++) {
+      if(options[i].value === '') {
+        continue;
+      }
+      let show = search === '' || options[i].text.toLowerCase().includes(search) || options[i].value === {{.OtherID}};
+      options[i].hidden = !show;
+      options[i].disabled = !show;
+      if(show && first === null && options[i].value !== {{.OtherID}}) {
+        first = options[i];
+      }
+    }
+    let groups = select.querySelectorAll('optgroup');
+    for(let i = 0; i < groups.length; i++) {
+      groups[i].hidden = groups[i].querySelector('option:not([hidden])') === null;
+    }
+    // Select first match to allow picking by typing only
+    if(search !== '' && first !== null && (select.selectedOptions.length === 0 || select.selectedOptions[0].hidden || select.value === '')) {
+      select.value = first.value;
+      select.dispatchEvent(new Event('change'));
+    }
+  });
+  {{end}}
+}
+</script>
+`))
+
+var dropdownStatisticsTemplate = template.Must(template.New("dropdownStatisticsTemplate").Parse(`{{.Question}}<br>
+<table>
+<thead>
+<tr>
+<th>Option</th>
+<th>Answer (Number)</th>
+<th>Answer (percentage)</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Data }}
+<tr>
+<td>{{$e.Option}}</td>
+<td>{{$e.Number}}</td>
+<td>{{printf "%.2f" $e.Result}}</td>
+</tr>
+{{end}}
+{{if .Remainder}}
+<tr>
+<td>
+<details>
+<summary>[remaining options ({{len .Remainder}})]</summary>
+<ol>
+{{range $i, $e := .Remainder }}
+<li>{{$e.Option}}: {{$e.Number}} ({{printf "%.2f" $e.Result}})</li>
+{{end}}
+</ol>
+</details>
+</td>
+<td>{{.RemainderNumber}}</td>
+<td>{{printf "%.2f" .RemainderResult}}</td>
+</tr>
+{{end}}
+{{if .OptionsSuppressed}}
+<tr>
+<td class="th-cell">[options with small counts]</td>
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+</tr>
+{{end}}
+{{if .Other}}
+<tr>
+<td>{{.OtherText}}</td>
+{{if .OtherSuppressed}}
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+{{else}}
+<td>{{.OtherNumber}}</td>
+<td>{{printf "%.2f" .OtherResult}}</td>
+{{end}}
+</tr>
+{{end}}
+<tr>
+<td class="th-cell">[no answer]</td>
+{{if .NoAnswerSuppressed}}
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+{{else}}
+<td>{{.NoAnswer}}</td>
+<td>{{printf "%.2f" .NoAnswerResult}}</td>
+{{end}}
+</tr>
+</tbody>
+</table>
+<br>
+{{.Image}}
+{{if .Other}}
+<br>
+{{.OtherText}}
+{{if .OtherDataSuppressed}}
+<p>[text answers suppressed]</p>
+{{else}}
+<details>
+<summary>show results ({{len .OtherData}})</summary>
+<ol>
+{{range $i, $e := .OtherData }}
+<li>{{$e}}</li>
+{{end}}
+</ol>
+</details>
+{{end}}
+{{end}}
+`))
+
+type dropdownTemplateStructOption struct {
+	ID   string
+	Text template.HTML
+}
+
+type dropdownTemplateStructGroup struct {
+	Name    string
+	Options []dropdownTemplateStructOption
+}
+
+type dropdownTemplateStruct struct {
+	Question    template.HTML
+	QID         string
+	Required    bool
+	Searchable  bool
+	Groups      []dropdownTemplateStructGroup
+	Other       bool
+	OtherID     string
+	OtherText   string
+	Translation translation.Translation
+}
+
+type dropdownStatisticsTemplateStructInner struct {
+	Option template.HTML
+	Number int
+	Result float64
+	weight float64
+}
+
+type dropdownStatisticsTemplateStruct struct {
+	Question            template.HTML
+	Data                []dropdownStatisticsTemplateStructInner
+	Remainder           []dropdownStatisticsTemplateStructInner
+	RemainderNumber     int
+	RemainderResult     float64
+	OptionsSuppressed   bool
+	Other               bool
+	OtherText           string
+	OtherNumber         int
+	OtherResult         float64
+	OtherSuppressed     bool
+	OtherData           []string
+	OtherDataSuppressed bool
+	NoAnswer            int
+	NoAnswerResult      float64
+	NoAnswerSuppressed  bool
+	Image               template.HTML
+}
+
+type dropdownResult struct {
+	Answer string
+	Other  string `json:",omitempty"`
+}
+
+type dropdown struct {
+	Format      string
+	Question    string
+	Required    bool
+	Searchable  bool
+	Options     [][]string // id, text and optional group
+	OptionsFile string     // CSV file in the questionnaire folder with the same columns as Options
+	Other       bool
+	OtherText   string
+	TopN        int
+
+	id          string
+	translation translation.Translation
+}
+
+func (d dropdown) checkOptions() error {
+	if len(d.Options) == 0 {
+		return fmt.Errorf("dropdown: No options found (%s)", d.id)
+	}
+	testID := make(map[string]bool)
+	for i := range d.Options {
+		if len(d.Options[i]) != 2 && len(d.Options[i]) != 3 {
+			return fmt.Errorf("dropdown: Option %d must have 2 or 3 values (id, text, group) (%s)", i, d.id)
+		}
+		if d.Options[i][0] == "" {
+			return fmt.Errorf("dropdown: Option %d has an empty ID (%s)", i, d.id)
+		}
+		if testID[d.Options[i][0]] {
+			return fmt.Errorf("dropdown: ID %s found twice (%s)", d.Options[i][0], d.id)
+		}
+		if d.Other && d.Options[i][0] == dropdownOtherID {
+			return fmt.Errorf("dropdown: ID %s is reserved if Other is set (%s)", dropdownOtherID, d.id)
+		}
+		testID[d.Options[i][0]] = true
+	}
+	return nil
+}
+
+// LoadFolder reads the options from OptionsFile, if set.
+// Lines starting with '#' are ignored.
+func (d *dropdown) LoadFolder(path string) error {
+	if d.OptionsFile == "" {
+		return nil
+	}
+	f, err := os.Open(filepath.Join(path, d.OptionsFile))
+	if err != nil {
+		return fmt.Errorf("dropdown: Can not open options file: %w (%s)", err, d.id)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	d.Options, err = r.ReadAll()
+	if err != nil {
+		return fmt.Errorf("dropdown: Can not read options file: %w (%s)", err, d.id)
+	}
+	return d.checkOptions()
+}
+
+func (d dropdown) otherText() string {
+	if d.OtherText != "" {
+		return d.OtherText
+	}
+	return d.translation.DropdownOther
+}
+
+func (d dropdown) GetID() string {
+	return d.id
+}
+
+func (d dropdown) GetHTML() template.HTML {
+	f, _ := registry.GetFormatType(d.Format)
+
+	td := dropdownTemplateStruct{
+		Question:    f.Format([]byte(d.Question)),
+		QID:         d.id,
+		Required:    d.Required,
+		Searchable:  d.Searchable,
+		Groups:      make([]dropdownTemplateStructGroup, 0),
+		Other:       d.Other,
+		OtherID:     dropdownOtherID,
+		OtherText:   d.otherText(),
+		Translation: d.translation,
+	}
+
+	// Options of the same group are shown together, in order of their first appearance
+	groups := make(map[string]int)
+	for i := range d.Options {
+		group := ""
+		if len(d.Options[i]) == 3 {
+			group = d.Options[i][2]
+		}
+		g, ok := groups[group]
+		if !ok {
+			g = len(td.Groups)
+			groups[group] = g
+			td.Groups = append(td.Groups, dropdownTemplateStructGroup{Name: group})
+		}
+		td.Groups[g].Options = append(td.Groups[g].Options, dropdownTemplateStructOption{ID: d.Options[i][0], Text: f.FormatClean([]byte(d.Options[i][1]))})
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := dropdownTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("dropdown: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (d dropdown) GetStatisticsHeader() []string {
+	return []string{d.id, fmt.Sprintf("%s_other", d.id)}
+}
+
+func (d dropdown) GetStatistics(data []string) [][]string {
+	result := make([][]string, len(data))
+	for i := range data {
+		if data[i] == "" {
+			result[i] = []string{"", ""}
+			continue
+		}
+		var r dropdownResult
+		err := json.Unmarshal([]byte(data[i]), &r)
+		if err != nil {
+			result[i] = []string{"[ERROR]", "[ERROR]"}
+			continue
+		}
+		result[i] = []string{r.Answer, r.Other}
+	}
+	return result
+}
+
+func (d dropdown) GetStatisticsDisplay(data []string) template.HTML {
+	return d.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted percentages if the options contain weights. Small counts are suppressed.
+func (d dropdown) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	f, _ := registry.GetFormatType(d.Format)
+
+	td := dropdownStatisticsTemplateStruct{
+		Question:  f.Format([]byte(d.Question)),
+		Other:     d.Other,
+		OtherText: d.otherText(),
+		OtherData: make([]string, 0),
+	}
+
+	index := make(map[string]int, len(d.Options))
+	for i := range d.Options {
+		index[d.Options[i][0]] = i
+	}
+	count := make([]int, len(d.Options))
+	weight := make([]float64, len(d.Options))
+	total, otherWeight, noAnswerWeight, remainderWeight := 0.0, 0.0, 0.0, 0.0
+
+	for i := range data {
+		total += options.Weight(i)
+		if data[i] == "" {
+			td.NoAnswer++
+			noAnswerWeight += options.Weight(i)
+			continue
+		}
+		var r dropdownResult
+		err := json.Unmarshal([]byte(data[i]), &r)
+		if err != nil {
+			log.Printf("dropdown: Can not parse '%s':  %s (%s)", data[i], err.Error(), d.id)
+			td.NoAnswer++
+			noAnswerWeight += options.Weight(i)
+			continue
+		}
+		if d.Other && r.Answer == dropdownOtherID {
+			td.OtherNumber++
+			otherWeight += options.Weight(i)
+			if r.Other != "" {
+				td.OtherData = append(td.OtherData, r.Other)
+			}
+			continue
+		}
+		o, ok := index[r.Answer]
+		if !ok {
+			td.NoAnswer++
+			noAnswerWeight += options.Weight(i)
+			continue
+		}
+		count[o]++
+		weight[o] += options.Weight(i)
+	}
+
+	// Options, other and no answer together make up all answers
+	cells := make([]int, len(d.Options), len(d.Options)+2)
+	copy(cells, count)
+	cells = append(cells, td.OtherNumber, td.NoAnswer)
+	suppressed := options.SuppressedCells(cells)
+
+	all := make([]dropdownStatisticsTemplateStructInner, 0, len(d.Options))
+	for i := range d.Options {
+		if count[i] == 0 {
+			continue
+		}
+		if suppressed[i] {
+			td.OptionsSuppressed = true
+			continue
+		}
+		all = append(all, dropdownStatisticsTemplateStructInner{Option: f.FormatClean([]byte(d.Options[i][1])), Number: count[i], weight: weight[i]})
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].weight > all[j].weight
+	})
+	for i := range all {
+		all[i].Result = all[i].weight / total
+	}
+
+	if len(all) > d.TopN {
+		td.Data = all[:d.TopN]
+		td.Remainder = all[d.TopN:]
+		for i := range td.Remainder {
+			td.RemainderNumber += td.Remainder[i].Number
+			remainderWeight += td.Remainder[i].weight
+		}
+	} else {
+		td.Data = all
+	}
+	if total != 0 {
+		td.RemainderResult = remainderWeight / total
+		td.OtherResult = otherWeight / total
+		td.NoAnswerResult = noAnswerWeight / total
+	}
+	td.OtherSuppressed = suppressed[len(d.Options)]
+	td.OtherDataSuppressed = options.Suppressed(len(td.OtherData))
+	td.NoAnswerSuppressed = suppressed[len(d.Options)+1]
+
+	v := make([]helper.ChartValue, 0, len(td.Data)+3)
+	for i := range td.Data {
+		v = append(v, helper.ChartValue{Label: string(helper.SanitiseStringClean(string(td.Data[i].Option))), Value: td.Data[i].weight})
+	}
+	if td.Remainder != nil {
+		v = append(v, helper.ChartValue{Label: "[remaining options]", Value: remainderWeight})
+	}
+	if d.Other && !td.OtherSuppressed {
+		v = append(v, helper.ChartValue{Label: td.OtherText, Value: otherWeight})
+	}
+	if !td.NoAnswerSuppressed {
+		v = append(v, helper.ChartValue{Label: "[no answer]", Value: noAnswerWeight})
+	}
+	td.Image = helper.BarChart(v, d.id, string(f.FormatClean([]byte(d.Question))))
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := dropdownStatisticsTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("dropdown: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+// GetCategoryLabels returns the options as categories. If enabled, the other option is the last category.
+func (d dropdown) GetCategoryLabels() []string {
+	labels := make([]string, len(d.Options), len(d.Options)+1)
+	for i := range d.Options {
+		labels[i] = d.Options[i][1]
+	}
+	if d.Other {
+		labels = append(labels, d.otherText())
+	}
+	return labels
+}
+
+// GetCategories returns the index of the selected option of each entry.
+func (d dropdown) GetCategories(data []string) []int {
+	index := make(map[string]int, len(d.Options)+1)
+	for i := range d.Options {
+		index[d.Options[i][0]] = i
+	}
+	if d.Other {
+		index[dropdownOtherID] = len(d.Options)
+	}
+
+	result := make([]int, len(data))
+	for i := range data {
+		result[i] = -1
+		var r dropdownResult
+		err := json.Unmarshal([]byte(data[i]), &r)
+		if err != nil {
+			continue
+		}
+		o, ok := index[r.Answer]
+		if ok {
+			result[i] = o
+		}
+	}
+	return result
+}
+
+func (d dropdown) ValidateInput(data map[string][]string) error {
+	r := data[d.id]
+	if len(r) == 0 || r[0] == "" {
+		if d.Required {
+			return fmt.Errorf("dropdown (%s): Required, but no input found", d.id)
+		}
+		return nil
+	}
+	if len(r) != 1 {
+		return fmt.Errorf("dropdown (%s): Malformed input", d.id)
+	}
+	if d.Other && r[0] == dropdownOtherID {
+		if d.Required && (len(data[fmt.Sprintf("%s_other", d.id)]) == 0 || data[fmt.Sprintf("%s_other", d.id)][0] == "") {
+			return fmt.Errorf("dropdown (%s): Required, but no text for other found", d.id)
+		}
+		return nil
+	}
+	for i := range d.Options {
+		if r[0] == d.Options[i][0] {
+			return nil
+		}
+	}
+	return fmt.Errorf("dropdown (%s): Unknown id '%s'", d.id, r[0])
+}
+
+func (d dropdown) IgnoreRecord(data map[string][]string) bool {
+	return false
+}
+
+func (d dropdown) GetDatabaseEntry(data map[string][]string) string {
+	result := dropdownResult{}
+	if r := data[d.id]; len(r) == 1 {
+		result.Answer = r[0]
+	}
+	if d.Other && result.Answer == dropdownOtherID {
+		if r := data[fmt.Sprintf("%s_other", d.id)]; len(r) == 1 {
+			result.Other = r[0]
+		}
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Sprintf("ERROR: %s", err.Error())
+	}
+	return string(b)
+}
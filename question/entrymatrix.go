@@ -0,0 +1,454 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package question
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Top-Ranger/questiongo/registry"
+)
+
+const (
+	entryMatrixTypeText   = "text"
+	entryMatrixTypeNumber = "number"
+)
+
+func init() {
+	err := registry.RegisterQuestionType(FactoryEntryMatrix, "entry matrix")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// FactoryEntryMatrix is the factory for entry matrix questions.
+func FactoryEntryMatrix(data []byte, id string, language string) (registry.Question, error) {
+	var m entryMatrix
+	err := json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, err
+	}
+	m.id = id
+
+	// Sanity checks
+	testID := make(map[string]bool)
+	for i := range m.Columns {
+		if m.Columns[i].ID == "" {
+			return nil, fmt.Errorf("entrymatrix: Column %d has no ID (%s)", i, id)
+		}
+		if testID[m.Columns[i].ID] {
+			return nil, fmt.Errorf("entrymatrix: ID %s found twice (%s)", m.Columns[i].ID, id)
+		}
+		if strings.Contains(m.Columns[i].ID, "_") {
+			return nil, fmt.Errorf("entrymatrix: ID %s must not have '_' (%s)", m.Columns[i].ID, id)
+		}
+		testID[m.Columns[i].ID] = true
+
+		switch m.Columns[i].Type {
+		case entryMatrixTypeText:
+			if m.Columns[i].HasMinMax {
+				return nil, fmt.Errorf("entrymatrix: min / max can only be used with number columns (column %s) (%s)", m.Columns[i].ID, id)
+			}
+		case entryMatrixTypeNumber:
+			if m.Columns[i].HasMinMax && m.Columns[i].Max < m.Columns[i].Min {
+				return nil, fmt.Errorf("entrymatrix: max (%f) must be larger than min (%f) (column %s) (%s)", m.Columns[i].Max, m.Columns[i].Min, m.Columns[i].ID, id)
+			}
+		default:
+			return nil, fmt.Errorf("entrymatrix: Unknown column type '%s' (column %s) (%s)", m.Columns[i].Type, m.Columns[i].ID, id)
+		}
+	}
+
+	testID = make(map[string]bool)
+	for i := range m.Questions {
+		if len(m.Questions[i]) != 2 {
+			return nil, fmt.Errorf("entrymatrix: Question %d must have exactly 2 values (id, text) (%s)", i, id)
+		}
+		if testID[m.Questions[i][0]] {
+			return nil, fmt.Errorf("entrymatrix: ID %s found twice (%s)", m.Questions[i][0], id)
+		}
+		if strings.Contains(m.Questions[i][0], "_") {
+			return nil, fmt.Errorf("entrymatrix: ID %s must not have '_' (%s)", m.Questions[i][0], id)
+		}
+		testID[m.Questions[i][0]] = true
+	}
+
+	_, ok := registry.GetFormatType(m.Format)
+	if !ok {
+		return nil, fmt.Errorf("entrymatrix: Unknown format type %s (%s)", m.Format, id)
+	}
+
+	return &m, nil
+}
+
+var entryMatrixTemplate = template.Must(template.New("entryMatrixTemplate").Parse(`{{.Title}}<br>
+<table>
+<thead>
+<tr>
+<th></th>
+{{range $i, $e := .Header }}
+<th class="centre">{{$e}}</th>
+{{end}}
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Data }}
+<tr>
+<td>{{$e.Question}}</td>
+{{range $I, $E := $.Columns }}
+<td class="centre">{{if eq $E.Type "number"}}<input title="{{$e.Question}} - {{$E.Text}}" type="number" step="any" name="{{$.GID}}_{{$e.QID}}_{{$E.ID}}" {{if $E.HasMinMax}}min="{{$E.Min}}" max="{{$E.Max}}"{{end}} {{if $E.Required}}required{{end}}>{{else}}<input title="{{$e.Question}} - {{$E.Text}}" type="text" name="{{$.GID}}_{{$e.QID}}_{{$E.ID}}" {{if $E.Required}}required{{end}}>{{end}}</td>
+{{end}}
+</tr>
+{{end}}
+</tbody>
+</table>
+`))
+
+var entryMatrixStatisticsTemplate = template.Must(template.New("entryMatrixStatisticsTemplate").Parse(`{{.Title}}<br>
+<table>
+<thead>
+<tr>
+<th>Question</th>
+{{range $i, $e := .Header }}
+<th>{{$e}}</th>
+{{end}}
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Data }}
+<tr>
+<td>{{$e.Question}}</td>
+{{range $I, $E := $e.Result }}
+<td>{{if $E.Suppressed}}[suppressed]{{else}}{{if $E.Numeric}}&sum; {{printf "%.2f" $E.Sum}}<br>&empty; {{printf "%.2f" $E.Average}}<br>{{end}}<small>({{$E.Count}})</small>{{end}}</td>
+{{end}}
+</tr>
+{{end}}
+<tr>
+<td class="th-cell">[sum]</td>
+{{range $i, $e := .Total }}
+<td>{{if $e.Numeric}}{{if $e.Suppressed}}[suppressed]{{else}}{{printf "%.2f" $e.Sum}}{{end}}{{end}}</td>
+{{end}}
+</tr>
+<tr>
+<td class="th-cell">[average]</td>
+{{range $i, $e := .Total }}
+<td>{{if $e.Numeric}}{{if $e.Suppressed}}[suppressed]{{else}}{{printf "%.2f" $e.Average}}{{end}}{{end}}</td>
+{{end}}
+</tr>
+<tr>
+<td class="th-cell">[number answers]</td>
+<td>{{if .CountSuppressed}}[suppressed]{{else}}{{.Count}}{{end}}</td>
+</tr>
+<tr>
+<td class="th-cell">[invalid input]</td>
+<td>{{if .InvalidSuppressed}}[suppressed]{{else}}{{.Invalid}}{{end}}</td>
+</tr>
+</tbody>
+</table>
+`))
+
+type entryMatrixTemplateStructInner struct {
+	Question template.HTML
+	QID      string
+}
+
+type entryMatrixTemplateStructColumn struct {
+	ID        string
+	Text      string
+	Type      string
+	Required  bool
+	HasMinMax bool
+	Min       string
+	Max       string
+}
+
+type entryMatrixTemplateStruct struct {
+	Title   template.HTML
+	Header  []template.HTML
+	Data    []entryMatrixTemplateStructInner
+	Columns []entryMatrixTemplateStructColumn
+	GID     string
+}
+
+type entryMatrixStatisticsTemplateStructCell struct {
+	Numeric    bool
+	Count      int
+	Sum        float64
+	Average    float64
+	Suppressed bool
+
+	weight float64
+}
+
+type entryMatrixStatisticsTemplateStructInner struct {
+	Question template.HTML
+	Result   []entryMatrixStatisticsTemplateStructCell
+}
+
+type entryMatrixStatisticsTemplateStruct struct {
+	Title             template.HTML
+	Header            []template.HTML
+	Data              []entryMatrixStatisticsTemplateStructInner
+	Total             []entryMatrixStatisticsTemplateStructCell
+	Count             int
+	CountSuppressed   bool
+	Invalid           int
+	InvalidSuppressed bool
+}
+
+type entryMatrixColumn struct {
+	ID        string
+	Text      string
+	Type      string
+	Required  bool
+	HasMinMax bool
+	Min       float64
+	Max       float64
+}
+
+type entryMatrix struct {
+	Format    string
+	Title     string
+	Columns   []entryMatrixColumn
+	Questions [][]string
+
+	id string
+}
+
+func (m entryMatrix) parseResult(data string) ([][]string, bool) {
+	if data == "" || strings.HasPrefix(data, "ERROR") {
+		return nil, false
+	}
+	var result [][]string
+	err := json.Unmarshal([]byte(data), &result)
+	if err != nil || len(result) != len(m.Questions) {
+		return nil, false
+	}
+	for i := range result {
+		if len(result[i]) != len(m.Columns) {
+			return nil, false
+		}
+	}
+	return result, true
+}
+
+func (m entryMatrix) GetID() string {
+	return m.id
+}
+
+func (m entryMatrix) GetHTML() template.HTML {
+	f, _ := registry.GetFormatType(m.Format)
+	td := entryMatrixTemplateStruct{
+		Title:   f.Format([]byte(m.Title)),
+		Header:  make([]template.HTML, len(m.Columns)),
+		Data:    make([]entryMatrixTemplateStructInner, 0, len(m.Questions)),
+		Columns: make([]entryMatrixTemplateStructColumn, len(m.Columns)),
+		GID:     m.id,
+	}
+	for i := range m.Questions {
+		td.Data = append(td.Data, entryMatrixTemplateStructInner{
+			QID:      m.Questions[i][0],
+			Question: f.FormatClean([]byte(m.Questions[i][1])),
+		})
+	}
+	for i := range m.Columns {
+		td.Header[i] = f.Format([]byte(m.Columns[i].Text))
+		td.Columns[i] = entryMatrixTemplateStructColumn{
+			ID:        m.Columns[i].ID,
+			Text:      string(f.FormatClean([]byte(m.Columns[i].Text))),
+			Type:      m.Columns[i].Type,
+			Required:  m.Columns[i].Required,
+			HasMinMax: m.Columns[i].HasMinMax,
+			Min:       strconv.FormatFloat(m.Columns[i].Min, 'f', -1, 64),
+			Max:       strconv.FormatFloat(m.Columns[i].Max, 'f', -1, 64),
+		}
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := entryMatrixTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("entrymatrix: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (m entryMatrix) GetStatisticsHeader() []string {
+	header := make([]string, 0, len(m.Questions)*len(m.Columns))
+	for i := range m.Questions {
+		for j := range m.Columns {
+			header = append(header, fmt.Sprintf("%s_%s_%s", m.id, m.Questions[i][0], m.Columns[j].ID))
+		}
+	}
+	return header
+}
+
+func (m entryMatrix) GetStatistics(data []string) [][]string {
+	result := make([][]string, len(data))
+	for d := range data {
+		r := make([]string, 0, len(m.Questions)*len(m.Columns))
+		cells, ok := m.parseResult(data[d])
+		for i := range m.Questions {
+			for j := range m.Columns {
+				if !ok {
+					r = append(r, "error")
+					continue
+				}
+				r = append(r, cells[i][j])
+			}
+		}
+		result[d] = r
+	}
+	return result
+}
+
+func (m entryMatrix) GetStatisticsDisplay(data []string) template.HTML {
+	return m.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted sums and averages if the options contain weights. Cells with small counts are suppressed.
+func (m entryMatrix) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	f, _ := registry.GetFormatType(m.Format)
+	td := entryMatrixStatisticsTemplateStruct{
+		Title:  f.Format([]byte(m.Title)),
+		Header: make([]template.HTML, len(m.Columns)),
+		Data:   make([]entryMatrixStatisticsTemplateStructInner, len(m.Questions)),
+		Total:  make([]entryMatrixStatisticsTemplateStructCell, len(m.Columns)),
+	}
+
+	for j := range m.Columns {
+		td.Header[j] = f.FormatClean([]byte(m.Columns[j].Text))
+		td.Total[j].Numeric = m.Columns[j].Type == entryMatrixTypeNumber
+	}
+	for i := range m.Questions {
+		td.Data[i] = entryMatrixStatisticsTemplateStructInner{
+			Question: f.FormatClean([]byte(m.Questions[i][1])),
+			Result:   make([]entryMatrixStatisticsTemplateStructCell, len(m.Columns)),
+		}
+		for j := range m.Columns {
+			td.Data[i].Result[j].Numeric = td.Total[j].Numeric
+		}
+	}
+
+	for d := range data {
+		cells, ok := m.parseResult(data[d])
+		if !ok {
+			td.Invalid++
+			continue
+		}
+		td.Count++
+		for i := range cells {
+			for j := range cells[i] {
+				if cells[i][j] == "" {
+					continue
+				}
+				if !td.Total[j].Numeric {
+					td.Data[i].Result[j].Count++
+					continue
+				}
+				value, err := strconv.ParseFloat(cells[i][j], 64)
+				if err != nil {
+					continue
+				}
+				td.Data[i].Result[j].Count++
+				td.Data[i].Result[j].Sum += value * options.Weight(d)
+				td.Data[i].Result[j].weight += options.Weight(d)
+				td.Total[j].Count++
+				td.Total[j].Sum += value * options.Weight(d)
+				td.Total[j].weight += options.Weight(d)
+			}
+		}
+	}
+
+	for i := range td.Data {
+		for j := range td.Data[i].Result {
+			if td.Data[i].Result[j].Count != 0 && td.Data[i].Result[j].Numeric {
+				td.Data[i].Result[j].Average = td.Data[i].Result[j].Sum / td.Data[i].Result[j].weight
+			}
+			td.Data[i].Result[j].Suppressed = options.Suppressed(td.Data[i].Result[j].Count)
+			// The total would reveal the suppressed cell
+			if td.Data[i].Result[j].Suppressed {
+				td.Total[j].Suppressed = true
+			}
+		}
+	}
+	for j := range td.Total {
+		if td.Total[j].Count != 0 {
+			td.Total[j].Average = td.Total[j].Sum / td.Total[j].weight
+		}
+		td.Total[j].Suppressed = td.Total[j].Suppressed || options.Suppressed(td.Total[j].Count)
+	}
+	td.CountSuppressed = options.Suppressed(td.Count)
+	td.InvalidSuppressed = options.Suppressed(td.Invalid)
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := entryMatrixStatisticsTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("entrymatrix: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (m entryMatrix) ValidateInput(data map[string][]string) error {
+	for i := range m.Questions {
+		for j := range m.Columns {
+			key := fmt.Sprintf("%s_%s_%s", m.id, m.Questions[i][0], m.Columns[j].ID)
+			if len(data[key]) == 0 || data[key][0] == "" {
+				if m.Columns[j].Required {
+					return fmt.Errorf("entrymatrix (%s): No input found for %s", m.id, key)
+				}
+				continue
+			}
+			if m.Columns[j].Type != entryMatrixTypeNumber {
+				continue
+			}
+			value, err := strconv.ParseFloat(data[key][0], 64)
+			if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+				return fmt.Errorf("entrymatrix: Input '%s' malformed (%s)", data[key][0], key)
+			}
+			if m.Columns[j].HasMinMax && (value < m.Columns[j].Min || value > m.Columns[j].Max) {
+				return fmt.Errorf("entrymatrix: Input '%s' not in range (%s)", data[key][0], key)
+			}
+		}
+	}
+	return nil
+}
+
+func (m entryMatrix) IgnoreRecord(data map[string][]string) bool {
+	return false
+}
+
+func (m entryMatrix) GetDatabaseEntry(data map[string][]string) string {
+	result := make([][]string, len(m.Questions))
+	for i := range m.Questions {
+		result[i] = make([]string, len(m.Columns))
+		for j := range m.Columns {
+			key := fmt.Sprintf("%s_%s_%s", m.id, m.Questions[i][0], m.Columns[j].ID)
+			if len(data[key]) > 0 {
+				result[i][j] = data[key][0]
+			}
+		}
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Sprintf("ERROR: %s", err.Error())
+	}
+	return string(b)
+}
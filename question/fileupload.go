@@ -0,0 +1,498 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package question
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Top-Ranger/questiongo/helper"
+	"github.com/Top-Ranger/questiongo/registry"
+	"github.com/Top-Ranger/questiongo/translation"
+)
+
+func init() {
+	err := registry.RegisterQuestionType(FactoryFileUpload, "file upload")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// FactoryFileUpload is the factory for file upload questions.
+func FactoryFileUpload(data []byte, id string, language string) (registry.Question, error) {
+	var fu fileUpload
+	err := json.Unmarshal(data, &fu)
+	if err != nil {
+		return nil, err
+	}
+	fu.id = id
+
+	if fu.MaxSize <= 0 {
+		return nil, fmt.Errorf("file upload: MaxSize (%d) must be positive (%s)", fu.MaxSize, id)
+	}
+
+	if fu.MaxFiles < 0 {
+		return nil, fmt.Errorf("file upload: MaxFiles (%d) must not be negative (%s)", fu.MaxFiles, id)
+	}
+
+	if !fu.Multiple {
+		fu.MaxFiles = 1
+	}
+
+	for i := range fu.AllowedTypes {
+		t := strings.ToLower(strings.TrimSpace(fu.AllowedTypes[i]))
+		split := strings.Split(t, "/")
+		if len(split) != 2 || split[0] == "" || split[1] == "" || split[0] == "*" {
+			return nil, fmt.Errorf("file upload: Can not parse MIME type '%s' (%s)", fu.AllowedTypes[i], id)
+		}
+		fu.AllowedTypes[i] = t
+	}
+
+	_, ok := registry.GetFormatType(fu.Format)
+	if !ok {
+		return nil, fmt.Errorf("file upload: Unknown format type %s (%s)", fu.Format, id)
+	}
+
+	fu.translation, err = translation.GetTranslation(language)
+	if err != nil {
+		return nil, fmt.Errorf("file upload: Can not get translation for language '%s' (%s)", language, id)
+	}
+
+	return &fu, nil
+}
+
+var fileUploadTemplate = template.Must(template.New("fileUploadTemplate").Parse(`<label for="{{.QID}}">{{.Question}}</label><br>
+<input type="file" id="{{.QID}}" name="{{.QID}}" {{if .Accept}}accept="{{.Accept}}"{{end}} {{if .Multiple}}multiple{{end}} {{if .Required}}required{{end}} onchange="var m='';if({{.MaxFiles}}>0&&this.files.length>{{.MaxFiles}}){m={{.Translation.FileUploadTooManyFiles}}}for(var i=0;i<this.files.length;i++){if(this.files[i].size>{{.MaxSize}}){m={{.Translation.FileUploadFileTooLarge}}+' ('+this.files[i].name+')'}}this.setCustomValidity(m);this.reportValidity();">
+<p><em>{{.Translation.FileUploadMaximumSize}}: {{.MaxSizeText}}{{if .Multiple}}{{if gt .MaxFiles 0}} - {{.Translation.FileUploadMaximumFiles}}: {{.MaxFiles}}{{end}}{{end}}</em></p>
+`))
+
+var fileUploadStatisticsTemplate = template.Must(template.New("fileUploadStatisticsTemplate").Parse(`{{.Question}}<br>
+<table>
+<thead>
+<tr>
+<th>Type</th>
+<th>Number</th>
+<th>Percent</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Data }}
+<tr>
+<td>{{$e.Type}}</td>
+<td>{{$e.Number}}</td>
+<td>{{printf "%.2f" $e.Percent}}</td>
+</tr>
+{{end}}
+{{if .TypesSuppressed}}
+<tr>
+<td class="th-cell">[types with small counts]</td>
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+</tr>
+{{end}}
+<tr>
+<td class="th-cell">[answers with files]</td>
+<td>{{if .AnswersSuppressed}}[suppressed]{{else}}{{.Answers}}{{end}}</td>
+</tr>
+<tr>
+<td class="th-cell">[no files]</td>
+<td>{{if .NoAnswerSuppressed}}[suppressed]{{else}}{{.NoAnswer}}{{end}}</td>
+</tr>
+<tr>
+<td class="th-cell">[number files]</td>
+<td>{{if .FilesSuppressed}}[suppressed]{{else}}{{.Files}}{{end}}</td>
+</tr>
+<tr>
+<td class="th-cell">[total size]</td>
+<td>{{if .FilesSuppressed}}[suppressed]{{else}}{{.Size}}{{end}}</td>
+</tr>
+</tbody>
+</table>
+<br>
+{{.Image}}
+`))
+
+type fileUploadTemplateStruct struct {
+	Question    template.HTML
+	QID         string
+	Required    bool
+	Multiple    bool
+	MaxFiles    int
+	MaxSize     int64
+	MaxSizeText string
+	Accept      string
+	Translation translation.Translation
+}
+
+type fileUploadStatisticsTemplateStructInner struct {
+	Type    string
+	Number  int
+	Percent float64
+}
+
+type fileUploadStatisticsTemplateStruct struct {
+	Question           template.HTML
+	Data               []fileUploadStatisticsTemplateStructInner
+	TypesSuppressed    bool
+	Answers            int
+	AnswersSuppressed  bool
+	NoAnswer           int
+	NoAnswerSuppressed bool
+	Files              int
+	FilesSuppressed    bool
+	Size               string
+	Image              template.HTML
+}
+
+type fileUploadResult struct {
+	Name string
+	Blob string
+	Type string
+	Size int64
+}
+
+type fileUpload struct {
+	Format       string
+	Question     string
+	Required     bool
+	Multiple     bool
+	MaxFiles     int
+	MaxSize      int64
+	AllowedTypes []string
+
+	id          string
+	translation translation.Translation
+}
+
+func fileUploadFormatSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GiB", float64(size)/float64(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(size)/float64(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(size)/float64(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+// fileUploadDetectType returns the MIME type of the file based on its content.
+// The content type provided by the client is ignored since it can not be trusted.
+func fileUploadDetectType(fh *multipart.FileHeader) (string, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	b := make([]byte, 512)
+	n, err := io.ReadFull(f, b)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	t, _, err := mime.ParseMediaType(http.DetectContentType(b[:n]))
+	if err != nil {
+		return "", err
+	}
+	return t, nil
+}
+
+func (fu fileUpload) typeAllowed(t string) bool {
+	if len(fu.AllowedTypes) == 0 {
+		return true
+	}
+	for i := range fu.AllowedTypes {
+		if strings.HasSuffix(fu.AllowedTypes[i], "/*") {
+			if strings.HasPrefix(t, strings.TrimSuffix(fu.AllowedTypes[i], "*")) {
+				return true
+			}
+			continue
+		}
+		if t == fu.AllowedTypes[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func (fu fileUpload) parseResults(data string) ([]fileUploadResult, error) {
+	result := make([]fileUploadResult, 0)
+	if data == "" {
+		return result, nil
+	}
+	err := json.Unmarshal([]byte(data), &result)
+	return result, err
+}
+
+func (fu fileUpload) GetID() string {
+	return fu.id
+}
+
+func (fu fileUpload) GetHTML() template.HTML {
+	f, _ := registry.GetFormatType(fu.Format)
+
+	td := fileUploadTemplateStruct{
+		Question:    f.Format([]byte(fu.Question)),
+		QID:         fu.id,
+		Required:    fu.Required,
+		Multiple:    fu.Multiple,
+		MaxFiles:    fu.MaxFiles,
+		MaxSize:     fu.MaxSize,
+		MaxSizeText: fileUploadFormatSize(fu.MaxSize),
+		Accept:      strings.Join(fu.AllowedTypes, ","),
+		Translation: fu.translation,
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := fileUploadTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("file upload: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (fu fileUpload) GetStatisticsHeader() []string {
+	return []string{fu.id, fmt.Sprintf("%s_names", fu.id)}
+}
+
+func (fu fileUpload) GetStatistics(data []string) [][]string {
+	result := make([][]string, len(data))
+	for i := range data {
+		r, err := fu.parseResults(data[i])
+		if err != nil {
+			result[i] = []string{"[ERROR]", "[ERROR]"}
+			continue
+		}
+		blobs := make([]string, len(r))
+		names := make([]string, len(r))
+		for j := range r {
+			blobs[j] = r[j].Blob
+			names[j] = r[j].Name
+		}
+		result[i] = []string{strings.Join(blobs, "; "), strings.Join(names, "; ")}
+	}
+	return result
+}
+
+func (fu fileUpload) GetStatisticsDisplay(data []string) template.HTML {
+	return fu.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results. Small counts are suppressed.
+// Weights are not used, since files are counted instead of participants.
+func (fu fileUpload) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	f, _ := registry.GetFormatType(fu.Format)
+
+	td := fileUploadStatisticsTemplateStruct{
+		Question: f.Format([]byte(fu.Question)),
+	}
+
+	types := make(map[string]int)
+	var size int64
+
+	for i := range data {
+		r, err := fu.parseResults(data[i])
+		if err != nil {
+			log.Printf("file upload: Can not parse '%s':  %s (%s)", data[i], err.Error(), fu.id)
+			continue
+		}
+		if len(r) == 0 {
+			td.NoAnswer++
+			continue
+		}
+		td.Answers++
+		for j := range r {
+			td.Files++
+			size += r[j].Size
+			types[r[j].Type]++
+		}
+	}
+	td.Size = fileUploadFormatSize(size)
+
+	keys := make([]string, 0, len(types))
+	counts := make([]int, 0, len(types))
+	for k := range types {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		counts = append(counts, types[k])
+	}
+	suppressed := options.SuppressedCells(counts)
+	for i, k := range keys {
+		if suppressed[i] {
+			td.TypesSuppressed = true
+			continue
+		}
+		td.Data = append(td.Data, fileUploadStatisticsTemplateStructInner{Type: k, Number: types[k], Percent: float64(types[k]) / float64(td.Files)})
+	}
+	suppressed = options.SuppressedCells([]int{td.Answers, td.NoAnswer})
+	td.AnswersSuppressed = suppressed[0]
+	td.NoAnswerSuppressed = suppressed[1]
+	td.FilesSuppressed = options.Suppressed(td.Files)
+
+	v := make([]helper.ChartValue, len(td.Data))
+	for i := range td.Data {
+		v[i].Label = td.Data[i].Type
+		v[i].Value = float64(td.Data[i].Number)
+	}
+	td.Image = helper.PieChart(v, fu.id, string(f.FormatClean([]byte(fu.Question))))
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := fileUploadStatisticsTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("file upload: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (fu fileUpload) ValidateInput(data map[string][]string) error {
+	// Files are validated in ValidateFiles
+	return nil
+}
+
+func (fu fileUpload) ValidateFiles(files map[string][]*multipart.FileHeader) error {
+	fh := files[fu.id]
+	if len(fh) == 0 {
+		if fu.Required {
+			return fmt.Errorf("file upload: Required, but no file found")
+		}
+		return nil
+	}
+
+	if fu.MaxFiles > 0 && len(fh) > fu.MaxFiles {
+		return fmt.Errorf("file upload: %d files uploaded, only %d allowed", len(fh), fu.MaxFiles)
+	}
+
+	for i := range fh {
+		if fh[i].Size > fu.MaxSize {
+			return fmt.Errorf("file upload: File '%s' too large (%d > %d)", fh[i].Filename, fh[i].Size, fu.MaxSize)
+		}
+		t, err := fileUploadDetectType(fh[i])
+		if err != nil {
+			return fmt.Errorf("file upload: Can not read file '%s': %w", fh[i].Filename, err)
+		}
+		if !fu.typeAllowed(t) {
+			return fmt.Errorf("file upload: Type '%s' of file '%s' not allowed", t, fh[i].Filename)
+		}
+	}
+	return nil
+}
+
+func (fu fileUpload) SaveFiles(store registry.BlobStore, questionnaireID string, files map[string][]*multipart.FileHeader) (result map[string][]string, err error) {
+	fh := files[fu.id]
+	results := make([]fileUploadResult, 0, len(fh))
+
+	// Do not leave files of a failed upload behind
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := range results {
+			deleteErr := store.DeleteBlob(questionnaireID, results[i].Blob)
+			if deleteErr != nil {
+				log.Printf("file upload: Can not delete blob %s (%s): %s", results[i].Blob, fu.id, deleteErr.Error())
+			}
+		}
+	}()
+
+	if len(fh) != 0 && store == nil {
+		return nil, fmt.Errorf("file upload: No blob store available (%s)", fu.id)
+	}
+
+	for i := range fh {
+		t, err := fileUploadDetectType(fh[i])
+		if err != nil {
+			return nil, fmt.Errorf("file upload: Can not read file '%s': %w", fh[i].Filename, err)
+		}
+
+		random := make([]byte, 16)
+		_, err = rand.Read(random)
+		if err != nil {
+			return nil, fmt.Errorf("file upload: Can not get random: %w", err)
+		}
+
+		name := fmt.Sprintf("%s_%s", fu.id, hex.EncodeToString(random))
+		ext := strings.ToLower(filepath.Ext(filepath.Base(fh[i].Filename)))
+		if len(ext) > 1 && len(ext) <= 10 && strings.Trim(ext[1:], "abcdefghijklmnopqrstuvwxyz0123456789") == "" {
+			name = strings.Join([]string{name, ext}, "")
+		}
+
+		err = func() error {
+			f, err := fh[i].Open()
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return store.SaveBlob(questionnaireID, name, f)
+		}()
+		if err != nil {
+			return nil, fmt.Errorf("file upload: Can not save file '%s': %w", fh[i].Filename, err)
+		}
+
+		results = append(results, fileUploadResult{
+			Name: filepath.Base(fh[i].Filename),
+			Blob: name,
+			Type: t,
+			Size: fh[i].Size,
+		})
+	}
+
+	b, err := json.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]string{fmt.Sprintf("%s_stored", fu.id): {string(b)}}, nil
+}
+
+func (fu fileUpload) GetBlobs(data []string) []string {
+	blobs := make([]string, 0)
+	for i := range data {
+		r, err := fu.parseResults(data[i])
+		if err != nil {
+			continue
+		}
+		for j := range r {
+			blobs = append(blobs, r[j].Blob)
+		}
+	}
+	return blobs
+}
+
+func (fu fileUpload) IgnoreRecord(data map[string][]string) bool {
+	return false
+}
+
+func (fu fileUpload) GetDatabaseEntry(data map[string][]string) string {
+	r := data[fmt.Sprintf("%s_stored", fu.id)]
+	if len(r) == 0 || r[0] == "" {
+		return "[]"
+	}
+	return r[0]
+}
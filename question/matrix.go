@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020 Marcus Soll
+// Copyright 2020,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -22,6 +22,7 @@ import (
 	"html/template"
 	"log"
 	"math/rand"
+	"strconv"
 	"strings"
 
 	"github.com/Top-Ranger/questiongo/helper"
@@ -112,12 +113,14 @@ var matrixStatisticsTemplate = template.Must(template.New("matrixStatisticTempla
 <tr>
 <td>{{$e.Question}}</td>
 {{range $I, $E := $e.Result }}
-<td>{{printf "%.2f" $E}}</td>
+<td>{{if index $e.Suppressed $I}}[suppressed]{{else}}{{printf "%.2f" $E}}{{end}}</td>
 {{end}}
 </tr>
 {{end}}
 </tbody>
 </table>
+{{.Descriptive}}
+{{.BoxPlot}}
 {{.Image}}
 `))
 
@@ -136,15 +139,18 @@ type matrixTemplateStruct struct {
 }
 
 type matrixStatisticsTemplateStructInner struct {
-	Question template.HTML
-	Result   []float64
+	Question   template.HTML
+	Result     []float64
+	Suppressed []bool
 }
 
 type matrixStatisticTemplateStruct struct {
-	Title  template.HTML
-	Header []template.HTML
-	Data   []matrixStatisticsTemplateStructInner
-	Image  template.HTML
+	Title       template.HTML
+	Header      []template.HTML
+	Data        []matrixStatisticsTemplateStructInner
+	Descriptive template.HTML
+	BoxPlot     template.HTML
+	Image       template.HTML
 }
 
 type matrix struct {
@@ -237,12 +243,40 @@ func (m matrix) GetStatistics(data []string) [][]string {
 	return result
 }
 
+// numericAnswerIDs returns the numeric values of the answer IDs.
+// The bool is false if at least one ID is not a number.
+func numericAnswerIDs(ids []string) ([]float64, bool) {
+	result := make([]float64, len(ids))
+	for i := range ids {
+		v, err := strconv.ParseFloat(strings.TrimSpace(ids[i]), 64)
+		if err != nil {
+			return nil, false
+		}
+		result[i] = v
+	}
+	return result, len(ids) > 0
+}
+
 func (m matrix) GetStatisticsDisplay(data []string) template.HTML {
-	count := 0
-	countAnswer := make([][]int, len(m.Questions))
+	return m.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted percentages and statistics if the options contain weights. Small counts are suppressed.
+func (m matrix) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	count := 0.0
+	countAnswer := make([][]float64, len(m.Questions))
+	number := make([][]int, len(m.Questions))
 	for i := range m.Questions {
-		countAnswer[i] = make([]int, len(m.Answers)+1)
+		countAnswer[i] = make([]float64, len(m.Answers)+1)
+		number[i] = make([]int, len(m.Answers)+1)
+	}
+	answerIDs := make([]string, len(m.Answers))
+	for i := range m.Answers {
+		answerIDs[i] = m.Answers[i][0]
 	}
+	numeric, isNumeric := numericAnswerIDs(answerIDs)
+	values := make([][]float64, len(m.Questions))
+	weights := make([][]float64, len(m.Questions))
 
 	for d := range data {
 		rarray := make([]string, len(m.Questions))
@@ -254,19 +288,27 @@ func (m matrix) GetStatisticsDisplay(data []string) template.HTML {
 			fmt.Println(99999)
 			continue
 		}
-		count++
+		count += options.Weight(d)
 
 		for i := range m.Questions {
 			found := false
 			for j := range m.Answers {
 				if rarray[i] == m.Answers[j][0] {
-					countAnswer[i][j]++
+					countAnswer[i][j] += options.Weight(d)
+					number[i][j]++
+					if isNumeric {
+						values[i] = append(values[i], numeric[j])
+						if options.Weighted() {
+							weights[i] = append(weights[i], options.Weight(d))
+						}
+					}
 					found = true
 					break
 				}
 			}
 			if !found {
-				countAnswer[i][len(m.Answers)]++
+				countAnswer[i][len(m.Answers)] += options.Weight(d)
+				number[i][len(m.Answers)]++
 			}
 		}
 	}
@@ -285,26 +327,52 @@ func (m matrix) GetStatisticsDisplay(data []string) template.HTML {
 		labelValues[i] = string(td.Header[i])
 	}
 	td.Header[len(m.Answers)] = "[no answer]"
-	v := make([][]int, 0, len(m.Questions))
+	v := make([][]float64, 0, len(m.Questions))
 
 	for i := range m.Questions {
-		vinner := make([]int, len(m.Answers))
+		vinner := make([]float64, len(m.Answers))
 		question := f.FormatClean([]byte(m.Questions[i][1]))
 		inner := matrixStatisticsTemplateStructInner{
-			Question: question,
-			Result:   make([]float64, len(m.Answers)+1),
+			Question:   question,
+			Result:     make([]float64, len(m.Answers)+1),
+			Suppressed: make([]bool, len(m.Answers)+1),
 		}
 		labelBars[i] = string(question)
 		for j := range m.Answers {
-			inner.Result[j] = float64(countAnswer[i][j]) / float64(count)
-			vinner[j] = countAnswer[i][j]
+			inner.Result[j] = countAnswer[i][j] / count
+			inner.Suppressed[j] = options.Suppressed(number[i][j])
+			if !inner.Suppressed[j] {
+				vinner[j] = countAnswer[i][j]
+			}
 		}
-		inner.Result[len(m.Answers)] = float64(countAnswer[i][len(m.Answers)]) / float64(count)
+		inner.Result[len(m.Answers)] = countAnswer[i][len(m.Answers)] / count
+		inner.Suppressed[len(m.Answers)] = options.Suppressed(number[i][len(m.Answers)])
 
 		td.Data = append(td.Data, inner)
 		v = append(v, vinner)
 	}
 	td.Image = helper.Stacked100Chart(v, fmt.Sprintf("%s_bar", m.id), labelBars, labelValues, "")
+	if isNumeric {
+		labels := make([]string, len(m.Questions))
+		d := make([]helper.Descriptive, len(m.Questions))
+		for i := range m.Questions {
+			labels[i] = helper.StripTags(labelBars[i])
+			d[i] = helper.WeightedDescriptiveStatistics(values[i], weights[i])
+			if options.Suppressed(d[i].N) {
+				d[i] = helper.Descriptive{Suppressed: true}
+				continue
+			}
+			// Extremes and quartiles might be the value of a suppressed count
+			for _, s := range td.Data[i].Suppressed[:len(m.Answers)] {
+				if s {
+					d[i].ExtremesSuppressed = true
+					break
+				}
+			}
+		}
+		td.Descriptive = helper.DescriptiveTable(labels, d)
+		td.BoxPlot = helper.BoxPlot(labels, d)
+	}
 	output := bytes.NewBuffer(make([]byte, 0))
 	err := matrixStatisticsTemplate.Execute(output, td)
 	if err != nil {
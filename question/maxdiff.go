@@ -0,0 +1,632 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package question
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"html/template"
+	"log"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Top-Ranger/questiongo/helper"
+	"github.com/Top-Ranger/questiongo/registry"
+	"github.com/Top-Ranger/questiongo/translation"
+)
+
+// maxDiffDesignAttempts is the number of random designs generated, of which the best balanced one is kept.
+const maxDiffDesignAttempts = 50
+
+func init() {
+	err := registry.RegisterQuestionType(FactoryMaxDiff, "maxdiff")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// FactoryMaxDiff is the factory for MaxDiff (best-worst scaling) questions.
+func FactoryMaxDiff(data []byte, id string, language string) (registry.Question, error) {
+	var m maxDiff
+	err := json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, err
+	}
+	m.id = id
+
+	m.translation, err = translation.GetTranslation(language)
+	if err != nil {
+		return nil, fmt.Errorf("maxdiff: Can not get translation for language '%s' (%s)", language, id)
+	}
+
+	// Sanity checks
+	testID := make(map[string]bool)
+	for i := range m.Items {
+		if len(m.Items[i]) != 2 {
+			return nil, fmt.Errorf("maxdiff: Item %d must have exactly 2 values (id, text) (%s)", i, id)
+		}
+		if testID[m.Items[i][0]] {
+			return nil, fmt.Errorf("maxdiff: ID %s found twice (%s)", m.Items[i][0], id)
+		}
+		// The IDs are used in input names and CSS selectors
+		if m.Items[i][0] == "" || strings.ContainsAny(m.Items[i][0], "_\"'\\") {
+			return nil, fmt.Errorf("maxdiff: ID '%s' must not be empty or contain '_', quotes or '\\' (%s)", m.Items[i][0], id)
+		}
+		testID[m.Items[i][0]] = true
+	}
+
+	if m.ItemsPerSet < 2 {
+		return nil, fmt.Errorf("maxdiff: ItemsPerSet must be at least 2, is %d (%s)", m.ItemsPerSet, id)
+	}
+	if m.ItemsPerSet > len(m.Items) {
+		return nil, fmt.Errorf("maxdiff: ItemsPerSet (%d) must not be larger than the number of items (%d) (%s)", m.ItemsPerSet, len(m.Items), id)
+	}
+	if m.Sets == 0 {
+		// Show each item about three times
+		m.Sets = (3*len(m.Items) + m.ItemsPerSet - 1) / m.ItemsPerSet
+	}
+	if m.Sets < 1 {
+		return nil, fmt.Errorf("maxdiff: Sets must be at least 1, is %d (%s)", m.Sets, id)
+	}
+
+	_, ok := registry.GetFormatType(m.Format)
+	if !ok {
+		return nil, fmt.Errorf("maxdiff: Unknown format type %s (%s)", m.Format, id)
+	}
+
+	m.design = m.generateDesign()
+
+	return &m, nil
+}
+
+var maxDiffTemplate = template.Must(template.New("maxDiffTemplate").Parse(`{{.Question}}<br>
+{{range $i, $e := .Sets }}
+<p><em>{{printf $.Translation.MaxDiffSet $e.Number $.Total}}</em></p>
+<input type="hidden" name="{{$.QID}}_{{$e.SID}}_position" value="{{$e.Number}}">
+<table>
+<thead>
+<tr>
+<th class="centre">{{$.Best}}</th>
+<th></th>
+<th class="centre">{{$.Worst}}</th>
+</tr>
+</thead>
+<tbody>
+{{range $I, $E := $e.Items }}
+<tr>
+<td class="centre"><input type="radio" id="{{$.QID}}_{{$e.SID}}_best_{{$E.ID}}" name="{{$.QID}}_{{$e.SID}}_best" value="{{$E.ID}}" title="{{$.Best}}" {{if $.Required}}required{{end}}></td>
+<td>{{$E.Text}}</td>
+<td class="centre"><input type="radio" id="{{$.QID}}_{{$e.SID}}_worst_{{$E.ID}}" name="{{$.QID}}_{{$e.SID}}_worst" value="{{$E.ID}}" title="{{$.Worst}}" {{if $.Required}}required{{end}}></td>
+</tr>
+{{end}}
+</tbody>
+</table>
+{{end}}
+<script>
+{
+  let inputs = document.querySelectorAll('input[type="radio"][name^="{{.QID}}_"]');
+  for(let i = 0; i < inputs.length; i++) {
+    inputs[i].addEventListener('change', function(event) {
+      let other = event.target.name.endsWith('_best') ? event.target.name.slice(0, -5) + '_worst' : event.target.name.slice(0, -6) + '_best';
+      let o = document.querySelector('input[name="' + other + '"][value="' + event.target.value + '"]');
+      if(o !== null && o.checked) {
+        o.checked = false;
+      }
+    });
+  }
+}
+</script>
+`))
+
+var maxDiffStatisticsTemplate = template.Must(template.New("maxDiffStatisticsTemplate").Parse(`{{.Question}}<br>
+<table>
+<thead>
+<tr>
+<th>Item</th>
+<th>Shown</th>
+<th>Best</th>
+<th>Worst</th>
+<th>Best - Worst</th>
+<th>Score</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Data }}
+<tr>
+<td>{{$e.Item}}</td>
+<td>{{if $e.ShownSuppressed}}[suppressed]{{else}}{{$e.Shown}}{{end}}</td>
+<td>{{if $e.BestSuppressed}}[suppressed]{{else}}{{$e.Best}}{{end}}</td>
+<td>{{if $e.WorstSuppressed}}[suppressed]{{else}}{{$e.Worst}}{{end}}</td>
+{{if $e.ScoreSuppressed}}
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+{{else}}
+<td>{{$e.Difference}}</td>
+<td>{{printf "%.2f" $e.Score}}</td>
+{{end}}
+</tr>
+{{end}}
+<tr>
+<td class="th-cell">[number answers]</td>
+<td>{{if .CountSuppressed}}[suppressed]{{else}}{{.Count}}{{end}}</td>
+</tr>
+</tbody>
+</table>
+<p><small>Score = (Best - Worst) / Shown</small></p>
+{{.Image}}
+`))
+
+type maxDiffTemplateStructItem struct {
+	ID   string
+	Text template.HTML
+}
+
+type maxDiffTemplateStructSet struct {
+	SID    int
+	Number int
+	Items  []maxDiffTemplateStructItem
+}
+
+type maxDiffTemplateStruct struct {
+	Question    template.HTML
+	QID         string
+	Required    bool
+	Best        string
+	Worst       string
+	Total       int
+	Sets        []maxDiffTemplateStructSet
+	Translation translation.Translation
+}
+
+type maxDiffStatisticsTemplateStructInner struct {
+	Item            template.HTML
+	Shown           int
+	ShownSuppressed bool
+	Best            int
+	BestSuppressed  bool
+	Worst           int
+	WorstSuppressed bool
+	Difference      int
+	Score           float64
+	ScoreSuppressed bool
+
+	shownWeight float64
+	bestWeight  float64
+	worstWeight float64
+}
+
+type maxDiffStatisticsTemplateStruct struct {
+	Question        template.HTML
+	Data            []maxDiffStatisticsTemplateStructInner
+	Count           int
+	CountSuppressed bool
+	Image           template.HTML
+}
+
+// maxDiffAnswer is the answer to a single set.
+// Shown holds the IDs of the items of the set, so that the answer stays valid if the items are changed later.
+type maxDiffAnswer struct {
+	Best     string
+	Worst    string
+	Position int
+	Shown    []string
+}
+
+type maxDiff struct {
+	Format      string
+	Question    string
+	Required    bool
+	BestLabel   string
+	WorstLabel  string
+	Items       [][]string
+	ItemsPerSet int
+	Sets        int
+
+	id          string
+	translation translation.Translation
+	design      [][]int // [set][item index]
+}
+
+// generateDesign creates a balanced incomplete block design of the items.
+// Since an exact design does not exist for all parameters, multiple random designs are created and the most balanced one is used.
+// Each item appears equally often (up to one), and pairs of items are spread as evenly as possible.
+// The random source is seeded with the question definition, so the design is stable between restarts.
+func (m maxDiff) generateDesign() [][]int {
+	h := fnv.New64a()
+	h.Write([]byte(m.id))
+	for i := range m.Items {
+		h.Write([]byte(m.Items[i][0]))
+	}
+	fmt.Fprintf(h, "%d-%d", m.ItemsPerSet, m.Sets)
+	r := rand.New(rand.NewSource(int64(h.Sum64())))
+
+	var best [][]int
+	bestCost := -1
+	for attempt := 0; attempt < maxDiffDesignAttempts; attempt++ {
+		design := make([][]int, m.Sets)
+		count := make([]int, len(m.Items))
+		pairs := make([][]int, len(m.Items))
+		for i := range pairs {
+			pairs[i] = make([]int, len(m.Items))
+		}
+
+		for s := range design {
+			set := make([]int, 0, m.ItemsPerSet)
+			inSet := make([]bool, len(m.Items))
+			for len(set) < m.ItemsPerSet {
+				candidates := make([]int, 0, len(m.Items))
+				bestCandidate := -1
+				for i := range m.Items {
+					if inSet[i] {
+						continue
+					}
+					// Prefer rarely shown items, then rarely paired items
+					cost := count[i] * len(m.Items) * m.ItemsPerSet
+					for _, j := range set {
+						cost += pairs[i][j]
+					}
+					switch {
+					case bestCandidate == -1 || cost < bestCandidate:
+						bestCandidate = cost
+						candidates = candidates[:0]
+						candidates = append(candidates, i)
+					case cost == bestCandidate:
+						candidates = append(candidates, i)
+					}
+				}
+				choice := candidates[r.Intn(len(candidates))]
+				for _, j := range set {
+					pairs[choice][j]++
+					pairs[j][choice]++
+				}
+				set = append(set, choice)
+				inSet[choice] = true
+				count[choice]++
+			}
+			design[s] = set
+		}
+
+		// Improve by exchanging items between sets (keeps the item frequencies)
+		improved := true
+		for improved {
+			improved = false
+			for s1 := range design {
+				for s2 := s1 + 1; s2 < len(design); s2++ {
+					for i1 := range design[s1] {
+						for i2 := range design[s2] {
+							a, b := design[s1][i1], design[s2][i2]
+							if maxDiffContains(design[s2], a) || maxDiffContains(design[s1], b) {
+								continue
+							}
+							// Pair changes of the exchange, shared items cancel each other out
+							change := make(map[int][2]int)
+							for _, x := range design[s1] {
+								if x != a {
+									c := change[x]
+									c[0]--
+									c[1]++
+									change[x] = c
+								}
+							}
+							for _, y := range design[s2] {
+								if y != b {
+									c := change[y]
+									c[0]++
+									c[1]--
+									change[y] = c
+								}
+							}
+							delta := 0
+							for x, c := range change {
+								delta += (pairs[a][x]+c[0])*(pairs[a][x]+c[0]) - pairs[a][x]*pairs[a][x]
+								delta += (pairs[b][x]+c[1])*(pairs[b][x]+c[1]) - pairs[b][x]*pairs[b][x]
+							}
+							if delta >= 0 {
+								continue
+							}
+							for x, c := range change {
+								pairs[a][x] += c[0]
+								pairs[x][a] += c[0]
+								pairs[b][x] += c[1]
+								pairs[x][b] += c[1]
+							}
+							design[s1][i1], design[s2][i2] = b, a
+							improved = true
+						}
+					}
+				}
+			}
+		}
+
+		// Cost: squared pair frequencies (minimal if pairs are evenly spread)
+		cost := 0
+		for i := range pairs {
+			for j := i + 1; j < len(pairs); j++ {
+				cost += pairs[i][j] * pairs[i][j]
+			}
+		}
+		if bestCost == -1 || cost < bestCost {
+			bestCost = cost
+			best = design
+		}
+	}
+	for s := range best {
+		sort.Ints(best[s])
+	}
+	return best
+}
+
+func maxDiffContains(set []int, item int) bool {
+	for i := range set {
+		if set[i] == item {
+			return true
+		}
+	}
+	return false
+}
+
+func (m maxDiff) parseResult(data string) ([]maxDiffAnswer, bool) {
+	if data == "" || strings.HasPrefix(data, "ERROR") {
+		return nil, false
+	}
+	var result []maxDiffAnswer
+	err := json.Unmarshal([]byte(data), &result)
+	if err != nil {
+		return nil, false
+	}
+	return result, true
+}
+
+func (m maxDiff) GetID() string {
+	return m.id
+}
+
+func (m maxDiff) GetHTML() template.HTML {
+	f, _ := registry.GetFormatType(m.Format)
+
+	td := maxDiffTemplateStruct{
+		Question:    f.Format([]byte(m.Question)),
+		QID:         m.id,
+		Required:    m.Required,
+		Best:        m.BestLabel,
+		Worst:       m.WorstLabel,
+		Total:       len(m.design),
+		Sets:        make([]maxDiffTemplateStructSet, len(m.design)),
+		Translation: m.translation,
+	}
+	if td.Best == "" {
+		td.Best = m.translation.MaxDiffBest
+	}
+	if td.Worst == "" {
+		td.Worst = m.translation.MaxDiffWorst
+	}
+
+	for s := range m.design {
+		td.Sets[s].SID = s + 1
+		td.Sets[s].Items = make([]maxDiffTemplateStructItem, len(m.design[s]))
+		for i, item := range m.design[s] {
+			td.Sets[s].Items[i] = maxDiffTemplateStructItem{ID: m.Items[item][0], Text: f.FormatClean([]byte(m.Items[item][1]))}
+		}
+		rand.Shuffle(len(td.Sets[s].Items), func(i, j int) {
+			td.Sets[s].Items[i], td.Sets[s].Items[j] = td.Sets[s].Items[j], td.Sets[s].Items[i]
+		})
+	}
+	rand.Shuffle(len(td.Sets), func(i, j int) {
+		td.Sets[i], td.Sets[j] = td.Sets[j], td.Sets[i]
+	})
+	for s := range td.Sets {
+		td.Sets[s].Number = s + 1
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := maxDiffTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("maxdiff: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (m maxDiff) GetStatisticsHeader() []string {
+	header := make([]string, 0, 4*len(m.design))
+	for s := range m.design {
+		header = append(header, fmt.Sprintf("%s_%d_best", m.id, s+1), fmt.Sprintf("%s_%d_worst", m.id, s+1), fmt.Sprintf("%s_%d_position", m.id, s+1), fmt.Sprintf("%s_%d_shown", m.id, s+1))
+	}
+	return header
+}
+
+func (m maxDiff) GetStatistics(data []string) [][]string {
+	result := make([][]string, len(data))
+	for d := range data {
+		r := make([]string, 0, 4*len(m.design))
+		answers, ok := m.parseResult(data[d])
+		ok = ok && len(answers) == len(m.design)
+		for s := range m.design {
+			if !ok {
+				r = append(r, "error", "error", "error", "error")
+				continue
+			}
+			position := ""
+			if answers[s].Position > 0 {
+				position = strconv.Itoa(answers[s].Position)
+			}
+			r = append(r, answers[s].Best, answers[s].Worst, position, strings.Join(answers[s].Shown, "; "))
+		}
+		result[d] = r
+	}
+	return result
+}
+
+func (m maxDiff) GetStatisticsDisplay(data []string) template.HTML {
+	return m.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted scores if the options contain weights.
+// Small counts are suppressed. Since the score reveals best and worst, it is suppressed together with them.
+func (m maxDiff) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	f, _ := registry.GetFormatType(m.Format)
+
+	index := make(map[string]int, len(m.Items))
+	for i := range m.Items {
+		index[m.Items[i][0]] = i
+	}
+
+	td := maxDiffStatisticsTemplateStruct{
+		Question: f.Format([]byte(m.Question)),
+		Data:     make([]maxDiffStatisticsTemplateStructInner, len(m.Items)),
+	}
+	for i := range m.Items {
+		td.Data[i].Item = f.FormatClean([]byte(m.Items[i][1]))
+	}
+
+	for d := range data {
+		answers, ok := m.parseResult(data[d])
+		if !ok {
+			continue
+		}
+		td.Count++
+		for s := range answers {
+			// Only complete sets count, otherwise the shown items would be biased towards a missing best or worst
+			if answers[s].Best == "" || answers[s].Worst == "" {
+				continue
+			}
+			for _, item := range answers[s].Shown {
+				if i, ok := index[item]; ok {
+					td.Data[i].Shown++
+					td.Data[i].shownWeight += options.Weight(d)
+				}
+			}
+			if i, ok := index[answers[s].Best]; ok {
+				td.Data[i].Best++
+				td.Data[i].bestWeight += options.Weight(d)
+			}
+			if i, ok := index[answers[s].Worst]; ok {
+				td.Data[i].Worst++
+				td.Data[i].worstWeight += options.Weight(d)
+			}
+		}
+	}
+
+	for i := range td.Data {
+		td.Data[i].Difference = td.Data[i].Best - td.Data[i].Worst
+		if td.Data[i].Shown != 0 {
+			td.Data[i].Score = (td.Data[i].bestWeight - td.Data[i].worstWeight) / td.Data[i].shownWeight
+		}
+		td.Data[i].ShownSuppressed = options.Suppressed(td.Data[i].Shown)
+		td.Data[i].BestSuppressed = options.Suppressed(td.Data[i].Best)
+		td.Data[i].WorstSuppressed = options.Suppressed(td.Data[i].Worst)
+		td.Data[i].ScoreSuppressed = td.Data[i].ShownSuppressed || td.Data[i].BestSuppressed || td.Data[i].WorstSuppressed
+	}
+	td.CountSuppressed = options.Suppressed(td.Count)
+	sort.SliceStable(td.Data, func(i, j int) bool {
+		// Suppressed scores go last, so that their position does not reveal them
+		if td.Data[i].ScoreSuppressed != td.Data[j].ScoreSuppressed {
+			return td.Data[j].ScoreSuppressed
+		}
+		return td.Data[i].Score > td.Data[j].Score
+	})
+
+	v := make([]helper.ChartValue, 0, len(td.Data))
+	for i := range td.Data {
+		if td.Data[i].ScoreSuppressed {
+			continue
+		}
+		v = append(v, helper.ChartValue{Label: string(td.Data[i].Item), Value: td.Data[i].Score})
+	}
+	td.Image = helper.BarChart(v, m.id, string(f.FormatClean([]byte(m.Question))))
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := maxDiffStatisticsTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("maxdiff: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (m maxDiff) ValidateInput(data map[string][]string) error {
+	for s := range m.design {
+		best := ""
+		worst := ""
+		if v := data[fmt.Sprintf("%s_%d_best", m.id, s+1)]; len(v) > 0 {
+			best = v[0]
+		}
+		if v := data[fmt.Sprintf("%s_%d_worst", m.id, s+1)]; len(v) > 0 {
+			worst = v[0]
+		}
+
+		if best == "" || worst == "" {
+			if m.Required {
+				return fmt.Errorf("maxdiff (%s): No input found for set %d", m.id, s+1)
+			}
+			if best == "" && worst == "" {
+				continue
+			}
+		}
+		if best != "" && best == worst {
+			return fmt.Errorf("maxdiff (%s): Best and worst must be different in set %d", m.id, s+1)
+		}
+		for _, answer := range []string{best, worst} {
+			if answer == "" {
+				continue
+			}
+			found := false
+			for _, item := range m.design[s] {
+				if m.Items[item][0] == answer {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("maxdiff (%s): Unknown item '%s' in set %d", m.id, answer, s+1)
+			}
+		}
+	}
+	return nil
+}
+
+func (m maxDiff) IgnoreRecord(data map[string][]string) bool {
+	return false
+}
+
+func (m maxDiff) GetDatabaseEntry(data map[string][]string) string {
+	result := make([]maxDiffAnswer, len(m.design))
+	for s := range m.design {
+		result[s].Shown = make([]string, len(m.design[s]))
+		for i, item := range m.design[s] {
+			result[s].Shown[i] = m.Items[item][0]
+		}
+		if v := data[fmt.Sprintf("%s_%d_best", m.id, s+1)]; len(v) > 0 {
+			result[s].Best = v[0]
+		}
+		if v := data[fmt.Sprintf("%s_%d_worst", m.id, s+1)]; len(v) > 0 {
+			result[s].Worst = v[0]
+		}
+		if v := data[fmt.Sprintf("%s_%d_position", m.id, s+1)]; len(v) > 0 {
+			p, err := strconv.Atoi(v[0])
+			if err == nil && p > 0 && p <= len(m.design) {
+				result[s].Position = p
+			}
+		}
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Sprintf("ERROR: %s", err.Error())
+	}
+	return string(b)
+}
@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2022 Marcus Soll
+// Copyright 2020,2022,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -82,13 +82,18 @@ var multiplechoiceStatisticsTemplate = template.Must(template.New("multiplechoic
 {{range $i, $e := .Data }}
 <tr>
 <td>{{$e.Question}}</td>
+{{if $e.Suppressed}}
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+{{else}}
 <td>{{$e.Result}}</td>
 <td>{{printf "%.2f" $e.Percent}}</td>
+{{end}}
 </tr>
 {{end}}
 <tr>
 <td class="th-cell">[number answers]</td>
-<td>{{.Sum}}</td>
+<td>{{if .SumSuppressed}}[suppressed]{{else}}{{.Sum}}{{end}}</td>
 </tr>
 </tbody>
 </table>
@@ -103,16 +108,18 @@ type multiplechoiceTemplateStructInner struct {
 }
 
 type multiplechoiceStatisticTemplateStruct struct {
-	Question template.HTML
-	Sum      int
-	Data     []multiplechoiceStatisticsTemplateStructInner
-	Image    template.HTML
+	Question      template.HTML
+	Sum           int
+	SumSuppressed bool
+	Data          []multiplechoiceStatisticsTemplateStructInner
+	Image         template.HTML
 }
 
 type multiplechoiceStatisticsTemplateStructInner struct {
-	Question template.HTML
-	Result   int
-	Percent  float64
+	Question   template.HTML
+	Result     int
+	Percent    float64
+	Suppressed bool
 }
 
 type multiplechoiceTemplateStruct struct {
@@ -201,8 +208,15 @@ func (mc multipleChoice) GetStatistics(data []string) [][]string {
 }
 
 func (mc multipleChoice) GetStatisticsDisplay(data []string) template.HTML {
+	return mc.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted percentages if the options contain weights. Small counts are suppressed.
+func (mc multipleChoice) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
 	count := 0
 	countAnswer := make([]int, len(mc.Answers))
+	weightAnswer := make([]float64, len(mc.Answers))
+	total := 0.0
 
 	for d := range data {
 		boolarray := make([]bool, len(mc.Answers))
@@ -214,28 +228,36 @@ func (mc multipleChoice) GetStatisticsDisplay(data []string) template.HTML {
 			continue
 		}
 		count++
+		total += options.Weight(d)
 		for i := range mc.Answers {
 			if boolarray[i] {
 				countAnswer[i]++
+				weightAnswer[i] += options.Weight(d)
 			}
 		}
 	}
 
 	f, _ := registry.GetFormatType(mc.Format)
 	td := multiplechoiceStatisticTemplateStruct{
-		Question: f.Format([]byte(mc.Question)),
-		Sum:      count,
-		Data:     make([]multiplechoiceStatisticsTemplateStructInner, 0, len(mc.Answers)),
+		Question:      f.Format([]byte(mc.Question)),
+		Sum:           count,
+		SumSuppressed: options.Suppressed(count),
+		Data:          make([]multiplechoiceStatisticsTemplateStructInner, 0, len(mc.Answers)),
 	}
 	v := make([]helper.ChartValue, len(mc.Answers))
 	for i := range mc.Answers {
 		question := f.FormatClean([]byte(mc.Answers[i][1]))
 		v[i].Label = string(question)
-		v[i].Value = float64(countAnswer[i])
+		v[i].Value = weightAnswer[i]
 		inner := multiplechoiceStatisticsTemplateStructInner{
 			Question: question,
 			Result:   countAnswer[i],
-			Percent:  float64(countAnswer[i]) / float64(td.Sum),
+			Percent:  weightAnswer[i] / total,
+		}
+		// The number of participants not choosing the answer follows from the sum, so it must not be small either
+		if options.Suppressed(countAnswer[i]) || options.Suppressed(count-countAnswer[i]) {
+			inner.Suppressed = true
+			v[i].Value = 0
 		}
 		td.Data = append(td.Data, inner)
 	}
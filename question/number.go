@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2021,2022 Marcus Soll
+// Copyright 2020,2021,2022,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"html/template"
 	"log"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
@@ -65,6 +66,10 @@ func FactoryNumber(data []byte, id string, language string) (registry.Question,
 		}
 	}
 
+	if n.HistogramBins < 0 {
+		return nil, fmt.Errorf("number: histogram bins (%d) must not be negative (%s)", n.HistogramBins, id)
+	}
+
 	_, ok := registry.GetFormatType(n.Format)
 	if !ok {
 		return nil, fmt.Errorf("number: Unknown format type %s (%s)", n.Format, id)
@@ -94,25 +99,30 @@ var numberStatisticsTemplate = template.Must(template.New("numberStatisticTempla
 <td>{{printf "%.2f" $e.Percent}}</td>
 </tr>
 {{end}}
+{{if .ValuesSuppressed}}
 <tr>
-<td class="th-cell">[average]</td>
-<td>{{printf "%.2f" .Average}}</td>
+<td class="th-cell">[values with small counts]</td>
+<td>[suppressed]</td>
+<td>[suppressed]</td>
 </tr>
+{{end}}
 <tr>
 <td class="th-cell">[number answer]</td>
-<td>{{.Count}}</td>
+<td>{{if .CountSuppressed}}[suppressed]{{else}}{{.Count}}{{end}}</td>
 </tr>
 <tr>
 <td class="th-cell">[no answer]</td>
-<td>{{.NoAnswer}}</td>
+<td>{{if .NoAnswerSuppressed}}[suppressed]{{else}}{{.NoAnswer}}{{end}}</td>
 </tr>
 <tr>
 <td class="th-cell">[invalid input]</td>
-<td>{{.Invalid}}</td>
+<td>{{if .InvalidSuppressed}}[suppressed]{{else}}{{.Invalid}}{{end}}</td>
 </tr>
 </tbody>
 </table>
 <br>
+{{.Descriptive}}
+{{.BoxPlot}}
 {{.Image}}
 `))
 
@@ -134,13 +144,18 @@ type numberStatisticTemplateStructInner struct {
 }
 
 type numberStatisticTemplateStruct struct {
-	Question template.HTML
-	Data     []numberStatisticTemplateStructInner
-	Average  float64
-	Count    int
-	Invalid  int
-	NoAnswer int
-	Image    template.HTML
+	Question           template.HTML
+	Data               []numberStatisticTemplateStructInner
+	ValuesSuppressed   bool
+	Count              int
+	CountSuppressed    bool
+	Invalid            int
+	InvalidSuppressed  bool
+	NoAnswer           int
+	NoAnswerSuppressed bool
+	Descriptive        template.HTML
+	BoxPlot            template.HTML
+	Image              template.HTML
 }
 type numberStatisticTemplateStructInnerSort []numberStatisticTemplateStructInner
 
@@ -169,6 +184,7 @@ type numberQuestion struct {
 	IgoreRecordUpperBound   int
 	IgoreRecordIfLowerThan  bool
 	IgoreRecordLowerBound   int
+	HistogramBins           int
 
 	id string
 }
@@ -225,21 +241,30 @@ func (n numberQuestion) GetStatistics(data []string) [][]string {
 }
 
 func (n numberQuestion) GetStatisticsDisplay(data []string) template.HTML {
+	return n.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted percentages and statistics if the options contain weights. Small counts are suppressed.
+func (n numberQuestion) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
 	f, _ := registry.GetFormatType(n.Format)
 
 	td := numberStatisticTemplateStruct{
 		Question: f.Format([]byte(n.Question)),
-		Average:  0.0,
 		Count:    0,
 		Invalid:  0,
 		NoAnswer: 0,
 	}
 
 	answer := make(map[int]int)
+	weightAnswer := make(map[int]float64)
+	values := make([]float64, 0, len(data))
+	var weights []float64
+	sum, noAnswer := 0.0, 0.0
 
 	for i := range data {
 		if data[i] == "" {
 			td.NoAnswer++
+			noAnswer += options.Weight(i)
 			continue
 		}
 		value, err := strconv.Atoi(data[i])
@@ -248,29 +273,55 @@ func (n numberQuestion) GetStatisticsDisplay(data []string) template.HTML {
 		} else {
 			td.Count++
 			answer[value]++
-			td.Average += float64(value)
+			weightAnswer[value] += options.Weight(i)
+			sum += options.Weight(i)
+			values = append(values, float64(value))
+			if options.Weighted() {
+				weights = append(weights, options.Weight(i))
+			}
 		}
 	}
 
 	for k := range answer {
-		td.Data = append(td.Data, numberStatisticTemplateStructInner{Value: k, Number: answer[k], Percent: float64(answer[k]) / float64(td.Count)})
+		if options.Suppressed(answer[k]) {
+			td.ValuesSuppressed = true
+			continue
+		}
+		td.Data = append(td.Data, numberStatisticTemplateStructInner{Value: k, Number: answer[k], Percent: weightAnswer[k] / sum})
 	}
 
 	sort.Sort(numberStatisticTemplateStructInnerSort(td.Data))
 
-	v := make([]helper.ChartValue, len(td.Data)+1)
-
-	for i := range td.Data {
-		v[i].Label = strconv.Itoa(td.Data[i].Value)
-		v[i].Value = float64(td.Data[i].Number)
+	td.CountSuppressed = options.Suppressed(td.Count)
+	td.InvalidSuppressed = options.Suppressed(td.Invalid)
+	td.NoAnswerSuppressed = options.Suppressed(td.NoAnswer)
+	d := []helper.Descriptive{helper.WeightedDescriptiveStatistics(values, weights)}
+	if options.Suppressed(d[0].N) {
+		d[0] = helper.Descriptive{Suppressed: true}
+	} else if td.ValuesSuppressed {
+		// Extremes and quartiles might be the value of a suppressed count
+		d[0].ExtremesSuppressed = true
 	}
+	td.Descriptive = helper.DescriptiveTable(nil, d)
+	td.BoxPlot = helper.BoxPlot(nil, d)
 
-	v[len(td.Data)].Label = "[no answer]"
-	v[len(td.Data)].Value = float64(td.NoAnswer)
+	if n.HistogramBins > 0 {
+		td.Image = helper.Histogram(values, weights, n.HistogramBins, options.MinCellSize, n.id, string(f.FormatClean([]byte(n.Question))))
+	} else {
+		v := make([]helper.ChartValue, len(td.Data)+1)
 
-	td.Image = helper.BarChart(v, n.id, string(f.FormatClean([]byte(n.Question))))
+		for i := range td.Data {
+			v[i].Label = strconv.Itoa(td.Data[i].Value)
+			v[i].Value = weightAnswer[td.Data[i].Value]
+		}
+
+		v[len(td.Data)].Label = "[no answer]"
+		if !td.NoAnswerSuppressed {
+			v[len(td.Data)].Value = noAnswer
+		}
 
-	td.Average /= float64(td.Count)
+		td.Image = helper.BarChart(v, n.id, string(f.FormatClean([]byte(n.Question))))
+	}
 
 	output := bytes.NewBuffer(make([]byte, 0))
 	err := numberStatisticsTemplate.Execute(output, td)
@@ -280,6 +331,25 @@ func (n numberQuestion) GetStatisticsDisplay(data []string) template.HTML {
 	return template.HTML(output.Bytes())
 }
 
+// IsNumeric returns true.
+func (n numberQuestion) IsNumeric() bool {
+	return true
+}
+
+// GetValues returns the number of each entry.
+func (n numberQuestion) GetValues(data []string) []float64 {
+	result := make([]float64, len(data))
+	for i := range data {
+		value, err := strconv.Atoi(data[i])
+		if err != nil {
+			result[i] = math.NaN()
+			continue
+		}
+		result[i] = float64(value)
+	}
+	return result
+}
+
 func (n numberQuestion) ValidateInput(data map[string][]string) error {
 	if len(data[n.id]) == 0 || data[n.id][0] == "" {
 		if n.Required {
@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2022,2023 Marcus Soll
+// Copyright 2020,2022,2023,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"html/template"
 	"log"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
@@ -61,6 +62,10 @@ func FactoryRange(data []byte, id string, language string) (registry.Question, e
 		return nil, fmt.Errorf("range: start (%d) must be between min (%d) and max (%d) (%s)", r.Start, r.Min, r.Max, id)
 	}
 
+	if r.HistogramBins < 0 {
+		return nil, fmt.Errorf("range: histogram bins (%d) must not be negative (%s)", r.HistogramBins, id)
+	}
+
 	_, ok := registry.GetFormatType(r.Format)
 	if !ok {
 		return nil, fmt.Errorf("range: Unknown format type %s (%s)", r.Format, id)
@@ -101,21 +106,26 @@ var rangeStatisticsTemplate = template.Must(template.New("rangeStatisticTemplate
 <td>{{printf "%.2f" $e.Percent}}</td>
 </tr>
 {{end}}
+{{if .ValuesSuppressed}}
 <tr>
-<td class="th-cell">[average]</td>
-<td>{{printf "%.2f" .Average}}</td>
+<td class="th-cell">[values with small counts]</td>
+<td>[suppressed]</td>
+<td>[suppressed]</td>
 </tr>
+{{end}}
 <tr>
 <td class="th-cell">[number answer]</td>
-<td>{{.Count}}</td>
+<td>{{if .CountSuppressed}}[suppressed]{{else}}{{.Count}}{{end}}</td>
 </tr>
 <tr>
 <td class="th-cell">[invalid input]</td>
-<td>{{.Invalid}}</td>
+<td>{{if .InvalidSuppressed}}[suppressed]{{else}}{{.Invalid}}{{end}}</td>
 </tr>
 </tbody>
 </table>
 <br>
+{{.Descriptive}}
+{{.BoxPlot}}
 {{.Image}}
 `))
 
@@ -139,12 +149,16 @@ type rangeStatisticTemplateStructInner struct {
 }
 
 type rangeStatisticTemplateStruct struct {
-	Question template.HTML
-	Data     []rangeStatisticTemplateStructInner
-	Average  float64
-	Count    int
-	Invalid  int
-	Image    template.HTML
+	Question          template.HTML
+	Data              []rangeStatisticTemplateStructInner
+	ValuesSuppressed  bool
+	Count             int
+	CountSuppressed   bool
+	Invalid           int
+	InvalidSuppressed bool
+	Descriptive       template.HTML
+	BoxPlot           template.HTML
+	Image             template.HTML
 }
 type rangeStatisticTemplateStructInnerSort []rangeStatisticTemplateStructInner
 
@@ -161,16 +175,17 @@ func (r rangeStatisticTemplateStructInnerSort) Swap(i, j int) {
 }
 
 type rangeQuestion struct {
-	Format     string
-	Question   string
-	Min        int
-	Max        int
-	Step       int
-	Start      int
-	ShowValue  bool
-	ShowScale  bool
-	ScaleStart string
-	ScaleEnd   string
+	Format        string
+	Question      string
+	Min           int
+	Max           int
+	Step          int
+	Start         int
+	ShowValue     bool
+	ShowScale     bool
+	ScaleStart    string
+	ScaleEnd      string
+	HistogramBins int
 
 	id string
 }
@@ -224,16 +239,24 @@ func (r rangeQuestion) GetStatistics(data []string) [][]string {
 }
 
 func (r rangeQuestion) GetStatisticsDisplay(data []string) template.HTML {
+	return r.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted percentages and statistics if the options contain weights. Small counts are suppressed.
+func (r rangeQuestion) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
 	f, _ := registry.GetFormatType(r.Format)
 
 	td := rangeStatisticTemplateStruct{
 		Question: f.Format([]byte(r.Question)),
-		Average:  0.0,
 		Count:    0,
 		Invalid:  0,
 	}
 
 	answer := make(map[int]int)
+	weightAnswer := make(map[int]float64)
+	values := make([]float64, 0, len(data))
+	var weights []float64
+	sum := 0.0
 
 	for i := range data {
 		value, err := strconv.Atoi(data[i])
@@ -242,26 +265,50 @@ func (r rangeQuestion) GetStatisticsDisplay(data []string) template.HTML {
 		} else {
 			td.Count++
 			answer[value]++
-			td.Average += float64(value)
+			weightAnswer[value] += options.Weight(i)
+			sum += options.Weight(i)
+			values = append(values, float64(value))
+			if options.Weighted() {
+				weights = append(weights, options.Weight(i))
+			}
 		}
 	}
 
 	for k := range answer {
-		td.Data = append(td.Data, rangeStatisticTemplateStructInner{Value: k, Number: answer[k], Percent: float64(answer[k]) / float64(td.Count)})
+		if options.Suppressed(answer[k]) {
+			td.ValuesSuppressed = true
+			continue
+		}
+		td.Data = append(td.Data, rangeStatisticTemplateStructInner{Value: k, Number: answer[k], Percent: weightAnswer[k] / sum})
 	}
 
 	sort.Sort(rangeStatisticTemplateStructInnerSort(td.Data))
 
-	v := make([]helper.ChartValue, len(td.Data))
+	td.CountSuppressed = options.Suppressed(td.Count)
+	td.InvalidSuppressed = options.Suppressed(td.Invalid)
 
-	for i := range td.Data {
-		v[i].Label = strconv.Itoa(td.Data[i].Value)
-		v[i].Value = float64(td.Data[i].Number)
+	d := []helper.Descriptive{helper.WeightedDescriptiveStatistics(values, weights)}
+	if options.Suppressed(d[0].N) {
+		d[0] = helper.Descriptive{Suppressed: true}
+	} else if td.ValuesSuppressed {
+		// Extremes and quartiles might be the value of a suppressed count
+		d[0].ExtremesSuppressed = true
 	}
+	td.Descriptive = helper.DescriptiveTable(nil, d)
+	td.BoxPlot = helper.BoxPlot(nil, d)
 
-	td.Image = helper.BarChart(v, r.id, string(f.FormatClean([]byte(r.Question))))
+	if r.HistogramBins > 0 {
+		td.Image = helper.Histogram(values, weights, r.HistogramBins, options.MinCellSize, r.id, string(f.FormatClean([]byte(r.Question))))
+	} else {
+		v := make([]helper.ChartValue, len(td.Data))
 
-	td.Average /= float64(td.Count)
+		for i := range td.Data {
+			v[i].Label = strconv.Itoa(td.Data[i].Value)
+			v[i].Value = weightAnswer[td.Data[i].Value]
+		}
+
+		td.Image = helper.BarChart(v, r.id, string(f.FormatClean([]byte(r.Question))))
+	}
 
 	output := bytes.NewBuffer(make([]byte, 0))
 	err := rangeStatisticsTemplate.Execute(output, td)
@@ -271,6 +318,25 @@ func (r rangeQuestion) GetStatisticsDisplay(data []string) template.HTML {
 	return template.HTML(output.Bytes())
 }
 
+// IsNumeric returns true.
+func (r rangeQuestion) IsNumeric() bool {
+	return true
+}
+
+// GetValues returns the selected value of each entry.
+func (r rangeQuestion) GetValues(data []string) []float64 {
+	result := make([]float64, len(data))
+	for i := range data {
+		value, err := strconv.Atoi(data[i])
+		if err != nil {
+			result[i] = math.NaN()
+			continue
+		}
+		result[i] = float64(value)
+	}
+	return result
+}
+
 func (r rangeQuestion) ValidateInput(data map[string][]string) error {
 	if len(data[r.id]) == 0 || data[r.id][0] == "" {
 		return fmt.Errorf("range (%s): No input found", r.id)
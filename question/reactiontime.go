@@ -0,0 +1,664 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package question
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Top-Ranger/questiongo/helper"
+	"github.com/Top-Ranger/questiongo/registry"
+	"github.com/Top-Ranger/questiongo/translation"
+)
+
+func init() {
+	err := registry.RegisterQuestionType(FactoryReactionTime, "reaction time")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// FactoryReactionTime is the factory for reaction time questions.
+func FactoryReactionTime(data []byte, id string, language string) (registry.Question, error) {
+	var r reactionTime
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return nil, err
+	}
+	r.id = id
+
+	r.translation, err = translation.GetTranslation(language)
+	if err != nil {
+		return nil, fmt.Errorf("reaction time: Can not get translation for language '%s' (%s)", language, id)
+	}
+
+	// Defaults
+	if r.FixationDuration == 0 {
+		r.FixationDuration = 500
+	}
+	if r.Timeout == 0 {
+		r.Timeout = 2000
+	}
+	if r.Pause == 0 {
+		r.Pause = 500
+	}
+
+	// Sanity checks
+	if len(r.AllowedKeys) == 0 {
+		return nil, fmt.Errorf("reaction time: AllowedKeys must not be empty (%s)", id)
+	}
+	if len(r.Trials) == 0 {
+		return nil, fmt.Errorf("reaction time: Trials must not be empty (%s)", id)
+	}
+	if r.FixationDuration < 0 || r.StimulusDuration < 0 || r.Timeout < 0 || r.Pause < 0 {
+		return nil, fmt.Errorf("reaction time: Durations must not be negative (%s)", id)
+	}
+	if r.StimulusDuration > r.Timeout {
+		return nil, fmt.Errorf("reaction time: StimulusDuration (%d) must not be larger than Timeout (%d) (%s)", r.StimulusDuration, r.Timeout, id)
+	}
+	testKey := make(map[string]bool)
+	for i := range r.AllowedKeys {
+		if r.AllowedKeys[i] == "" {
+			return nil, fmt.Errorf("reaction time: Allowed key %d is empty (%s)", i, id)
+		}
+		if testKey[r.AllowedKeys[i]] {
+			return nil, fmt.Errorf("reaction time: Key '%s' found twice (%s)", r.AllowedKeys[i], id)
+		}
+		testKey[r.AllowedKeys[i]] = true
+	}
+	testID := make(map[string]bool)
+	for i := range r.Trials {
+		if r.Trials[i].ID == "" || strings.Contains(r.Trials[i].ID, "_") {
+			return nil, fmt.Errorf("reaction time: Trial %d must have an ID without '_' (%s)", i, id)
+		}
+		if testID[r.Trials[i].ID] {
+			return nil, fmt.Errorf("reaction time: ID %s found twice (%s)", r.Trials[i].ID, id)
+		}
+		testID[r.Trials[i].ID] = true
+		if (r.Trials[i].Text == "") == (r.Trials[i].Image == "") {
+			return nil, fmt.Errorf("reaction time: Trial %s must have either Text or Image (%s)", r.Trials[i].ID, id)
+		}
+		if r.Trials[i].CorrectKey != "" && !testKey[r.Trials[i].CorrectKey] {
+			return nil, fmt.Errorf("reaction time: CorrectKey '%s' of trial %s is not an allowed key (%s)", r.Trials[i].CorrectKey, r.Trials[i].ID, id)
+		}
+	}
+
+	_, ok := registry.GetFormatType(r.Format)
+	if !ok {
+		return nil, fmt.Errorf("reaction time: Unknown format type %s (%s)", r.Format, id)
+	}
+
+	return &r, nil
+}
+
+var reactionTimeTemplate = template.Must(template.New("reactionTimeTemplate").Parse(`{{.Question}}<br>
+<p><small>{{.Translation.ReactionTimeKeys}} {{range $i, $e := .Keys}}{{if $i}}, {{end}}<kbd>{{$e}}</kbd>{{end}}</small></p>
+<div id="{{.QID}}_area" style="min-height: 12em; display: flex; align-items: center; justify-content: center; text-align: center; border: 1px solid; margin-bottom: 0.5em;">
+<button type="button" id="{{.QID}}_start">{{.Translation.ReactionTimeStart}}</button>
+<span id="{{.QID}}_fixation" style="font-size: 3em;" hidden>+</span>
+{{range $i, $e := .Trials}}
+<div id="{{$.QID}}_stimulus_{{$e.ID}}" style="font-size: 2em;" hidden>{{if $e.Image}}<img src="{{$e.Image}}" alt="" style="max-width: 100%; max-height: 30em;">{{else}}{{$e.Text}}{{end}}</div>
+{{end}}
+<span id="{{.QID}}_done" hidden>{{.Translation.ReactionTimeDone}}</span>
+</div>
+<input type="text" id="{{.QID}}" name="{{.QID}}" value="" tabindex="-1" style="opacity: 0; width: 1px; height: 1px; border: 0; padding: 0;" {{if .Required}}required{{end}}>
+<script>
+{
+  let qid = {{.QID}};
+  let order = {{.Order}};
+  let keys = {{.Keys}};
+  let fixation = {{.Fixation}};
+  let stimulus = {{.Stimulus}};
+  let timeout = {{.Timeout}};
+  let pause = {{.Pause}};
+
+  let start = document.getElementById(qid + '_start');
+  let cross = document.getElementById(qid + '_fixation');
+  let done = document.getElementById(qid + '_done');
+  let result = document.getElementById(qid);
+  let results = [];
+  let current = -1;
+  let onset = 0;
+  let waiting = false;
+  let timers = [];
+
+  function clearTimers() {
+    for(let i = 0; i < timers.length; i++) {
+      clearTimeout(timers[i]);
+    }
+    timers = [];
+  }
+
+  function next() {
+    current++;
+    if(current >= order.length) {
+      document.removeEventListener('keydown', keyPressed);
+      result.value = JSON.stringify(results);
+      done.hidden = false;
+      return;
+    }
+    cross.hidden = false;
+    timers.push(setTimeout(show, fixation));
+  }
+
+  function show() {
+    cross.hidden = true;
+    let s = document.getElementById(qid + '_stimulus_' + order[current]);
+    s.hidden = false;
+    // Measure from the frame the stimulus is painted in
+    requestAnimationFrame(function(t) {
+      onset = t;
+      waiting = true;
+      if(stimulus > 0) {
+        timers.push(setTimeout(function() { s.hidden = true; }, stimulus));
+      }
+      timers.push(setTimeout(function() { record('', 0); }, timeout));
+    });
+  }
+
+  function record(key, rt) {
+    waiting = false;
+    clearTimers();
+    document.getElementById(qid + '_stimulus_' + order[current]).hidden = true;
+    results.push({Trial: order[current], Key: key, RT: rt, Position: current + 1});
+    timers.push(setTimeout(next, pause));
+  }
+
+  function keyPressed(event) {
+    if(!waiting || event.repeat || !keys.includes(event.key)) {
+      return;
+    }
+    event.preventDefault();
+    record(event.key, Math.round((performance.now() - onset) * 10) / 10);
+  }
+
+  start.addEventListener('click', function() {
+    start.hidden = true;
+    start.blur();
+    document.addEventListener('keydown', keyPressed);
+    timers.push(setTimeout(next, pause));
+  });
+}
+</script>
+`))
+
+var reactionTimeStatisticsTemplate = template.Must(template.New("reactionTimeStatisticsTemplate").Parse(`{{.Question}}<br>
+{{if .CountSuppressed}}
+<p>[suppressed]</p>
+{{else}}
+<table>
+<thead>
+<tr>
+<th>Condition</th>
+<th>Trials</th>
+<th>Responses</th>
+<th>Timeouts</th>
+<th>Mean RT (ms)</th>
+<th>SD RT (ms)</th>
+<th>Correct</th>
+<th>Mean RT correct (ms)</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $e := .Data }}
+<tr>
+<td>{{$e.Condition}}</td>
+<td>{{$e.Trials}}</td>
+<td>{{if $e.Suppressed}}[suppressed]{{else}}{{$e.Responses}}{{end}}</td>
+<td>{{if $e.Suppressed}}[suppressed]{{else}}{{$e.Timeouts}}{{end}}</td>
+<td>{{printf "%.1f" $e.Mean}}</td>
+<td>{{printf "%.1f" $e.SD}}</td>
+<td>{{if $e.HasCorrect}}{{printf "%.2f" $e.Correct}}{{else}}-{{end}}</td>
+<td>{{if $e.HasCorrect}}{{printf "%.1f" $e.MeanCorrect}}{{else}}-{{end}}</td>
+</tr>
+{{end}}
+<tr>
+<td class="th-cell">[number answers]</td>
+<td>{{.Count}}</td>
+</tr>
+</tbody>
+</table>
+{{.Image}}
+{{end}}
+`))
+
+type reactionTimeTemplateStructTrial struct {
+	ID    string
+	Text  template.HTML
+	Image template.URL
+}
+
+type reactionTimeTemplateStruct struct {
+	Question    template.HTML
+	QID         string
+	Required    bool
+	Keys        []string
+	Trials      []reactionTimeTemplateStructTrial
+	Order       []string
+	Fixation    int
+	Stimulus    int
+	Timeout     int
+	Pause       int
+	Translation translation.Translation
+}
+
+type reactionTimeStatisticsTemplateStructInner struct {
+	Condition   string
+	Trials      int
+	Responses   int
+	Timeouts    int
+	Mean        float64
+	SD          float64
+	HasCorrect  bool
+	Correct     float64
+	MeanCorrect float64
+	Suppressed  bool
+}
+
+type reactionTimeStatisticsTemplateStruct struct {
+	Question        template.HTML
+	Data            []reactionTimeStatisticsTemplateStructInner
+	Count           int
+	CountSuppressed bool
+	Image           template.HTML
+}
+
+type reactionTimeTrial struct {
+	ID         string
+	Condition  string
+	Text       string
+	Image      string
+	CorrectKey string
+
+	image template.URL // data URI of Image, set by LoadFolder
+}
+
+type reactionTimeAnswer struct {
+	Trial    string
+	Key      string
+	RT       float64
+	Position int
+}
+
+type reactionTime struct {
+	Format           string
+	Question         string
+	Required         bool
+	AllowedKeys      []string
+	FixationDuration int // ms
+	StimulusDuration int // ms, 0 shows the stimulus until a response or the timeout
+	Timeout          int // ms after stimulus onset
+	Pause            int // ms between trials
+	Trials           []reactionTimeTrial
+
+	id          string
+	translation translation.Translation
+}
+
+// LoadFolder embeds all stimulus images as data URIs.
+func (r *reactionTime) LoadFolder(path string) error {
+	for i := range r.Trials {
+		if r.Trials[i].Image == "" {
+			continue
+		}
+		mimeType := mime.TypeByExtension(filepath.Ext(r.Trials[i].Image))
+		if !strings.HasPrefix(mimeType, "image/") {
+			return fmt.Errorf("reaction time: Image %s of trial %s is not a known image type (%s)", r.Trials[i].Image, r.Trials[i].ID, r.id)
+		}
+		b, err := os.ReadFile(filepath.Join(path, r.Trials[i].Image))
+		if err != nil {
+			return fmt.Errorf("reaction time: Can not read image %s: %w (%s)", r.Trials[i].Image, err, r.id)
+		}
+		r.Trials[i].image = template.URL(fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(b)))
+	}
+	return nil
+}
+
+func (r reactionTime) trialIndex() map[string]int {
+	index := make(map[string]int, len(r.Trials))
+	for i := range r.Trials {
+		index[r.Trials[i].ID] = i
+	}
+	return index
+}
+
+func (r reactionTime) parseResult(data string) ([]reactionTimeAnswer, bool) {
+	if data == "" || strings.HasPrefix(data, "ERROR") {
+		return nil, false
+	}
+	var result []reactionTimeAnswer
+	err := json.Unmarshal([]byte(data), &result)
+	if err != nil {
+		return nil, false
+	}
+	return result, true
+}
+
+func (r reactionTime) GetID() string {
+	return r.id
+}
+
+func (r reactionTime) GetHTML() template.HTML {
+	f, _ := registry.GetFormatType(r.Format)
+
+	td := reactionTimeTemplateStruct{
+		Question:    f.Format([]byte(r.Question)),
+		QID:         r.id,
+		Required:    r.Required,
+		Keys:        r.AllowedKeys,
+		Trials:      make([]reactionTimeTemplateStructTrial, len(r.Trials)),
+		Order:       make([]string, len(r.Trials)),
+		Fixation:    r.FixationDuration,
+		Stimulus:    r.StimulusDuration,
+		Timeout:     r.Timeout,
+		Pause:       r.Pause,
+		Translation: r.translation,
+	}
+
+	for i := range r.Trials {
+		td.Trials[i] = reactionTimeTemplateStructTrial{ID: r.Trials[i].ID, Text: f.FormatClean([]byte(r.Trials[i].Text)), Image: r.Trials[i].image}
+		td.Order[i] = r.Trials[i].ID
+	}
+	rand.Shuffle(len(td.Order), func(i, j int) {
+		td.Order[i], td.Order[j] = td.Order[j], td.Order[i]
+	})
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := reactionTimeTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("reaction time: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func (r reactionTime) GetStatisticsHeader() []string {
+	header := make([]string, 0, 3*len(r.Trials))
+	for i := range r.Trials {
+		header = append(header, fmt.Sprintf("%s_%s_key", r.id, r.Trials[i].ID), fmt.Sprintf("%s_%s_rt", r.id, r.Trials[i].ID), fmt.Sprintf("%s_%s_position", r.id, r.Trials[i].ID))
+	}
+	return header
+}
+
+func (r reactionTime) GetStatistics(data []string) [][]string {
+	index := r.trialIndex()
+	result := make([][]string, len(data))
+	for d := range data {
+		line := make([]string, 3*len(r.Trials))
+		answers, ok := r.parseResult(data[d])
+		if !ok && data[d] != "" {
+			for i := range line {
+				line[i] = "error"
+			}
+		}
+		for _, a := range answers {
+			i, ok := index[a.Trial]
+			if !ok {
+				continue
+			}
+			line[3*i] = a.Key
+			if a.Key != "" {
+				line[3*i+1] = strconv.FormatFloat(a.RT, 'f', -1, 64)
+			}
+			line[3*i+2] = strconv.Itoa(a.Position)
+		}
+		result[d] = line
+	}
+	return result
+}
+
+func (r reactionTime) GetStatisticsDisplay(data []string) template.HTML {
+	return r.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results. All results are suppressed if there are too few answers, otherwise small numbers of responses or timeouts are suppressed.
+// Weights are not used, since the statistics are based on the single trials.
+func (r reactionTime) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	f, _ := registry.GetFormatType(r.Format)
+
+	index := r.trialIndex()
+	conditions := make(map[string]*reactionTimeStatisticsTemplateStructInner)
+	rts := make(map[string][]float64)
+	correctRTs := make(map[string][]float64)
+	correct := make(map[string]int)
+	order := make([]string, 0)
+	for i := range r.Trials {
+		c := r.Trials[i].Condition
+		if _, ok := conditions[c]; !ok {
+			conditions[c] = &reactionTimeStatisticsTemplateStructInner{Condition: c}
+			order = append(order, c)
+		}
+		if r.Trials[i].CorrectKey != "" {
+			conditions[c].HasCorrect = true
+		}
+	}
+
+	td := reactionTimeStatisticsTemplateStruct{
+		Question: f.Format([]byte(r.Question)),
+	}
+
+	for d := range data {
+		answers, ok := r.parseResult(data[d])
+		if !ok {
+			continue
+		}
+		td.Count++
+		for _, a := range answers {
+			i, ok := index[a.Trial]
+			if !ok {
+				continue
+			}
+			trial := r.Trials[i]
+			c := conditions[trial.Condition]
+			c.Trials++
+			if a.Key == "" {
+				c.Timeouts++
+				continue
+			}
+			c.Responses++
+			rts[trial.Condition] = append(rts[trial.Condition], a.RT)
+			if trial.CorrectKey != "" && trial.CorrectKey == a.Key {
+				correct[trial.Condition]++
+				correctRTs[trial.Condition] = append(correctRTs[trial.Condition], a.RT)
+			}
+		}
+	}
+
+	v := make([]helper.ChartValue, 0, len(order))
+	for _, k := range order {
+		c := conditions[k]
+		c.Mean, c.SD = reactionTimeMeanSD(rts[k])
+		c.MeanCorrect, _ = reactionTimeMeanSD(correctRTs[k])
+		if c.Trials != 0 {
+			c.Correct = float64(correct[k]) / float64(c.Trials)
+		}
+		// Responses and timeouts add up to the trials
+		c.Suppressed = options.SuppressedCells([]int{c.Responses, c.Timeouts})[0]
+		td.Data = append(td.Data, *c)
+		v = append(v, helper.ChartValue{Label: k, Value: c.Mean})
+	}
+	sort.SliceStable(td.Data, func(i, j int) bool {
+		return td.Data[i].Condition < td.Data[j].Condition
+	})
+	sort.SliceStable(v, func(i, j int) bool {
+		return v[i].Label < v[j].Label
+	})
+	td.CountSuppressed = options.Suppressed(td.Count)
+	if !td.CountSuppressed {
+		td.Image = helper.BarChart(v, r.id, string(f.FormatClean([]byte(r.Question))))
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0))
+	err := reactionTimeStatisticsTemplate.Execute(output, td)
+	if err != nil {
+		log.Printf("reaction time: Error executing template (%s)", err.Error())
+	}
+	return template.HTML(output.Bytes())
+}
+
+func reactionTimeMeanSD(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	mean := 0.0
+	for i := range values {
+		mean += values[i]
+	}
+	mean /= float64(len(values))
+	if len(values) == 1 {
+		return mean, 0
+	}
+	sd := 0.0
+	for i := range values {
+		sd += (values[i] - mean) * (values[i] - mean)
+	}
+	return mean, math.Sqrt(sd / float64(len(values)-1))
+}
+
+func (r reactionTime) ValidateInput(data map[string][]string) error {
+	v := data[r.id]
+	if len(v) == 0 || v[0] == "" {
+		if r.Required {
+			return fmt.Errorf("reaction time (%s): No input found", r.id)
+		}
+		return nil
+	}
+
+	var answers []reactionTimeAnswer
+	err := json.Unmarshal([]byte(v[0]), &answers)
+	if err != nil {
+		return fmt.Errorf("reaction time (%s): Invalid input: %w", r.id, err)
+	}
+	if len(answers) != len(r.Trials) {
+		return fmt.Errorf("reaction time (%s): Expected %d trials, got %d", r.id, len(r.Trials), len(answers))
+	}
+
+	index := r.trialIndex()
+	seen := make(map[string]bool, len(answers))
+	for i := range answers {
+		if _, ok := index[answers[i].Trial]; !ok {
+			return fmt.Errorf("reaction time (%s): Unknown trial '%s'", r.id, answers[i].Trial)
+		}
+		if seen[answers[i].Trial] {
+			return fmt.Errorf("reaction time (%s): Trial '%s' found twice", r.id, answers[i].Trial)
+		}
+		seen[answers[i].Trial] = true
+		if answers[i].Key != "" {
+			found := false
+			for k := range r.AllowedKeys {
+				if r.AllowedKeys[k] == answers[i].Key {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("reaction time (%s): Key '%s' not allowed", r.id, answers[i].Key)
+			}
+		}
+		if answers[i].RT < 0 || answers[i].RT > float64(r.Timeout) || math.IsNaN(answers[i].RT) {
+			return fmt.Errorf("reaction time (%s): Invalid reaction time %f", r.id, answers[i].RT)
+		}
+		if answers[i].Position < 1 || answers[i].Position > len(r.Trials) {
+			return fmt.Errorf("reaction time (%s): Invalid position %d", r.id, answers[i].Position)
+		}
+	}
+	return nil
+}
+
+func (r reactionTime) IgnoreRecord(data map[string][]string) bool {
+	return false
+}
+
+func (r reactionTime) GetDatabaseEntry(data map[string][]string) string {
+	v := data[r.id]
+	if len(v) == 0 || v[0] == "" {
+		return ""
+	}
+	var answers []reactionTimeAnswer
+	err := json.Unmarshal([]byte(v[0]), &answers)
+	if err != nil {
+		return fmt.Sprintf("ERROR: %s", err.Error())
+	}
+	sort.Slice(answers, func(i, j int) bool {
+		return answers[i].Position < answers[j].Position
+	})
+	b, err := json.Marshal(answers)
+	if err != nil {
+		return fmt.Sprintf("ERROR: %s", err.Error())
+	}
+	return string(b)
+}
+
+// WriteZipFiles writes all trials in long format (one row per respondent and trial).
+// The respondent is the number of the record in the unfiltered results.
+func (r reactionTime) WriteZipFiles(data []string, records []int, create func(name string) (io.Writer, error)) error {
+	f, err := create(fmt.Sprintf("%s_trials.csv", r.id))
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+
+	err = w.Write(helper.EscapeCSVLine([]string{"respondent", "position", "trial", "condition", "key", "rt", "correct"}))
+	if err != nil {
+		return err
+	}
+
+	index := r.trialIndex()
+	for d := range data {
+		answers, ok := r.parseResult(data[d])
+		if !ok {
+			continue
+		}
+		for _, a := range answers {
+			i, ok := index[a.Trial]
+			if !ok {
+				continue
+			}
+			rt := ""
+			if a.Key != "" {
+				rt = strconv.FormatFloat(a.RT, 'f', -1, 64)
+			}
+			correct := ""
+			if r.Trials[i].CorrectKey != "" {
+				if r.Trials[i].CorrectKey == a.Key {
+					correct = "1"
+				} else {
+					correct = "0"
+				}
+			}
+			err = w.Write(helper.EscapeCSVLine([]string{strconv.Itoa(records[d]), strconv.Itoa(a.Position), a.Trial, r.Trials[i].Condition, a.Key, rt, correct}))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2022 Marcus Soll
+// Copyright 2020,2022,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -22,6 +22,7 @@ import (
 	"html/template"
 	"log"
 	"math/rand"
+	"strings"
 
 	"github.com/Top-Ranger/questiongo/helper"
 	"github.com/Top-Ranger/questiongo/registry"
@@ -80,8 +81,13 @@ var singlechoiceStatisticsTemplate = template.Must(template.New("singlechoiceSta
 {{range $i, $e := .Data }}
 <tr>
 <td>{{$e.Question}}</td>
+{{if $e.Suppressed}}
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+{{else}}
 <td>{{$e.Number}}</td>
 <td>{{printf "%.2f" $e.Result}}</td>
+{{end}}
 </tr>
 {{end}}
 </tbody>
@@ -103,9 +109,10 @@ type singlechoiceStatisticTemplateStruct struct {
 }
 
 type singlechoiceStatisticsTemplateStructInner struct {
-	Question template.HTML
-	Result   float64
-	Number   int
+	Question   template.HTML
+	Result     float64
+	Number     int
+	Suppressed bool
 }
 
 type singlechoiceTemplateStruct struct {
@@ -172,21 +179,29 @@ func (sc singleChoice) GetStatistics(data []string) [][]string {
 }
 
 func (sc singleChoice) GetStatisticsDisplay(data []string) template.HTML {
-	count := 0
+	return sc.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted percentages if the options contain weights. Small counts are suppressed.
+func (sc singleChoice) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	count := 0.0
 	countAnswer := make([]int, len(sc.Answers)+1)
+	weightAnswer := make([]float64, len(sc.Answers)+1)
 
 	for d := range data {
-		count++
+		count += options.Weight(d)
 		found := false
 		for i := range sc.Answers {
 			if data[d] == sc.Answers[i][0] {
 				found = true
 				countAnswer[i]++
+				weightAnswer[i] += options.Weight(d)
 				break
 			}
 		}
 		if !found {
 			countAnswer[len(sc.Answers)]++
+			weightAnswer[len(sc.Answers)] += options.Weight(d)
 		}
 	}
 
@@ -195,26 +210,35 @@ func (sc singleChoice) GetStatisticsDisplay(data []string) template.HTML {
 		Question: f.Format([]byte(sc.Question)),
 		Data:     make([]singlechoiceStatisticsTemplateStructInner, 0, len(sc.Answers)+1),
 	}
+	suppressed := options.SuppressedCells(countAnswer)
 	v := make([]helper.ChartValue, len(sc.Answers)+1)
 	for i := range sc.Answers {
 		question := f.FormatClean([]byte(sc.Answers[i][1]))
 		v[i].Label = string(helper.SanitiseStringClean(string(question)))
-		v[i].Value = float64(countAnswer[i])
+		v[i].Value = weightAnswer[i]
 		inner := singlechoiceStatisticsTemplateStructInner{
 			Question: question,
-			Result:   float64(countAnswer[i]) / float64(count),
+			Result:   weightAnswer[i] / count,
 			Number:   countAnswer[i],
 		}
+		if suppressed[i] {
+			inner.Suppressed = true
+			v[i].Value = 0
+		}
 		td.Data = append(td.Data, inner)
 	}
 	{
 		v[len(sc.Answers)].Label = "[no answer]"
-		v[len(sc.Answers)].Value = float64(countAnswer[len(sc.Answers)])
+		v[len(sc.Answers)].Value = weightAnswer[len(sc.Answers)]
 		inner := singlechoiceStatisticsTemplateStructInner{
 			Question: "[no answer]",
-			Result:   float64(countAnswer[len(sc.Answers)]) / float64(count),
+			Result:   weightAnswer[len(sc.Answers)] / count,
 			Number:   countAnswer[len(sc.Answers)],
 		}
+		if suppressed[len(sc.Answers)] {
+			inner.Suppressed = true
+			v[len(sc.Answers)].Value = 0
+		}
 		td.Data = append(td.Data, inner)
 	}
 
@@ -228,6 +252,31 @@ func (sc singleChoice) GetStatisticsDisplay(data []string) template.HTML {
 	return template.HTML(output.Bytes())
 }
 
+// GetCategoryLabels returns the answers as categories.
+func (sc singleChoice) GetCategoryLabels() []string {
+	f, _ := registry.GetFormatType(sc.Format)
+	labels := make([]string, len(sc.Answers))
+	for i := range sc.Answers {
+		labels[i] = strings.TrimSpace(string(helper.SanitiseStringClean(string(f.FormatClean([]byte(sc.Answers[i][1]))))))
+	}
+	return labels
+}
+
+// GetCategories returns the index of the answer of each entry.
+func (sc singleChoice) GetCategories(data []string) []int {
+	result := make([]int, len(data))
+	for d := range data {
+		result[d] = -1
+		for i := range sc.Answers {
+			if data[d] == sc.Answers[i][0] {
+				result[d] = i
+				break
+			}
+		}
+	}
+	return result
+}
+
 func (sc singleChoice) ValidateInput(data map[string][]string) error {
 	r, ok := data[sc.id]
 	if !ok {
@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2022 Marcus Soll
+// Copyright 2020,2022,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -27,6 +27,7 @@ import (
 
 	"github.com/Top-Ranger/questiongo/helper"
 	"github.com/Top-Ranger/questiongo/registry"
+	"github.com/Top-Ranger/questiongo/translation"
 )
 
 func init() {
@@ -45,6 +46,12 @@ func FactorySingleChoiceOptionalText(data []byte, id string, language string) (r
 	}
 	sc.id = id
 
+	tr, err := translation.GetTranslation(language)
+	if err != nil {
+		return nil, fmt.Errorf("singlechoiceoptionaltext: Can not get translation for language '%s' (%s)", language, id)
+	}
+	sc.language = tr.Language
+
 	// Sanity checks
 	testID := make(map[string]bool)
 	for i := range sc.Answers {
@@ -100,8 +107,13 @@ var singlechoiceoptionaltextStatisticsTemplate = template.Must(template.New("sin
 {{range $i, $e := .Data }}
 <tr>
 <td>{{$e.Question}}</td>
+{{if $e.Suppressed}}
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+{{else}}
 <td>{{$e.Number}}</td>
 <td>{{printf "%.2f" $e.Result}}</td>
+{{end}}
 </tr>
 {{end}}
 </tbody>
@@ -110,14 +122,16 @@ var singlechoiceoptionaltextStatisticsTemplate = template.Must(template.New("sin
 {{.Image}}
 <br>
 {{.QuestionOptionalText}}
-<details>
-<summary>show results ({{len .TextData}}, {{.PercentShown}}%)</summary>
-<ol>
-{{range $i, $e := .TextData }}
-<li>{{$e}}</li>
+{{if .ShownSuppressed}}
+<p>Text field shown: [suppressed]</p>
+{{else}}
+<p>Text field shown: {{.Shown}} ({{.PercentShown}}%)</p>
+{{end}}
+{{if .TextSuppressed}}
+<p>[text answers suppressed]</p>
+{{else}}
+{{.TextAnswers}}
 {{end}}
-</ol>
-</details>
 `))
 
 type singlechoiceoptionaltextTemplateStructInner struct {
@@ -132,14 +146,18 @@ type singlechoiceoptionaltextStatisticTemplateStruct struct {
 	Data                 []singlechoiceoptionaltextStatisticsTemplateStructInner
 	Image                template.HTML
 	QuestionOptionalText template.HTML
-	TextData             []string
+	Shown                int
 	PercentShown         int
+	ShownSuppressed      bool
+	TextAnswers          template.HTML
+	TextSuppressed       bool
 }
 
 type singlechoiceoptionaltextStatisticsTemplateStructInner struct {
-	Question template.HTML
-	Result   float64
-	Number   int
+	Question   template.HTML
+	Result     float64
+	Number     int
+	Suppressed bool
 }
 
 type singlechoiceoptionaltextTemplateStruct struct {
@@ -168,6 +186,7 @@ type singleChoiceOptionalText struct {
 	ShowOptionalText     []string
 
 	id          string
+	language    string
 	showTextMap map[string]bool
 }
 
@@ -233,20 +252,27 @@ func (sc singleChoiceOptionalText) GetStatistics(data []string) [][]string {
 }
 
 func (sc singleChoiceOptionalText) GetStatisticsDisplay(data []string) template.HTML {
-	count := 0
+	return sc.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted percentages if the options contain weights. Small counts are suppressed.
+func (sc singleChoiceOptionalText) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
+	count := 0.0
 	countAnswer := make([]int, len(sc.Answers)+1)
+	weightAnswer := make([]float64, len(sc.Answers)+1)
 
 	f, _ := registry.GetFormatType(sc.Format)
 	td := singlechoiceoptionaltextStatisticTemplateStruct{
 		Question:             f.Format([]byte(sc.Question)),
 		Data:                 make([]singlechoiceoptionaltextStatisticsTemplateStructInner, 0, len(sc.Answers)+1),
 		QuestionOptionalText: f.Format([]byte(sc.QuestionOptionalText)),
-		TextData:             make([]string, 0, len(sc.Answers)),
+		Shown:                0,
 		PercentShown:         0,
 	}
+	text := make([]string, 0)
 
 	for d := range data {
-		count++
+		count += options.Weight(d)
 		found := false
 		var r singleChoiceOptionalTextResult
 		err := json.Unmarshal([]byte(data[d]), &r)
@@ -255,42 +281,63 @@ func (sc singleChoiceOptionalText) GetStatisticsDisplay(data []string) template.
 			continue
 		}
 		if r.TextShown {
-			td.TextData = append(td.TextData, r.Text)
+			td.Shown++
+			if r.Text != "" {
+				text = append(text, r.Text)
+			}
 		}
 		for i := range sc.Answers {
 			if r.Answer == sc.Answers[i][0] {
 				found = true
 				countAnswer[i]++
+				weightAnswer[i] += options.Weight(d)
 				break
 			}
 		}
 		if !found {
 			countAnswer[len(sc.Answers)]++
+			weightAnswer[len(sc.Answers)] += options.Weight(d)
 		}
 	}
 
-	td.PercentShown = 100 * len(td.TextData) / len(data)
+	if len(data) > 0 {
+		td.PercentShown = 100 * td.Shown / len(data)
+	}
+	td.ShownSuppressed = options.Suppressed(td.Shown)
+	if options.Suppressed(len(text)) {
+		td.TextSuppressed = true
+	} else {
+		td.TextAnswers = helper.TextAnswers(text, fmt.Sprintf("%s_scot_text", sc.id), sc.language)
+	}
 
 	v := make([]helper.ChartValue, len(sc.Answers)+1)
 	for i := range sc.Answers {
 		question := f.FormatClean([]byte(sc.Answers[i][1]))
 		v[i].Label = string(helper.SanitiseStringClean(string(question)))
-		v[i].Value = float64(countAnswer[i])
+		v[i].Value = weightAnswer[i]
 		inner := singlechoiceoptionaltextStatisticsTemplateStructInner{
 			Question: question,
-			Result:   float64(countAnswer[i]) / float64(count),
+			Result:   weightAnswer[i] / count,
 			Number:   countAnswer[i],
 		}
+		if options.Suppressed(countAnswer[i]) {
+			inner.Suppressed = true
+			v[i].Value = 0
+		}
 		td.Data = append(td.Data, inner)
 	}
 	{
 		v[len(sc.Answers)].Label = "[no answer]"
-		v[len(sc.Answers)].Value = float64(countAnswer[len(sc.Answers)])
+		v[len(sc.Answers)].Value = weightAnswer[len(sc.Answers)]
 		inner := singlechoiceoptionaltextStatisticsTemplateStructInner{
 			Question: "[no answer]",
-			Result:   float64(countAnswer[len(sc.Answers)]) / float64(count),
+			Result:   weightAnswer[len(sc.Answers)] / count,
 			Number:   countAnswer[len(sc.Answers)],
 		}
+		if options.Suppressed(countAnswer[len(sc.Answers)]) {
+			inner.Suppressed = true
+			v[len(sc.Answers)].Value = 0
+		}
 		td.Data = append(td.Data, inner)
 	}
 
@@ -304,6 +351,52 @@ func (sc singleChoiceOptionalText) GetStatisticsDisplay(data []string) template.
 	return template.HTML(output.Bytes())
 }
 
+// GetCategoryLabels returns the answers as categories.
+func (sc singleChoiceOptionalText) GetCategoryLabels() []string {
+	f, _ := registry.GetFormatType(sc.Format)
+	labels := make([]string, len(sc.Answers))
+	for i := range sc.Answers {
+		labels[i] = strings.TrimSpace(string(helper.SanitiseStringClean(string(f.FormatClean([]byte(sc.Answers[i][1]))))))
+	}
+	return labels
+}
+
+// GetCategories returns the index of the answer of each entry.
+func (sc singleChoiceOptionalText) GetCategories(data []string) []int {
+	result := make([]int, len(data))
+	for d := range data {
+		result[d] = -1
+		var r singleChoiceOptionalTextResult
+		err := json.Unmarshal([]byte(data[d]), &r)
+		if err != nil {
+			continue
+		}
+		for i := range sc.Answers {
+			if r.Answer == sc.Answers[i][0] {
+				result[d] = i
+				break
+			}
+		}
+	}
+	return result
+}
+
+// GetCodableText returns the optional text of each entry.
+func (sc singleChoiceOptionalText) GetCodableText(data []string) []string {
+	result := make([]string, len(data))
+	for d := range data {
+		var r singleChoiceOptionalTextResult
+		err := json.Unmarshal([]byte(data[d]), &r)
+		if err != nil {
+			continue
+		}
+		if r.TextShown {
+			result[d] = r.Text
+		}
+	}
+	return result
+}
+
 func (sc singleChoiceOptionalText) ValidateInput(data map[string][]string) error {
 	r, ok := data[sc.id]
 	if !ok {
@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020 Marcus Soll
+// Copyright 2020,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -22,7 +22,9 @@ import (
 	"html/template"
 	"log"
 
+	"github.com/Top-Ranger/questiongo/helper"
 	"github.com/Top-Ranger/questiongo/registry"
+	"github.com/Top-Ranger/questiongo/translation"
 )
 
 func init() {
@@ -41,6 +43,12 @@ func FactoryText(data []byte, id string, language string) (registry.Question, er
 	}
 	t.id = id
 
+	tr, err := translation.GetTranslation(language)
+	if err != nil {
+		return nil, fmt.Errorf("text: Can not get translation for language '%s' (%s)", language, id)
+	}
+	t.language = tr.Language
+
 	_, ok := registry.GetFormatType(t.Format)
 	if !ok {
 		return nil, fmt.Errorf("text: Unknown format type %s (%s)", t.Format, id)
@@ -54,14 +62,11 @@ var textTemplate = template.Must(template.New("textTemplate").Parse(`<label for=
 `))
 
 var textStatisticsTemplate = template.Must(template.New("textStatisticTemplate").Parse(`{{.Question}}
-<details>
-<summary>show results ({{len .Data}})</summary>
-<ol>
-{{range $i, $e := .Data }}
-<li>{{$e}}</li>
+{{if .Suppressed}}
+<p>[text answers suppressed]</p>
+{{else}}
+{{.Answers}}
 {{end}}
-</ol>
-</details>
 `))
 
 type textTemplateStruct struct {
@@ -72,8 +77,9 @@ type textTemplateStruct struct {
 }
 
 type textStatisticTemplateStruct struct {
-	Question template.HTML
-	Data     []string
+	Question   template.HTML
+	Answers    template.HTML
+	Suppressed bool
 }
 
 type text struct {
@@ -82,7 +88,8 @@ type text struct {
 	Lines    int
 	Required bool
 
-	id string
+	id       string
+	language string
 }
 
 func (t text) GetID() string {
@@ -120,6 +127,12 @@ func (t text) GetStatistics(data []string) [][]string {
 }
 
 func (t text) GetStatisticsDisplay(data []string) template.HTML {
+	return t.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results. The answers are hidden if there are too few of them to protect the privacy of participants.
+// Weights are not used, since there is nothing to count.
+func (t text) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
 	f, _ := registry.GetFormatType(t.Format)
 	answer := make([]string, 0, len(data))
 
@@ -130,8 +143,11 @@ func (t text) GetStatisticsDisplay(data []string) template.HTML {
 	}
 
 	td := textStatisticTemplateStruct{
-		Question: f.Format([]byte(t.Question)),
-		Data:     answer,
+		Question:   f.Format([]byte(t.Question)),
+		Suppressed: options.Suppressed(len(answer)),
+	}
+	if !td.Suppressed {
+		td.Answers = helper.TextAnswers(answer, t.id, t.language)
 	}
 
 	output := bytes.NewBuffer(make([]byte, 0))
@@ -142,6 +158,11 @@ func (t text) GetStatisticsDisplay(data []string) template.HTML {
 	return template.HTML(output.Bytes())
 }
 
+// GetCodableText returns the answers.
+func (t text) GetCodableText(data []string) []string {
+	return data
+}
+
 func (t text) ValidateInput(data map[string][]string) error {
 	if !t.Required {
 		return nil
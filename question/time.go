@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2022 Marcus Soll
+// Copyright 2020,2022,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -45,6 +45,13 @@ func FactoryTime(data []byte, id string, language string) (registry.Question, er
 	}
 	t.id = id
 
+	if t.BucketHours == 0 {
+		t.BucketHours = 1
+	}
+	if t.BucketHours < 0 || t.BucketHours > 24 {
+		return nil, fmt.Errorf("time: BucketHours must be between 1 and 24, is %d (%s)", t.BucketHours, id)
+	}
+
 	_, ok := registry.GetFormatType(t.Format)
 	if !ok {
 		return nil, fmt.Errorf("time: Unknown format type %s (%s)", t.Format, id)
@@ -69,13 +76,25 @@ var timeStatisticsTemplate = template.Must(template.New("timeStatisticTemplate")
 {{range $i, $e := .Data }}
 <tr>
 <td {{if $e.Special}}class="th-cell"{{end}}>{{$e.Time}}</td>
+{{if $e.Suppressed}}
+<td>[suppressed]</td>
+<td>[suppressed]</td>
+{{else}}
 <td>{{$e.Number}}</td>
 <td>{{printf "%.2f" $e.Percent}}</td>
+{{end}}
+</tr>
+{{end}}
+{{if .TimesSuppressed}}
+<tr>
+<td class="th-cell">[times with small counts]</td>
+<td>[suppressed]</td>
+<td>[suppressed]</td>
 </tr>
 {{end}}
 <tr>
 <td class="th-cell">[number answers]</td>
-<td>{{.Sum}}</td>
+<td>{{if .SumSuppressed}}[suppressed]{{else}}{{.Sum}}{{end}}</td>
 </tr>
 </tbody>
 </table>
@@ -90,17 +109,20 @@ type timeTemplateStruct struct {
 }
 
 type timeStatisticTemplateStructInner struct {
-	Time    string
-	Number  int
-	Special bool
-	Percent float64
+	Time       string
+	Number     int
+	Special    bool
+	Percent    float64
+	Suppressed bool
 }
 
 type timeStatisticTemplateStruct struct {
-	Question template.HTML
-	Data     []timeStatisticTemplateStructInner
-	Image    template.HTML
-	Sum      int
+	Question        template.HTML
+	Data            []timeStatisticTemplateStructInner
+	TimesSuppressed bool
+	Image           template.HTML
+	Sum             int
+	SumSuppressed   bool
 }
 type timeStatisticTemplateStructInnerSort []timeStatisticTemplateStructInner
 
@@ -117,13 +139,24 @@ func (d timeStatisticTemplateStructInnerSort) Swap(i, j int) {
 }
 
 type timeQuestion struct {
-	Format   string
-	Question string
-	Required bool
+	Format      string
+	Question    string
+	Required    bool
+	BucketHours int // statistics are aggregated into buckets of this many hours
 
 	id string
 }
 
+// bucket returns the label of the statistics bucket of v as well as the start of the bucket in ISO 8601 format.
+func (t timeQuestion) bucket(v time.Time) (string, string) {
+	start := v.Hour() - v.Hour()%t.BucketHours
+	end := start + t.BucketHours - 1
+	if end > 23 {
+		end = 23
+	}
+	return fmt.Sprintf("%02d:00-%02d:59", start, end), fmt.Sprintf("2000-01-01T%02d:00", start)
+}
+
 func (t timeQuestion) GetID() string {
 	return t.id
 }
@@ -158,8 +191,16 @@ func (t timeQuestion) GetStatistics(data []string) [][]string {
 }
 
 func (t timeQuestion) GetStatisticsDisplay(data []string) template.HTML {
+	return t.GetStatisticsDisplayOptions(data, registry.DisplayOptions{})
+}
+
+// GetStatisticsDisplayOptions returns the results with weighted percentages if the options contain weights.
+// Times with small counts are combined into a single row and left out of the chart.
+func (t timeQuestion) GetStatisticsDisplayOptions(data []string, options registry.DisplayOptions) template.HTML {
 	f, _ := registry.GetFormatType(t.Format)
 	answer := make(map[string]int)
+	weightAnswer := make(map[string]float64)
+	sum := 0.0
 
 	td := timeStatisticTemplateStruct{
 		Question: f.Format([]byte(t.Question)),
@@ -167,32 +208,51 @@ func (t timeQuestion) GetStatisticsDisplay(data []string) template.HTML {
 		Sum:      0,
 	}
 
+	chartTime := make(map[string]string)
 	for i := range data {
+		label := ""
 		if data[i] == "" {
-			answer["[no answer]"]++
-			td.Sum++
+			label = "[no answer]"
 		} else if strings.HasPrefix(data[i], "[invalid input]") {
-			answer["[invalid input]"]++
-			td.Sum++
+			label = "[invalid input]"
 		} else {
-			answer[data[i]]++
-			td.Sum++
+			v, err := time.Parse("15:04", data[i])
+			if err != nil {
+				label = "[invalid input]"
+			} else {
+				var start string
+				label, start = t.bucket(v)
+				chartTime[label] = start
+			}
 		}
+		answer[label]++
+		weightAnswer[label] += options.Weight(i)
+		td.Sum++
+		sum += options.Weight(i)
 	}
 
 	for k := range answer {
-		td.Data = append(td.Data, timeStatisticTemplateStructInner{Time: k, Number: answer[k], Special: strings.HasPrefix(string(k), "["), Percent: float64(answer[k]) / float64(td.Sum)})
+		special := strings.HasPrefix(string(k), "[")
+		suppressed := options.Suppressed(answer[k])
+		if suppressed && !special {
+			td.TimesSuppressed = true
+			continue
+		}
+		td.Data = append(td.Data, timeStatisticTemplateStructInner{Time: k, Number: answer[k], Special: special, Percent: weightAnswer[k] / sum, Suppressed: suppressed})
 	}
+	td.SumSuppressed = options.Suppressed(td.Sum)
 
 	sort.Sort(timeStatisticTemplateStructInnerSort(td.Data))
 
-	v := make([]helper.ChartValue, len(td.Data))
+	v := make([]helper.ChartValue, 0, len(td.Data))
 	for i := range td.Data {
-		v[i].Label = td.Data[i].Time
-		v[i].Value = float64(td.Data[i].Number)
+		if td.Data[i].Special {
+			continue
+		}
+		v = append(v, helper.ChartValue{Label: chartTime[td.Data[i].Time], Value: weightAnswer[td.Data[i].Time]})
 	}
 
-	td.Image = helper.BarChart(v, t.id, string(f.FormatClean([]byte(t.Question))))
+	td.Image = helper.TimeChart(v, t.id, string(f.FormatClean([]byte(t.Question))), "hour", "HH:mm")
 
 	output := bytes.NewBuffer(make([]byte, 0))
 	err := timeStatisticsTemplate.Execute(output, td)
@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2021,2022 Marcus Soll
+// Copyright 2020,2021,2022,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -19,16 +19,21 @@ package main
 import (
 	"archive/zip"
 	"bytes"
+	crand "crypto/rand"
 	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"html/template"
 	"io"
 	"log"
 	"math/rand"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -43,6 +48,24 @@ type ErrValidation error
 var questionnaireTemplate *template.Template
 var questionnaireStartTemplate *template.Template
 
+var questionnaireEditLinkTemplate = template.Must(template.New("questionnaireEditLinkTemplate").Parse(`{{.Text}}
+<hr>
+<p>{{.Translation.EditLinkText}}</p>
+<p><a id="__edit_link" href="{{.Link}}">{{.Link}}</a></p>
+<script>
+{
+  let link = document.getElementById('__edit_link');
+  link.textContent = link.href;
+}
+</script>
+`))
+
+type questionnaireEditLinkTemplateStruct struct {
+	Text        template.HTML
+	Link        string
+	Translation translation.Translation
+}
+
 func init() {
 	var err error
 	questionnaireTemplate, err = template.New("questionnaire").Funcs(evenOddFuncMap).ParseFS(templateFiles, "template/questionnaire.html")
@@ -57,12 +80,55 @@ func init() {
 
 }
 
+// QuestionnaireRepeat describes a repeat group.
+// All questions of a page with a repeat group are shown once per iteration.
+// The number of iterations is either fixed (Times) or taken from the answer of a question on an earlier page (TimesFrom), limited by Max.
+// The questions of each iteration get the iteration number appended to their ID (e.g. 'age-2').
+type QuestionnaireRepeat struct {
+	ID         string
+	Times      int
+	TimesFrom  string
+	Max        int
+	LongLayout bool
+}
+
 // QuestionnairePage represents a single page on the questionnaire.
+// SkipIf holds an optional skip condition. It is an expression (see helper.Expression) of type bool over the statistics values of questions on earlier pages,
+// including computed questions whose inputs are all on earlier pages. If it is true, the page is not shown and its questions are stored empty.
+// Questions depending on stored answers (see registry.DataSourceQuestion) can not be used.
 type QuestionnairePage struct {
 	RandomOrderQuestions bool
+	Repeat               *QuestionnaireRepeat
+	SkipIf               string
 	Questions            [][]string
 
-	questions []registry.Question
+	questions  []registry.Question
+	iterations [][]registry.Question
+	skip       *helper.Expression
+}
+
+// repeatGroup holds the positions of a repeat group in allQuestions.
+type repeatGroup struct {
+	QuestionnaireRepeat
+	baseIDs   []string
+	indices   [][]int // [iteration][question]
+	fromIndex int
+}
+
+// activeIterations returns the number of iterations shown to a participant.
+// from must contain the answer to TimesFrom and is ignored for a fixed number of iterations.
+func (g repeatGroup) activeIterations(from string) int {
+	if g.TimesFrom == "" {
+		return g.Times
+	}
+	n, err := strconv.Atoi(from)
+	if err != nil || n < 0 {
+		return 0
+	}
+	if n > g.Max {
+		return g.Max
+	}
+	return n
 }
 
 // Questionnaire represents a questionnaire.
@@ -85,27 +151,55 @@ type Questionnaire struct {
 	ShowProgress              bool
 	AllowBack                 bool
 	Pages                     []QuestionnairePage
+	Computed                  [][]string
+	Weighting                 *QuestionnaireWeighting
+	MinCellSize               int
+	PublicResults             *QuestionnairePublicResults
 
 	startCache   []byte
 	endCache     []byte
+	endText      template.HTML
 	id           string
 	allQuestions []registry.Question
+	repeats      []repeatGroup
+	computed     []int
+	computedPage []int           // Page after which the inputs of each computed question are known, -1 if it has none
+	skipValues   map[string]bool // Statistics headers needed to evaluate the skip conditions
+	editable     []int
+	hasFiles     bool
+	saveMutex    *sync.Mutex // Only set if answers must be saved serialised, see registry.DataSourceQuestion
+
+	weightQuestion  int
+	rakingQuestions []int
+	publicQuestions []int
+}
+
+type questionnaireTemplateIterationStruct struct {
+	Number       int
+	QuestionData []template.HTML
 }
 
 type questionnaireTemplatePageStruct struct {
 	QuestionData []template.HTML
+	Iterations   []questionnaireTemplateIterationStruct
+	RepeatID     string
+	RepeatFrom   string
 	First        bool
 	Last         bool
 	NextID       string
 	PrevID       string
 	ID           string
+	Page         int // Index in Pages, which is not the position if pages are shown in random order
 }
 
 type questionnaireTemplateStruct struct {
 	Pages        []questionnaireTemplatePageStruct
 	ShowProgress bool
 	AllowBack    bool
+	Multipart    bool
+	Skip         bool
 	ID           string
+	Response     string
 	Translation  translation.Translation
 	ServerPath   string
 }
@@ -128,6 +222,100 @@ func (q Questionnaire) GetEnd() []byte {
 	return q.endCache
 }
 
+// Editable returns whether participants can change their answers later.
+func (q Questionnaire) Editable() bool {
+	return len(q.editable) > 0
+}
+
+// GetEditEnd returns the questionnaire end page containing the personal edit link for a response.
+func (q Questionnaire) GetEditEnd(responseID string) []byte {
+	translationStruct, err := translation.GetTranslation(q.Language)
+	if err != nil {
+		translationStruct = translation.GetDefaultTranslation()
+	}
+
+	td := questionnaireEditLinkTemplateStruct{
+		Text:        q.endText,
+		Link:        fmt.Sprintf("%s/%s?edit=%s", config.ServerPath, q.id, url.QueryEscape(responseID)),
+		Translation: translationStruct,
+	}
+	text := bytes.NewBuffer(make([]byte, 0, len(td.Text)+1000))
+	err = questionnaireEditLinkTemplate.Execute(text, td)
+	if err != nil {
+		log.Printf("questionnaire: Error executing template (%s)", err.Error())
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0, text.Len()*2))
+	textTemplate.Execute(output, textTemplateStruct{template.HTML(text.String()), translationStruct, config.ServerPath})
+	return output.Bytes()
+}
+
+// WriteEdit writes a html page containing all editable questions, prefilled with a previous response, to the writer.
+// The bool reports whether the response exists.
+func (q Questionnaire) WriteEdit(w io.Writer, responseID string) (bool, error) {
+	translationStruct, err := translation.GetTranslation(q.Language)
+	if err != nil {
+		return false, fmt.Errorf("can not get translation for language '%s'", q.Language)
+	}
+
+	stored, ok, err := q.storedResponse(responseID)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	page := questionnaireTemplatePageStruct{
+		QuestionData: []template.HTML{helper.SanitiseString(fmt.Sprintf("<p><em>%s</em></p>", translationStruct.EditAnswer))},
+		First:        true,
+		Last:         true,
+		ID:           "__page_0",
+	}
+	for _, i := range q.editable {
+		page.QuestionData = append(page.QuestionData, q.allQuestions[i].(registry.EditableQuestion).GetEditHTML(stored[i]))
+	}
+
+	t := questionnaireTemplateStruct{
+		Pages:       []questionnaireTemplatePageStruct{page},
+		ID:          q.id,
+		Response:    responseID,
+		Translation: translationStruct,
+		ServerPath:  config.ServerPath,
+	}
+	return true, questionnaireTemplate.ExecuteTemplate(w, "questionnaire.html", t)
+}
+
+// storedResponse returns the stored database entries of the editable questions of a response, mapped by question index.
+// The bool reports whether the response exists.
+func (q Questionnaire) storedResponse(responseID string) (map[int]string, bool, error) {
+	safe, ok := registry.GetDataSafe(config.DataSafe)
+	if !ok {
+		return nil, false, fmt.Errorf("can not get datasafe %s", config.DataSafe)
+	}
+
+	ids := make([]string, len(q.editable)+1)
+	for i, index := range q.editable {
+		ids[i] = q.allQuestions[index].GetID()
+	}
+	ids[len(q.editable)] = registry.ResponseIDQuestion
+
+	data, err := safe.GetData(q.id, ids)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(data) != len(ids) {
+		return nil, false, fmt.Errorf("datasafe returned %d question data, expected was %d", len(data), len(ids))
+	}
+
+	result := make(map[int]string, len(q.editable))
+	for i, index := range q.editable {
+		pos, ok := registry.ResponseIndex(data[len(q.editable)], len(data[i]), responseID)
+		if !ok {
+			return nil, false, nil
+		}
+		result[index] = data[i][pos]
+	}
+	return result, true, nil
+}
+
 // WriteQuestions writes a html page containing the actual questionnaire to the writer.
 // Since the questionnaite might contain random elements, it should be called seperately for each user instead of caching the result.
 func (q Questionnaire) WriteQuestions(w io.Writer) {
@@ -141,20 +329,24 @@ func (q Questionnaire) WriteQuestions(w io.Writer) {
 		ID:           q.id,
 		ShowProgress: q.ShowProgress,
 		AllowBack:    q.AllowBack,
+		Multipart:    q.hasFiles,
+		Skip:         q.hasSkipConditions(),
 		Translation:  translationStruct,
 		ServerPath:   config.ServerPath,
 	}
 	for p := range q.Pages {
-		questionData := make([]template.HTML, len(q.Pages[p].questions))
-		for i := range q.Pages[p].questions {
-			questionData[i] = q.Pages[p].questions[i].GetHTML()
-		}
-		if q.Pages[p].RandomOrderQuestions {
-			rand.Shuffle(len(questionData), func(i, j int) {
-				questionData[i], questionData[j] = questionData[j], questionData[i]
-			})
+		t.Pages[p].Page = p
+		if q.Pages[p].Repeat != nil {
+			t.Pages[p].RepeatID = q.Pages[p].Repeat.ID
+			t.Pages[p].RepeatFrom = q.Pages[p].Repeat.TimesFrom
+			t.Pages[p].Iterations = make([]questionnaireTemplateIterationStruct, len(q.Pages[p].iterations))
+			for it := range q.Pages[p].iterations {
+				t.Pages[p].Iterations[it].Number = it + 1
+				t.Pages[p].Iterations[it].QuestionData = pageHTML(q.Pages[p].iterations[it], q.Pages[p].RandomOrderQuestions)
+			}
+			continue
 		}
-		t.Pages[p].QuestionData = questionData
+		t.Pages[p].QuestionData = pageHTML(q.Pages[p].questions, q.Pages[p].RandomOrderQuestions)
 	}
 
 	if q.RandomOrderPages {
@@ -181,51 +373,95 @@ func (q Questionnaire) WriteQuestions(w io.Writer) {
 	}
 }
 
-// GetResults returns a save html fragment containing the results of a question for each question.
-func (q Questionnaire) GetResults() ([]template.HTML, error) {
-	safe, ok := registry.GetDataSafe(config.DataSafe)
-	if !ok {
-		return nil, fmt.Errorf("can not get datasafe %s", config.DataSafe)
+func pageHTML(questions []registry.Question, random bool) []template.HTML {
+	questionData := make([]template.HTML, len(questions))
+	for i := range questions {
+		questionData[i] = questions[i].GetHTML()
+	}
+	if random {
+		rand.Shuffle(len(questionData), func(i, j int) {
+			questionData[i], questionData[j] = questionData[j], questionData[i]
+		})
 	}
+	return questionData
+}
 
-	ids := make([]string, len(q.allQuestions))
-	for i := range q.allQuestions {
-		ids[i] = q.allQuestions[i].GetID()
+// GetResults returns a save html fragment containing the results of a question for each question.
+// Only responses matching the filter are included.
+func (q Questionnaire) GetResults(filter ResultFilter) ([]template.HTML, error) {
+	indices := make([]int, len(q.allQuestions))
+	for i := range indices {
+		indices[i] = i
 	}
+	return q.getResults(indices, filter, true)
+}
 
-	data, err := safe.GetData(q.id, ids)
-	if len(data) != len(ids) {
-		return nil, fmt.Errorf("datasafe returned %d question data, expected was %d", len(data), len(ids))
+// getResults returns the display of the questions at the given positions of allQuestions.
+// Sections only meant for analysts (e.g. the coded answers) are only included if analyst is true.
+func (q Questionnaire) getResults(indices []int, filter ResultFilter, analyst bool) ([]template.HTML, error) {
+	ids := make([]string, len(indices))
+	for i := range indices {
+		ids[i] = q.allQuestions[indices[i]].GetID()
 	}
+
+	data, positions, _, err := q.getDataPositions(ids, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	result := make([]template.HTML, 0, len(q.allQuestions))
+	var coding map[string]codingState
+	if analyst {
+		coding, err = q.getCoding()
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	for i := range q.allQuestions {
-		result = append(result, q.allQuestions[i].GetStatisticsDisplay(data[i]))
+	weights, err := q.getWeights(positions)
+	if err != nil {
+		return nil, err
+	}
+
+	options := registry.DisplayOptions{Weights: weights, MinCellSize: q.MinCellSize}
+	result := make([]template.HTML, 0, len(indices))
+
+	for i := range indices {
+		question := q.allQuestions[indices[i]]
+		var display template.HTML
+		if dq, ok := question.(registry.DisplayOptionsQuestion); ok {
+			display = dq.GetStatisticsDisplayOptions(data[i], options)
+		} else if q.MinCellSize > 0 {
+			// The question can not suppress small counts, so nothing can be shown
+			display = template.HTML(fmt.Sprintf("<p>%s</p><p>[suppressed]</p>", template.HTMLEscapeString(ids[i])))
+		} else {
+			display = question.GetStatisticsDisplay(data[i])
+			if weights != nil {
+				display = template.HTML(strings.Join([]string{string(display), "<p><small>[unweighted]</small></p>"}, ""))
+			}
+		}
+		if c, ok := question.(registry.CodableQuestion); ok && analyst && len(coding[ids[i]].Codebook) > 0 {
+			display = template.HTML(strings.Join([]string{string(display), string(codeFrequencies(ids[i], coding[ids[i]], c.GetCodableText(data[i]), positions, options))}, ""))
+		}
+		result = append(result, display)
 	}
 
 	return result, nil
 }
 
 // WriteZip writes a zip file containing one result file per question to the writer.
-func (q Questionnaire) WriteZip(w io.Writer) error {
-	safe, ok := registry.GetDataSafe(config.DataSafe)
-	if !ok {
-		return fmt.Errorf("can not get datasafe %s", config.DataSafe)
-	}
-
+// Only responses matching the filter are included.
+func (q Questionnaire) WriteZip(w io.Writer, filter ResultFilter) error {
 	ids := make([]string, len(q.allQuestions))
 	for i := range q.allQuestions {
 		ids[i] = q.allQuestions[i].GetID()
 	}
 
-	data, err := safe.GetData(q.id, ids)
-	if len(data) != len(ids) {
-		return fmt.Errorf("datasafe returned %d question data, expected was %d", len(data), len(ids))
+	data, positions, _, err := q.getDataPositions(ids, filter)
+	if err != nil {
+		return err
 	}
+
+	weights, err := q.getWeights(positions)
 	if err != nil {
 		return err
 	}
@@ -255,25 +491,168 @@ func (q Questionnaire) WriteZip(w io.Writer) error {
 		}
 	}
 
+	if weights != nil {
+		f, err := result.Create(strings.Join([]string{WeightColumn, "csv"}, "."))
+		if err != nil {
+			return err
+		}
+		r := make([][]string, 0, len(weights)+1)
+		r = append(r, []string{WeightColumn})
+		for i := range weights {
+			r = append(r, []string{strconv.FormatFloat(weights[i], 'f', -1, 64)})
+		}
+		err = csv.NewWriter(f).WriteAll(r)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Number of the records in the unfiltered results
+	records := make([]int, len(positions))
+	for i := range positions {
+		records[i] = positions[i] + 1
+	}
+
+	for i := range q.allQuestions {
+		ze, ok := q.allQuestions[i].(registry.ZipExporter)
+		if !ok {
+			continue
+		}
+		prefix := strings.Join([]string{q.allQuestions[i].GetID(), "_"}, "")
+		err = ze.WriteZipFiles(data[i], records, func(name string) (io.Writer, error) {
+			if !strings.HasPrefix(name, prefix) {
+				return nil, fmt.Errorf("file name %s must start with %s", name, prefix)
+			}
+			return result.Create(name)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for g := range q.repeats {
+		if !q.repeats[g].LongLayout {
+			continue
+		}
+		err = q.writeRepeatLong(result, q.repeats[g], data, records)
+		if err != nil {
+			return err
+		}
+	}
+
+	if q.hasFiles {
+		store, ok := registry.GetBlobStore(config.BlobStore)
+		if !ok {
+			return fmt.Errorf("can not get blob store %s", config.BlobStore)
+		}
+
+		for i := range q.allQuestions {
+			fq, ok := q.allQuestions[i].(registry.FileQuestion)
+			if !ok {
+				continue
+			}
+			blobs := fq.GetBlobs(data[i])
+			for j := range blobs {
+				err = func() error {
+					b, err := store.GetBlob(q.id, blobs[j])
+					if err != nil {
+						return err
+					}
+					defer b.Close()
+					f, err := result.Create(strings.Join([]string{"files", blobs[j]}, "/"))
+					if err != nil {
+						return err
+					}
+					_, err = io.Copy(f, b)
+					return err
+				}()
+				if err != nil {
+					log.Printf("zip export (%s): Can not add file %s: %s", q.id, blobs[j], err.Error())
+				}
+			}
+		}
+	}
+
 	return result.Close()
 }
 
-// WriteCSV writes a single csv file containing the current combined results of all questions.
-func (q Questionnaire) WriteCSV(w io.Writer) error {
-	safe, ok := registry.GetDataSafe(config.DataSafe)
-	if !ok {
-		return fmt.Errorf("can not get datasafe %s", config.DataSafe)
+// writeRepeatLong writes the results of a repeat group in long layout (one row per record and iteration) into the zip file.
+// records holds the number of each record in the unfiltered results.
+func (q Questionnaire) writeRepeatLong(z *zip.Writer, g repeatGroup, data [][]string, records []int) error {
+	f, err := z.Create(strings.Join([]string{g.ID, "long.csv"}, "_"))
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+
+	header := []string{"record", "iteration"}
+	for i, index := range g.indices[0] {
+		// Remove iteration from the ID
+		prefix := q.allQuestions[index].GetID()
+		for _, h := range q.allQuestions[index].GetStatisticsHeader() {
+			if strings.HasPrefix(h, prefix) {
+				h = strings.Join([]string{g.baseIDs[i], strings.TrimPrefix(h, prefix)}, "")
+			}
+			header = append(header, h)
+		}
+	}
+	err = w.Write(helper.EscapeCSVLine(header))
+	if err != nil {
+		return err
+	}
+
+	statistics := make([][][][]string, len(g.indices))
+	for it := range g.indices {
+		statistics[it] = make([][][]string, len(g.indices[it]))
+		for i, index := range g.indices[it] {
+			statistics[it][i] = q.allQuestions[index].GetStatistics(data[index])
+		}
+	}
+
+	for record := range records {
+		from := ""
+		if g.fromIndex != -1 && record < len(data[g.fromIndex]) {
+			from = data[g.fromIndex][record]
+		}
+		for it := 0; it < g.activeIterations(from); it++ {
+			line := []string{strconv.Itoa(records[record]), strconv.Itoa(it + 1)}
+			for i := range statistics[it] {
+				if record < len(statistics[it][i]) {
+					line = append(line, statistics[it][i][record]...)
+				} else {
+					line = append(line, make([]string, len(q.allQuestions[g.indices[it][i]].GetStatisticsHeader()))...)
+				}
+			}
+			err = w.Write(helper.EscapeCSVLine(line))
+			if err != nil {
+				return err
+			}
+		}
 	}
 
+	w.Flush()
+	return w.Error()
+}
+
+// WriteCSV writes a single csv file containing the current combined results of all questions.
+// Only responses matching the filter are included.
+func (q Questionnaire) WriteCSV(w io.Writer, filter ResultFilter) error {
 	ids := make([]string, len(q.allQuestions))
 	for i := range q.allQuestions {
 		ids[i] = q.allQuestions[i].GetID()
 	}
 
-	data, err := safe.GetData(q.id, ids)
-	if len(data) != len(ids) {
-		return fmt.Errorf("datasafe returned %d question data, expected was %d", len(data), len(ids))
+	data, positions, _, err := q.getDataPositions(ids, filter)
+	if err != nil {
+		return err
 	}
+
+	coding, err := q.getCoding()
+	if err != nil {
+		return err
+	}
+
+	weights, err := q.getWeights(positions)
 	if err != nil {
 		return err
 	}
@@ -281,17 +660,34 @@ func (q Questionnaire) WriteCSV(w io.Writer) error {
 	csv := csv.NewWriter(w)
 
 	header := make([]string, 0)
+	headerLength := make([]int, len(q.allQuestions))
 	result := make([][][]string, len(q.allQuestions))
 	maxLength := 0
 	for i := range q.allQuestions {
-		header = append(header, q.allQuestions[i].GetStatisticsHeader()...)
-
+		questionHeader := q.allQuestions[i].GetStatisticsHeader()
 		result[i] = q.allQuestions[i].GetStatistics(data[i])
+
+		if _, ok := q.allQuestions[i].(registry.CodableQuestion); ok && len(coding[ids[i]].Codebook) > 0 {
+			codeHeader, codes := codeColumns(ids[i], coding[ids[i]], positions)
+			questionHeader = append(questionHeader, codeHeader...)
+			for r := range result[i] {
+				if r < len(codes) {
+					result[i][r] = append(result[i][r], codes[r]...)
+				}
+			}
+		}
+
+		header = append(header, questionHeader...)
+		headerLength[i] = len(questionHeader)
 		if len(result[i]) > maxLength {
 			maxLength = len(result[i])
 		}
 	}
 
+	if weights != nil {
+		header = append(header, WeightColumn)
+	}
+
 	err = csv.Write(helper.EscapeCSVLine(header))
 	if err != nil {
 		return err
@@ -314,8 +710,15 @@ func (q Questionnaire) WriteCSV(w io.Writer) error {
 					log.Printf("csv export (%s): %s", q.id, t.ErrorAnswersDifferentAmount)
 					errorList = append(errorList, t.ErrorAnswersDifferentAmount)
 				})
-				write = append(write, make([]string, len(q.allQuestions[i].GetStatisticsHeader()))...)
+				write = append(write, make([]string, headerLength[i])...)
+			}
+		}
+		if weights != nil {
+			weight := ""
+			if data < len(weights) {
+				weight = strconv.FormatFloat(weights[data], 'f', -1, 64)
 			}
+			write = append(write, weight)
 		}
 		csv.Write(helper.EscapeCSVLine(write))
 	}
@@ -334,57 +737,118 @@ func (q Questionnaire) WriteCSV(w io.Writer) error {
 }
 
 // SaveData stores the questionnaire results contained in the http.Request permanently.
-func (q Questionnaire) SaveData(r *http.Request) error {
+// If the questionnaire is editable, the ID of the stored response is returned. It is empty otherwise or if the record was ignored.
+// The returned bool reports whether the record was dropped on purpose (see IgnoreRecord and ComputedQuestion), e.g. because the participant was screened out.
+func (q Questionnaire) SaveData(r *http.Request) (string, bool, error) {
 	results := make(map[string]map[string][]string)
+	files := make(map[string]map[string][]*multipart.FileHeader)
 	safe, ok := registry.GetDataSafe(config.DataSafe)
 	if !ok {
-		return fmt.Errorf("can not get datasafe %s", config.DataSafe)
+		return "", false, fmt.Errorf("can not get datasafe %s", config.DataSafe)
 	}
-	r.ParseForm()
-	for k := range r.Form {
+
+	if q.hasFiles {
+		r.Body = http.MaxBytesReader(nil, r.Body, config.MaxRequestSize)
+		err := r.ParseMultipartForm(32 << 20)
+		if err != nil && err != http.ErrNotMultipart {
+			return "", false, ErrValidation(fmt.Errorf("save data: Can not parse multipart form for '%s': %s", q.id, err.Error()))
+		}
+		if r.MultipartForm != nil {
+			defer r.MultipartForm.RemoveAll()
+		}
+	} else {
+		r.ParseForm()
+	}
+
+	formQuestionID := func(k string) (string, bool) {
 		split := strings.Split(k, "_")
 		if len(split) == 0 {
-			continue
+			return "", false
 		}
-		ok := false
 		for i := range q.allQuestions {
 			if split[0] == q.allQuestions[i].GetID() {
-				ok = true
-				break
+				return split[0], true
 			}
 		}
+		return "", false
+	}
+
+	for k := range r.Form {
+		id, ok := formQuestionID(k)
 		if !ok {
 			continue
 		}
-		m, ok := results[split[0]]
+		m, ok := results[id]
 		if !ok {
 			m = make(map[string][]string)
-			results[split[0]] = m
+			results[id] = m
 		}
 		m[k] = r.Form[k]
 	}
 
+	if r.MultipartForm != nil {
+		for k := range r.MultipartForm.File {
+			id, ok := formQuestionID(k)
+			if !ok {
+				continue
+			}
+			m, ok := files[id]
+			if !ok {
+				m = make(map[string][]*multipart.FileHeader)
+				files[id] = m
+			}
+			m[k] = r.MultipartForm.File[k]
+		}
+	}
+
+	// Questions of repeat group iterations and of skipped pages were not shown, so they are not validated and stored empty
+	inactive := q.inactiveRepeats(r.Form)
+	for p := range q.skippedPages(results, inactive) {
+		for _, question := range q.Pages[p].questions {
+			inactive[question.GetID()] = true
+		}
+	}
+
+	if q.saveMutex != nil {
+		q.saveMutex.Lock()
+		defer q.saveMutex.Unlock()
+	}
+
 	// Validate input first
 	for i := range q.allQuestions {
+		if inactive[q.allQuestions[i].GetID()] {
+			continue
+		}
 		m, ok := results[q.allQuestions[i].GetID()]
 		if !ok {
 			m = make(map[string][]string)
 		}
 		err := q.allQuestions[i].ValidateInput(m)
 		if err != nil {
-			return ErrValidation(fmt.Errorf("save data: Validation failed for '%s - %s': %s", q.id, q.allQuestions[i].GetID(), err.Error()))
+			return "", false, ErrValidation(fmt.Errorf("save data: Validation failed for '%s - %s': %s", q.id, q.allQuestions[i].GetID(), err.Error()))
+		}
+
+		fq, ok := q.allQuestions[i].(registry.FileQuestion)
+		if ok {
+			err = fq.ValidateFiles(files[q.allQuestions[i].GetID()])
+			if err != nil {
+				return "", false, ErrValidation(fmt.Errorf("save data: Validation of files failed for '%s - %s': %s", q.id, q.allQuestions[i].GetID(), err.Error()))
+			}
 		}
 	}
 
 	// See if we need to drop the data
 	for i := range q.allQuestions {
+		if inactive[q.allQuestions[i].GetID()] {
+			continue
+		}
 		m, ok := results[q.allQuestions[i].GetID()]
 		if !ok {
 			m = make(map[string][]string)
 		}
 		if q.allQuestions[i].IgnoreRecord(m) {
 			// Silently drop out and ignore the record
-			return nil
+			return "", true, nil
 		}
 	}
 
@@ -392,21 +856,190 @@ func (q Questionnaire) SaveData(r *http.Request) error {
 	data := make([]string, len(q.allQuestions))
 	for i := range q.allQuestions {
 		m, ok := results[q.allQuestions[i].GetID()]
-		if !ok {
+		if !ok || inactive[q.allQuestions[i].GetID()] {
 			m = make(map[string][]string)
 		}
 		questionID[i] = q.allQuestions[i].GetID()
 		data[i] = q.allQuestions[i].GetDatabaseEntry(m)
 	}
 
+	// Computed questions
+	if len(q.computed) > 0 {
+		values := make(map[string]string)
+		for i := range q.allQuestions {
+			cq, ok := q.allQuestions[i].(registry.ComputedQuestion)
+			if ok {
+				var ignore bool
+				data[i], ignore = cq.Compute(values)
+				if ignore {
+					// Silently drop out and ignore the record
+					return "", true, nil
+				}
+			}
+			header := q.allQuestions[i].GetStatisticsHeader()
+			statistics := q.allQuestions[i].GetStatistics([]string{data[i]})
+			if len(statistics) != 1 || len(statistics[0]) != len(header) {
+				continue
+			}
+			for h := range header {
+				values[header[h]] = statistics[0][h]
+			}
+		}
+	}
+
+	// Store uploaded files
+	// If the record can not be saved, the stored files are deleted again so that no file without record is left behind
+	var storedBlobs []string
+	deleteStoredBlobs := func() {
+		store, _ := registry.GetBlobStore(config.BlobStore)
+		for i := range storedBlobs {
+			err := store.DeleteBlob(q.id, storedBlobs[i])
+			if err != nil {
+				log.Printf("save data: Can not delete file '%s' of '%s': %s", storedBlobs[i], q.id, err.Error())
+			}
+		}
+	}
+	if q.hasFiles {
+		store, _ := registry.GetBlobStore(config.BlobStore)
+		for i := range q.allQuestions {
+			fq, ok := q.allQuestions[i].(registry.FileQuestion)
+			if !ok || inactive[q.allQuestions[i].GetID()] {
+				continue
+			}
+			stored, err := fq.SaveFiles(store, q.id, files[q.allQuestions[i].GetID()])
+			if err != nil {
+				log.Printf("save data: Can not save files for '%s - %s': %s", q.id, q.allQuestions[i].GetID(), err.Error())
+				deleteStoredBlobs()
+				return "", false, err
+			}
+			m, ok := results[q.allQuestions[i].GetID()]
+			if !ok {
+				m = make(map[string][]string)
+				results[q.allQuestions[i].GetID()] = m
+			}
+			for k := range stored {
+				m[k] = stored[k]
+			}
+			data[i] = q.allQuestions[i].GetDatabaseEntry(m)
+			storedBlobs = append(storedBlobs, fq.GetBlobs([]string{data[i]})...)
+		}
+	}
+
+	// Response ID for editing
+	// It is always stored (empty if the questionnaire can not be edited), so that the response IDs stay aligned with the records (see registry.ResponseIndex)
+	responseID := ""
+	if q.Editable() {
+		b := make([]byte, 16)
+		_, err := crand.Read(b)
+		if err != nil {
+			deleteStoredBlobs()
+			return "", false, err
+		}
+		responseID = hex.EncodeToString(b)
+	}
+	questionID = append(questionID, registry.ResponseIDQuestion)
+	data = append(data, responseID)
+
 	err := safe.SaveData(q.id, questionID, data)
 	if err != nil {
 		log.Printf("save data: Can not save questionnaire data for '%s': %s", q.id, err.Error())
+		deleteStoredBlobs()
+		return "", false, err
+	}
+
+	return responseID, false, nil
+}
+
+// UpdateData replaces the answers to all editable questions of a previous response with the results contained in the http.Request.
+// All other answers of the response are kept. Computed questions are not evaluated again.
+func (q Questionnaire) UpdateData(r *http.Request, responseID string) error {
+	safe, ok := registry.GetDataSafe(config.DataSafe)
+	if !ok {
+		return fmt.Errorf("can not get datasafe %s", config.DataSafe)
+	}
+
+	if !q.Editable() {
+		return ErrValidation(fmt.Errorf("update data: Questionnaire '%s' can not be edited", q.id))
+	}
+
+	r.ParseForm()
+
+	results := make(map[string]map[string][]string)
+	for _, i := range q.editable {
+		results[q.allQuestions[i].GetID()] = make(map[string][]string)
+	}
+	for k := range r.Form {
+		id := strings.Split(k, "_")[0]
+		m, ok := results[id]
+		if !ok {
+			continue
+		}
+		m[k] = r.Form[k]
+	}
+
+	if q.saveMutex != nil {
+		q.saveMutex.Lock()
+		defer q.saveMutex.Unlock()
+	}
+
+	_, ok, err := q.storedResponse(responseID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrValidation(fmt.Errorf("update data: Unknown response '%s' for '%s'", responseID, q.id))
+	}
+
+	questionID := make([]string, 0, len(q.editable))
+	data := make([]string, 0, len(q.editable))
+	for _, i := range q.editable {
+		m := results[q.allQuestions[i].GetID()]
+		err := q.allQuestions[i].(registry.EditableQuestion).ValidateUpdate(m, responseID)
+		if err != nil {
+			return ErrValidation(fmt.Errorf("update data: Validation failed for '%s - %s': %s", q.id, q.allQuestions[i].GetID(), err.Error()))
+		}
+		if q.allQuestions[i].IgnoreRecord(m) {
+			// Silently keep the previous answer
+			return nil
+		}
+		questionID = append(questionID, q.allQuestions[i].GetID())
+		data = append(data, q.allQuestions[i].GetDatabaseEntry(m))
 	}
 
+	err = safe.UpdateData(q.id, responseID, questionID, data)
+	if err != nil {
+		log.Printf("update data: Can not update questionnaire data for '%s': %s", q.id, err.Error())
+	}
 	return err
 }
 
+// questionDataSource returns a function which returns all stored answers of a single question.
+// The answer of the response exclude is left out.
+func questionDataSource(questionnaireID, questionID string) func(exclude string) ([]string, error) {
+	return func(exclude string) ([]string, error) {
+		safe, ok := registry.GetDataSafe(config.DataSafe)
+		if !ok {
+			return nil, fmt.Errorf("can not get datasafe %s", config.DataSafe)
+		}
+		if exclude == "" {
+			data, err := safe.GetData(questionnaireID, []string{questionID})
+			if err != nil {
+				return nil, err
+			}
+			return data[0], nil
+		}
+		data, err := safe.GetData(questionnaireID, []string{questionID, registry.ResponseIDQuestion})
+		if err != nil {
+			return nil, err
+		}
+		index, ok := registry.ResponseIndex(data[1], len(data[0]), exclude)
+		if ok {
+			data[0] = append(data[0][:index], data[0][index+1:]...)
+		}
+		return data[0], nil
+	}
+}
+
 // LoadQuestionnaire loads a single questionnaire from a file.
 // path must contain the path to the questionnaire folder.
 // file must contain the path to the actual questionnaire json.
@@ -436,10 +1069,38 @@ func LoadQuestionnaire(path, file, key string) (Questionnaire, error) {
 
 	// Load Questions
 	testID := make(map[string]bool)
+	questionPage := make(map[string]int)
 	q.allQuestions = make([]registry.Question, 0)
 	for p := range q.Pages {
 		q.Pages[p].questions = make([]registry.Question, 0, len(q.Pages[p].Questions))
 
+		iterations := 1
+		var group repeatGroup
+		if q.Pages[p].Repeat != nil {
+			group.QuestionnaireRepeat = *q.Pages[p].Repeat
+			group.fromIndex = -1
+			if group.ID == "" || strings.Contains(group.ID, "_") {
+				return Questionnaire{}, fmt.Errorf("repeat group on page %d must have an ID without '_' (%s)", p, file)
+			}
+			switch {
+			case group.Times > 0 && group.TimesFrom != "":
+				return Questionnaire{}, fmt.Errorf("repeat group %s must not have both Times and TimesFrom (%s)", group.ID, file)
+			case group.Times > 0:
+				iterations = group.Times
+			case group.TimesFrom != "":
+				if group.Max < 1 {
+					return Questionnaire{}, fmt.Errorf("repeat group %s needs Max to be at least 1 when using TimesFrom (%s)", group.ID, file)
+				}
+				iterations = group.Max
+			default:
+				return Questionnaire{}, fmt.Errorf("repeat group %s needs either Times or TimesFrom (%s)", group.ID, file)
+			}
+			group.indices = make([][]int, iterations)
+			q.Pages[p].iterations = make([][]registry.Question, iterations)
+		}
+
+		factories := make([]registry.QuestionFactory, len(q.Pages[p].Questions))
+		definitions := make([][]byte, len(q.Pages[p].Questions))
 		for i := range q.Pages[p].Questions {
 			if len(q.Pages[p].Questions[i]) != 3 {
 				return Questionnaire{}, fmt.Errorf("question %d-%d arguments have wrong length (%s)", p, i, file)
@@ -447,25 +1108,164 @@ func LoadQuestionnaire(path, file, key string) (Questionnaire, error) {
 			if strings.Contains(q.Pages[p].Questions[i][0], "_") {
 				return Questionnaire{}, fmt.Errorf("ID %s must not have '_' (%s)", q.Pages[p].Questions[i][0], file)
 			}
-			if testID[q.Pages[p].Questions[i][0]] {
-				return Questionnaire{}, fmt.Errorf("ID %s found twice (%s)", q.Pages[p].Questions[i][0], file)
-			}
-			testID[q.Pages[p].Questions[i][0]] = true
 			pathQ := filepath.Join(path, q.Pages[p].Questions[i][2])
-			b, err = os.ReadFile(pathQ)
+			definitions[i], err = os.ReadFile(pathQ)
 			if err != nil {
 				return Questionnaire{}, fmt.Errorf("can not read file %s: %w (%s)", pathQ, err, file)
 			}
-			factory, ok := registry.GetQuestionType(q.Pages[p].Questions[i][1])
+			factories[i], ok = registry.GetQuestionType(q.Pages[p].Questions[i][1])
 			if !ok {
 				return Questionnaire{}, fmt.Errorf("unknown question type %s (%s)", q.Pages[p].Questions[i][1], file)
 			}
-			newQuestion, err := factory(b, q.Pages[p].Questions[i][0], q.Language)
-			if err != nil {
-				return Questionnaire{}, fmt.Errorf("can not create question %d-%d: %w (%s)", p, i, err, file)
+			group.baseIDs = append(group.baseIDs, q.Pages[p].Questions[i][0])
+		}
+
+		for it := 0; it < iterations; it++ {
+			for i := range q.Pages[p].Questions {
+				id := q.Pages[p].Questions[i][0]
+				if q.Pages[p].Repeat != nil {
+					id = fmt.Sprintf("%s-%d", id, it+1)
+				}
+				if testID[id] {
+					return Questionnaire{}, fmt.Errorf("ID %s found twice (%s)", id, file)
+				}
+				testID[id] = true
+				questionPage[id] = p
+
+				newQuestion, err := factories[i](definitions[i], id, q.Language)
+				if err != nil {
+					return Questionnaire{}, fmt.Errorf("can not create question %d-%d: %w (%s)", p, i, err, file)
+				}
+				if _, ok := newQuestion.(registry.ComputedQuestion); ok {
+					return Questionnaire{}, fmt.Errorf("computed question %s must be listed in Computed instead of a page (%s)", id, file)
+				}
+				if fq, ok := newQuestion.(registry.FolderQuestion); ok {
+					err = fq.LoadFolder(path)
+					if err != nil {
+						return Questionnaire{}, fmt.Errorf("can not load files of question %s: %w (%s)", id, err, file)
+					}
+				}
+				if dq, ok := newQuestion.(registry.DataSourceQuestion); ok {
+					dq.SetDataSource(questionDataSource(key, id))
+					if q.saveMutex == nil && dq.SerialiseSave() {
+						q.saveMutex = new(sync.Mutex)
+					}
+				}
+				q.Pages[p].questions = append(q.Pages[p].questions, newQuestion)
+				if q.Pages[p].Repeat != nil {
+					q.Pages[p].iterations[it] = append(q.Pages[p].iterations[it], newQuestion)
+					group.indices[it] = append(group.indices[it], len(q.allQuestions))
+				}
+				if eq, ok := newQuestion.(registry.EditableQuestion); ok && eq.Editable() {
+					if q.Pages[p].Repeat != nil {
+						return Questionnaire{}, fmt.Errorf("editable question %s must not be part of a repeat group (%s)", id, file)
+					}
+					q.editable = append(q.editable, len(q.allQuestions))
+				}
+				q.allQuestions = append(q.allQuestions, newQuestion)
+
+				_, ok = newQuestion.(registry.FileQuestion)
+				if ok {
+					q.hasFiles = true
+				}
 			}
-			q.Pages[p].questions = append(q.Pages[p].questions, newQuestion)
-			q.allQuestions = append(q.allQuestions, newQuestion)
+		}
+
+		if q.Pages[p].Repeat != nil {
+			q.repeats = append(q.repeats, group)
+		}
+	}
+
+	// Load computed questions
+	knownHeader := make(map[string]bool)
+	headerType := make(map[string]registry.ValueType)
+	headerPage := make(map[string]int)
+	for i := range q.allQuestions {
+		headers := q.allQuestions[i].GetStatisticsHeader()
+		for _, h := range headers {
+			knownHeader[h] = true
+			headerPage[h] = questionPage[q.allQuestions[i].GetID()]
+		}
+		if len(headers) != 1 {
+			continue
+		}
+		if nq, ok := q.allQuestions[i].(registry.NumericQuestion); ok && nq.IsNumeric() {
+			headerType[headers[0]] = registry.ValueNumber
+		} else if _, ok := q.allQuestions[i].(registry.CategoricalQuestion); ok {
+			headerType[headers[0]] = registry.ValueString
+		}
+	}
+	for i := range q.Computed {
+		if len(q.Computed[i]) != 3 {
+			return Questionnaire{}, fmt.Errorf("computed question %d arguments have wrong length (%s)", i, file)
+		}
+		if strings.Contains(q.Computed[i][0], "_") {
+			return Questionnaire{}, fmt.Errorf("ID %s must not have '_' (%s)", q.Computed[i][0], file)
+		}
+		if testID[q.Computed[i][0]] {
+			return Questionnaire{}, fmt.Errorf("ID %s found twice (%s)", q.Computed[i][0], file)
+		}
+		testID[q.Computed[i][0]] = true
+		pathQ := filepath.Join(path, q.Computed[i][2])
+		b, err = os.ReadFile(pathQ)
+		if err != nil {
+			return Questionnaire{}, fmt.Errorf("can not read file %s: %w (%s)", pathQ, err, file)
+		}
+		factory, ok := registry.GetQuestionType(q.Computed[i][1])
+		if !ok {
+			return Questionnaire{}, fmt.Errorf("unknown question type %s (%s)", q.Computed[i][1], file)
+		}
+		newQuestion, err := factory(b, q.Computed[i][0], q.Language)
+		if err != nil {
+			return Questionnaire{}, fmt.Errorf("can not create computed question %d: %w (%s)", i, err, file)
+		}
+		cq, ok := newQuestion.(registry.ComputedQuestion)
+		if !ok {
+			return Questionnaire{}, fmt.Errorf("question %s of type %s is not a computed question (%s)", q.Computed[i][0], q.Computed[i][1], file)
+		}
+		page := -1
+		for _, r := range cq.References() {
+			if !knownHeader[r] {
+				return Questionnaire{}, fmt.Errorf("computed question %s references unknown value '%s' (%s)", q.Computed[i][0], r, file)
+			}
+			page = max(page, headerPage[r])
+		}
+		t, err := cq.CheckReferences(headerType)
+		if err != nil {
+			return Questionnaire{}, fmt.Errorf("can not create computed question %d: %w (%s)", i, err, file)
+		}
+		for _, h := range cq.GetStatisticsHeader() {
+			knownHeader[h] = true
+			headerType[h] = t
+			headerPage[h] = page
+		}
+		q.computed = append(q.computed, len(q.allQuestions))
+		q.computedPage = append(q.computedPage, page)
+		q.allQuestions = append(q.allQuestions, cq)
+	}
+
+	// Check small-cell suppression
+	if q.MinCellSize < 0 {
+		return Questionnaire{}, fmt.Errorf("value MinCellSize must be positive, is %d (%s)", q.MinCellSize, file)
+	}
+
+	// Check weighting
+	err = q.checkWeighting()
+	if err != nil {
+		return Questionnaire{}, fmt.Errorf("%w (%s)", err, file)
+	}
+
+	// Check public results
+	err = q.checkPublicResults()
+	if err != nil {
+		return Questionnaire{}, fmt.Errorf("%w (%s)", err, file)
+	}
+
+	// Check blob store
+	if q.hasFiles {
+		_, ok := registry.GetBlobStore(config.BlobStore)
+		if !ok {
+			return Questionnaire{}, fmt.Errorf("questionnaire contains file uploads, but blob store '%s' is not available (%s)", config.BlobStore, file)
 		}
 	}
 
@@ -499,7 +1299,8 @@ func LoadQuestionnaire(path, file, key string) (Questionnaire, error) {
 	if !ok {
 		return Questionnaire{}, fmt.Errorf("can not format end: Unknown type %s (%s)", q.StartFormat, file)
 	}
-	text := textTemplateStruct{f.Format(b), translationStruct, config.ServerPath}
+	q.endText = f.Format(b)
+	text := textTemplateStruct{q.endText, translationStruct, config.ServerPath}
 	output = bytes.NewBuffer(make([]byte, 0, len(text.Text)*2))
 	textTemplate.Execute(output, text)
 	q.endCache = output.Bytes()
@@ -518,6 +1319,48 @@ func LoadQuestionnaire(path, file, key string) (Questionnaire, error) {
 		}
 	}
 
+	// Check repeat groups
+	testID = make(map[string]bool)
+	for r := range q.repeats {
+		if _, ok := questionPage[q.repeats[r].ID]; testID[q.repeats[r].ID] || ok {
+			return Questionnaire{}, fmt.Errorf("repeat group ID %s found twice (%s)", q.repeats[r].ID, file)
+		}
+		testID[q.repeats[r].ID] = true
+		if len(q.repeats[r].baseIDs) == 0 {
+			return Questionnaire{}, fmt.Errorf("repeat group %s has no questions (%s)", q.repeats[r].ID, file)
+		}
+		if q.repeats[r].TimesFrom == "" {
+			continue
+		}
+		fromPage, ok := questionPage[q.repeats[r].TimesFrom]
+		if !ok || q.Pages[fromPage].Repeat != nil {
+			return Questionnaire{}, fmt.Errorf("repeat group %s: TimesFrom must be the ID of a question outside of a repeat group, is '%s' (%s)", q.repeats[r].ID, q.repeats[r].TimesFrom, file)
+		}
+		repeatPage := questionPage[fmt.Sprintf("%s-1", q.repeats[r].baseIDs[0])]
+		if fromPage >= repeatPage {
+			return Questionnaire{}, fmt.Errorf("repeat group %s: question %s must be on an earlier page (%s)", q.repeats[r].ID, q.repeats[r].TimesFrom, file)
+		}
+		if q.RandomOrderPages && fromPage >= q.DoNotRandomiseFirstNPages && repeatPage < len(q.Pages)-q.DoNotRandomiseLastNPages {
+			return Questionnaire{}, fmt.Errorf("repeat group %s: question %s might be shown after the repeat group due to random page order (%s)", q.repeats[r].ID, q.repeats[r].TimesFrom, file)
+		}
+		for i := range q.allQuestions {
+			if q.allQuestions[i].GetID() == q.repeats[r].TimesFrom {
+				q.repeats[r].fromIndex = i
+				break
+			}
+		}
+		nq, ok := q.allQuestions[q.repeats[r].fromIndex].(registry.NumericQuestion)
+		if !ok || !nq.IsNumeric() {
+			return Questionnaire{}, fmt.Errorf("repeat group %s: question %s must be numeric (%s)", q.repeats[r].ID, q.repeats[r].TimesFrom, file)
+		}
+	}
+
+	// Check skip conditions
+	err = q.loadSkipConditions(headerPage, headerType)
+	if err != nil {
+		return Questionnaire{}, fmt.Errorf("%w (%s)", err, file)
+	}
+
 	// ID
 	q.id = key
 
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestLoadRepeatTimesFrom(t *testing.T) {
+	tests := []struct {
+		questionType string
+		file         string
+		fail         bool
+	}{
+		{"number", "number.json", false},
+		{"text", "text.json", true},
+		{"single choice", "sc.json", true},
+	}
+
+	for _, tc := range tests {
+		dir := t.TempDir()
+		files := map[string]string{
+			"questionnaire.json": `{
+    "Password": "test",
+    "PasswordMethod": "plain",
+    "Open": true,
+    "Start": "start.md",
+    "StartFormat": "plain",
+    "End": "end.md",
+    "EndFormat": "plain",
+    "Pages": [
+        {"Questions": [["times", ` + strconv.Quote(tc.questionType) + `, ` + strconv.Quote(tc.file) + `]]},
+        {"Repeat": {"ID": "group", "TimesFrom": "times", "Max": 3}, "Questions": [["name", "text", "text.json"]]}
+    ]
+}`,
+			"start.md":    "Start",
+			"end.md":      "End",
+			"number.json": `{"Format": "plain", "Question": "Times", "HasMinMax": true, "Min": 0, "Max": 3}`,
+			"text.json":   `{"Format": "plain", "Question": "Text", "Lines": 1}`,
+			"sc.json":     `{"Format": "plain", "Question": "Times", "Answers": [["1", "One"], ["2", "Two"]]}`,
+		}
+		for name, content := range files {
+			err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+			if err != nil {
+				t.Fatalf("can not write %s: %s", name, err.Error())
+			}
+		}
+		_, err := LoadQuestionnaire(dir, filepath.Join(dir, "questionnaire.json"), "repeat")
+		if tc.fail && err == nil {
+			t.Errorf("%s: expected error", tc.questionType)
+		}
+		if !tc.fail && err != nil {
+			t.Errorf("%s: can not load questionnaire: %s", tc.questionType, err.Error())
+		}
+	}
+}
@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2021,2022 Marcus Soll
+// Copyright 2020,2021,2022,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -21,9 +21,42 @@ package registry
 import (
 	"fmt"
 	"html/template"
+	"io"
+	"mime/multipart"
 	"sync"
 )
 
+// ResponseIDQuestion is the question ID under which the response IDs of a questionnaire are stored.
+// Since question IDs can not contain '_', it can not collide with a question.
+// A response ID is stored with every record, which is empty if the questionnaire can not be edited at that time.
+// Only records stored before response IDs were introduced have none, so the stored response IDs always belong to the newest records of each question.
+const ResponseIDQuestion = "_response"
+
+// FieldworkQuestion is the question ID under which aggregated fieldwork counts (e.g. page views per day) of a questionnaire are stored.
+// Like ResponseIDQuestion, it can not collide with a question. The stored counts do not contain any data of single respondents.
+const FieldworkQuestion = "_fieldwork"
+
+// CodingQuestion is the question ID under which the codebooks and coded answers of CodableQuestion are stored.
+// Like ResponseIDQuestion, it can not collide with a question. Coded answers are identified by their position, which is kept by UpdateData.
+const CodingQuestion = "_coding"
+
+// ResponseIndex returns the index of the record identified by responseID in the n records of a question.
+// responses holds all stored response IDs of the questionnaire.
+// The bool indicates whether the record exists. You can only use the index if the bool is true.
+// An empty responseID never matches, since records stored while a questionnaire can not be edited have an empty response ID.
+func ResponseIndex(responses []string, n int, responseID string) (int, bool) {
+	if responseID == "" {
+		return -1, false
+	}
+	for i := range responses {
+		if responses[i] == responseID {
+			index := n - len(responses) + i
+			return index, index >= 0 && index < n
+		}
+	}
+	return -1, false
+}
+
 // AlreadyRegisteredError represents an error where an option is already registeres
 type AlreadyRegisteredError string
 
@@ -84,6 +117,223 @@ type Question interface {
 	GetDatabaseEntry(data map[string][]string) string
 }
 
+// FileQuestion represents a question which accepts uploaded files in addition to the normal form input.
+// Uploaded files are stored in a BlobStore. The database entry should only hold references to the stored blobs.
+// The names of all blobs must start with the question id, followed by a '_'.
+// All methods must be save for parallel usage.
+type FileQuestion interface {
+	Question
+
+	// ValidateFiles validates whether the uploaded files can be considered valid (e.g. allowed type and size).
+	// The files are filtered by questions the same way as the data of ValidateInput.
+	// The method must return error != nil if the input is not valid.
+	ValidateFiles(files map[string][]*multipart.FileHeader) error
+
+	// SaveFiles stores the uploaded files in the BlobStore.
+	// The returned values are added to the data passed to GetDatabaseEntry, replacing values with the same key.
+	// It is only called for validated records which are not ignored. If an error is returned, no stored file must be left in the BlobStore.
+	SaveFiles(store BlobStore, questionnaireID string, files map[string][]*multipart.FileHeader) (map[string][]string, error)
+
+	// GetBlobs returns the names of all blobs referenced by the database entries.
+	GetBlobs(data []string) []string
+}
+
+// FolderQuestion represents a question which needs files from the questionnaire folder, e.g. images.
+// All methods must be save for parallel usage.
+type FolderQuestion interface {
+	Question
+
+	// LoadFolder is called once after the question is created. path holds the questionnaire folder.
+	// The question should read all needed files here, since the questionnaire is treated as immutable afterwards.
+	LoadFolder(path string) error
+}
+
+// DataSourceQuestion represents a question which needs the already stored answers, e.g. to enforce a limited capacity.
+// If SerialiseSave returns true, saving answers of the questionnaire is serialised, so ValidateInput can check the stored answers without races.
+// This only holds for a single running instance.
+// All methods must be save for parallel usage.
+type DataSourceQuestion interface {
+	Question
+
+	// SetDataSource is called once after the question is created.
+	// get returns all stored answers of the question as returned by GetDatabaseEntry.
+	// The answer of the response exclude is left out (see ResponseIDQuestion). An empty exclude leaves out nothing.
+	SetDataSource(get func(exclude string) ([]string, error))
+
+	// SerialiseSave returns whether saving answers must be serialised, e.g. because ValidateInput checks the stored answers.
+	SerialiseSave() bool
+}
+
+// ZipExporter represents a question which adds further files to the zip export, e.g. data in long format.
+// The names of all files must start with the question id, followed by a '_'.
+// All methods must be save for parallel usage.
+type ZipExporter interface {
+	Question
+
+	// WriteZipFiles writes the additional files. create returns a writer for a new file in the zip file, which is valid until the next call of create.
+	// data holds all database entries currently available.
+	// records holds the number of each database entry in the unfiltered results (starting at 1), so that responses can be matched across exports. It has at least as many entries as data.
+	WriteZipFiles(data []string, records []int, create func(name string) (io.Writer, error)) error
+}
+
+// EditableQuestion represents a question whose answer can be changed later by the participant through a personal edit link.
+// When editing a response, only the editable questions are shown, validated and updated. All other answers are kept.
+// All methods must be save for parallel usage.
+type EditableQuestion interface {
+	Question
+
+	// Editable returns whether participants are allowed to change their answer.
+	Editable() bool
+
+	// GetEditHTML returns the HTML representation of the question, prefilled with a database entry (see GetDatabaseEntry).
+	GetEditHTML(data string) template.HTML
+
+	// ValidateUpdate validates the changed answer of the response responseID like ValidateInput.
+	// The previous answer of that response must not be counted as a stored answer, e.g. against a capacity.
+	ValidateUpdate(data map[string][]string, responseID string) error
+}
+
+// CategoricalQuestion represents a question whose answers fall into a fixed set of categories, e.g. single choice questions.
+// Categorical questions can be used for cross-tabulation on the results page.
+// All methods must be save for parallel usage.
+type CategoricalQuestion interface {
+	Question
+
+	// GetCategoryLabels returns the plain text labels of all categories in display order.
+	GetCategoryLabels() []string
+
+	// GetCategories returns the category (index of GetCategoryLabels) of each database entry.
+	// Entries without a category (e.g. no answer) are -1.
+	GetCategories(data []string) []int
+}
+
+// NumericQuestion represents a question whose answers are numbers, e.g. number questions.
+// Numeric questions can be compared between groups on the results page.
+// All methods must be save for parallel usage.
+type NumericQuestion interface {
+	Question
+
+	// IsNumeric returns whether the answers are numbers. This allows types to decide based on their configuration.
+	IsNumeric() bool
+
+	// GetValues returns the value of each database entry. Entries without a valid value are NaN.
+	GetValues(data []string) []float64
+}
+
+// DisplayOptions holds options for displaying the results of a question, see DisplayOptionsQuestion.
+type DisplayOptions struct {
+	// Weights holds the weight of each database entry in the same order as the data. It is nil if the results are not weighted.
+	Weights []float64
+
+	// MinCellSize is the smallest number of participants shown in the results, see Suppressed. 0 disables the suppression.
+	// Free text answers must only be shown if there are at least MinCellSize of them.
+	MinCellSize int
+}
+
+// Suppressed returns whether a count of participants must not be shown (nor anything it can be derived from, e.g. percentages) to protect the privacy of the participants.
+// Counts of 0 are never suppressed, since they can not identify anybody.
+func (o DisplayOptions) Suppressed(count int) bool {
+	return count > 0 && count < o.MinCellSize
+}
+
+// SuppressedCells returns which counts must not be shown if the counts are parts of a total, e.g. the answers to a single choice question.
+// Besides all counts which are Suppressed, the smallest other count is suppressed if only one count would be, since it could be calculated from the total and the other counts otherwise.
+func (o DisplayOptions) SuppressedCells(counts []int) []bool {
+	result := make([]bool, len(counts))
+	suppressed := 0
+	for i := range counts {
+		if o.Suppressed(counts[i]) {
+			result[i] = true
+			suppressed++
+		}
+	}
+	if suppressed != 1 {
+		return result
+	}
+	secondary := -1
+	for i := range counts {
+		if result[i] || counts[i] == 0 {
+			continue
+		}
+		if secondary == -1 || counts[i] < counts[secondary] {
+			secondary = i
+		}
+	}
+	if secondary != -1 {
+		result[secondary] = true
+	}
+	return result
+}
+
+// Weighted returns whether the results are weighted.
+func (o DisplayOptions) Weighted() bool {
+	return o.Weights != nil
+}
+
+// Weight returns the weight of the database entry at index i.
+// All entries have a weight of 1 if the results are not weighted.
+func (o DisplayOptions) Weight(i int) float64 {
+	if i < 0 || i >= len(o.Weights) {
+		return 1
+	}
+	return o.Weights[i]
+}
+
+// DisplayOptionsQuestion represents a question which takes display options into account when displaying the results, e.g. to show weighted percentages and means.
+// Questions not implementing it are not shown if counts must be suppressed.
+// All methods must be save for parallel usage.
+type DisplayOptionsQuestion interface {
+	Question
+
+	// GetStatisticsDisplayOptions returns a HTML fragment representing the current results like GetStatisticsDisplay, but using the options.
+	// data holds all database entries currently available.
+	GetStatisticsDisplayOptions(data []string, options DisplayOptions) template.HTML
+}
+
+// CodableQuestion represents a question with free text answers, which analysts can code on the results page.
+// The codes are stored separately from the database entries.
+// All methods must be save for parallel usage.
+type CodableQuestion interface {
+	Question
+
+	// GetCodableText returns the free text of each database entry. An empty string means that there is nothing to code.
+	GetCodableText(data []string) []string
+}
+
+// ComputedQuestion represents a question which is not shown to participants, but computed from the answers to other questions.
+// Computed questions are evaluated after all other database entries of a record are known, in the order they are defined.
+// They can ignore a record (screen-out) on submission. While answering, a computed value is evaluated on the page where all its references are known, so page skip conditions can use it.
+// All methods must be save for parallel usage.
+type ComputedQuestion interface {
+	Question
+
+	// References returns the statistics headers (see GetStatisticsHeader) the computation depends on.
+	References() []string
+
+	// Compute returns the database entry of the question.
+	// values holds the statistics of the current record (see GetStatistics), mapped by statistics header. This includes previously computed questions.
+	// ignore reports whether the whole record should be ignored (see IgnoreRecord).
+	Compute(values map[string]string) (entry string, ignore bool)
+
+	// CheckReferences type checks the computation against the types of the referenced statistics headers and returns the type of the result.
+	// References missing in types have the type ValueUnknown. It is called once while loading the questionnaire, before any other method is used.
+	CheckReferences(types map[string]ValueType) (ValueType, error)
+}
+
+// ValueType represents the type of a statistics value, see ComputedQuestion.
+type ValueType int
+
+const (
+	// ValueUnknown means that the type of the value is not known.
+	ValueUnknown ValueType = iota
+	// ValueNumber represents a number.
+	ValueNumber
+	// ValueString represents a string.
+	ValueString
+	// ValueBool represents a bool ("true" or "false").
+	ValueBool
+)
+
 // Format represents a formatting option.
 // All methods must be save for parallel usage.
 type Format interface {
@@ -94,10 +344,25 @@ type Format interface {
 // DataSafe represents a backend for save storage of questionnaire results.
 // All results must be stored in the same order they are added, grouped by questionnaireID and questionID.
 // However, there reordering is allowed as long as the order for one questionnaireID / questionID combination is retained.
+// GetData must return all data passed to SaveData before, even if it is not persisted yet.
+// UpdateData replaces the data of a single record identified by its response ID (see ResponseIDQuestion and ResponseIndex), keeping its position.
 // All methods must be save for parallel usage.
 type DataSafe interface {
 	SaveData(questionnaireID string, questionID, data []string) error // Must preserve the order of data for a questionnaireID, questionID combination
 	GetData(questionnaireID string, questionID []string) ([][]string, error)
+	UpdateData(questionnaireID, responseID string, questionID, data []string) error // Must fail without changes if the response is not known
+	LoadConfig(data []byte) error
+	FlushAndClose()
+}
+
+// BlobStore represents a backend for storing binary large objects, e.g. uploaded files.
+// Blobs are grouped by questionnaireID. Names are chosen by the caller and must be unique for a questionnaireID.
+// Names must not contain path separators.
+// All methods must be save for parallel usage.
+type BlobStore interface {
+	SaveBlob(questionnaireID, name string, r io.Reader) error
+	GetBlob(questionnaireID, name string) (io.ReadCloser, error) // Caller must close the returned reader
+	DeleteBlob(questionnaireID, name string) error               // Deleting a blob which does not exist is not an error
 	LoadConfig(data []byte) error
 	FlushAndClose()
 }
@@ -111,6 +376,8 @@ var (
 	knownDataSafesMutex       = sync.RWMutex{}
 	knownPasswordMethods      = make(map[string]PasswordMethod)
 	knownPasswordMethodsMutex = sync.RWMutex{}
+	knownBlobStores           = make(map[string]BlobStore)
+	knownBlobStoresMutex      = sync.RWMutex{}
 )
 
 // RegisterQuestionType registeres a question type.
@@ -185,6 +452,30 @@ func GetDataSafe(name string) (DataSafe, bool) {
 	return f, ok
 }
 
+// RegisterBlobStore registeres a blob store.
+// The name of the blob store is used as an identifier and must be unique.
+// You can savely use it in parallel.
+func RegisterBlobStore(t BlobStore, name string) error {
+	knownBlobStoresMutex.Lock()
+	defer knownBlobStoresMutex.Unlock()
+
+	_, ok := knownBlobStores[name]
+	if ok {
+		return AlreadyRegisteredError("BlobStore already registered")
+	}
+	knownBlobStores[name] = t
+	return nil
+}
+
+// GetBlobStore returns a blob store.
+// The bool indicates whether it existed. You can only use it if the bool is true.
+func GetBlobStore(name string) (BlobStore, bool) {
+	knownBlobStoresMutex.RLock()
+	defer knownBlobStoresMutex.RUnlock()
+	f, ok := knownBlobStores[name]
+	return f, ok
+}
+
 // RegisterPasswordMethod registeres a password method.
 // The name of the password method is used as an identifier and must be unique.
 // You can savely use it in parallel.
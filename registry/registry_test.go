@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuppressedCells(t *testing.T) {
+	tests := []struct {
+		name        string
+		minCellSize int
+		counts      []int
+		want        []bool
+	}{
+		{"disabled", 0, []int{1, 7, 2}, []bool{false, false, false}},
+		{"nothing small", 5, []int{5, 7, 0}, []bool{false, false, false}},
+		{"secondary", 5, []int{2, 9, 6, 0}, []bool{true, false, true, false}},
+		{"two small", 5, []int{2, 9, 3}, []bool{true, false, true}},
+		{"no other count", 5, []int{2, 0}, []bool{true, false}},
+	}
+
+	for _, tc := range tests {
+		got := DisplayOptions{MinCellSize: tc.minCellSize}.SuppressedCells(tc.counts)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2021,2022 Marcus Soll
+// Copyright 2020,2021,2022,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -19,11 +19,13 @@ import (
 	"bytes"
 	"context"
 	"embed"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -44,6 +46,8 @@ var rootPath string
 var resultsTemplate *template.Template
 var resultsAccessTemplate *template.Template
 var reloadTemplate *template.Template
+var codingTemplate *template.Template
+var publicResultsTemplate *template.Template
 
 var dsgvo []byte
 var impressum []byte
@@ -71,6 +75,11 @@ func init() {
 		panic(err)
 	}
 
+	publicResultsTemplate, err = template.New("public").Funcs(evenOddFuncMap).ParseFS(templateFiles, "template/public.html")
+	if err != nil {
+		panic(err)
+	}
+
 	cssTemplates, err = template.ParseFS(cachedFiles, "css/*")
 	if err != nil {
 		panic(err)
@@ -80,12 +89,40 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	codingTemplate, err = template.ParseFS(templateFiles, "template/coding.html")
+	if err != nil {
+		panic(err)
+	}
 }
 
 type resultsTemplateStruct struct {
+	Results          []template.HTML
+	Key              string
+	Auth             string
+	Categorical      []string
+	CrossTabRow      string
+	CrossTabColumn   string
+	CrossTab         template.HTML
+	Compare          string
+	Comparison       template.HTML
+	Codable          bool
+	Weighting        string
+	Fieldwork        template.HTML
+	FilterOptions    []resultFilterQuestion
+	Filter           string
+	FilterText       string
+	FilterMatching   int
+	FilterTotal      int
+	FilterSuppressed bool
+	MinCellSize      int
+	Translation      translation.Translation
+	ServerPath       string
+}
+
+type publicResultsTemplateStruct struct {
 	Results     []template.HTML
-	Key         string
-	Auth        string
+	Updated     string
 	Translation translation.Translation
 	ServerPath  string
 }
@@ -214,8 +251,11 @@ func initialiseServer() error {
 		return err
 	}
 	http.HandleFunc(strings.Join([]string{config.ServerPath, "/answer.html"}, ""), answerHandle)
+	http.HandleFunc(strings.Join([]string{config.ServerPath, "/skip.json"}, ""), skipHandle)
 	http.HandleFunc(strings.Join([]string{config.ServerPath, "/results.html"}, ""), resultsHandle)
+	http.HandleFunc(strings.Join([]string{config.ServerPath, "/public.html"}, ""), publicResultsHandle)
 	http.HandleFunc(strings.Join([]string{config.ServerPath, "/reload.html"}, ""), reloadHandle)
+	http.HandleFunc(strings.Join([]string{config.ServerPath, "/coding.html"}, ""), codingHandle)
 	http.HandleFunc(strings.Join([]string{config.ServerPath, "/results.zip"}, ""), func(w http.ResponseWriter, r *http.Request) { resultDownloadHandle(w, r, "zip") })
 	http.HandleFunc(strings.Join([]string{config.ServerPath, "/results.csv"}, ""), func(w http.ResponseWriter, r *http.Request) { resultDownloadHandle(w, r, "csv") })
 	http.HandleFunc("/", questionnaireHandle)
@@ -267,15 +307,43 @@ func questionnaireHandle(rw http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 	_, main := query["main"]
 	_, end := query["end"]
+	edit := query.Get("edit")
 
 	if main {
+		countFieldwork(key, fieldworkMain)
 		q.WriteQuestions(rw)
 		return
 	}
+	if edit != "" && q.Editable() {
+		ok, err := q.WriteEdit(rw, edit)
+		if err != nil {
+			log.Printf("server: can not show edit page for questionnaire %s: %s", key, err.Error())
+			rw.WriteHeader(http.StatusInternalServerError)
+			rw.Write([]byte(err.Error()))
+			return
+		}
+		if !ok {
+			rw.WriteHeader(http.StatusNotFound)
+			translationStruct, err := translation.GetTranslation(q.Language)
+			if err != nil {
+				log.Printf("server: error while getting translation (%s) for questionnaire %s: %s", q.Language, key, err.Error())
+				translationStruct = translation.GetDefaultTranslation()
+			}
+			t := errorTemplateStruct{helper.SanitiseString(fmt.Sprintf("<h1>%s</h1>", translationStruct.EditUnknownResponse)), translationStruct, config.ServerPath}
+			errorTemplate.Execute(rw, t)
+		}
+		return
+	}
 	if end {
+		response := query.Get("response")
+		if response != "" && q.Editable() {
+			rw.Write(q.GetEditEnd(response))
+			return
+		}
 		rw.Write(q.GetEnd())
 		return
 	}
+	countFieldwork(key, fieldworkStart)
 	rw.Write(q.GetStart())
 }
 
@@ -292,7 +360,21 @@ func answerHandle(rw http.ResponseWriter, r *http.Request) {
 		errorTemplate.Execute(rw, t)
 		return
 	}
-	err := q.SaveData(r)
+	response := query.Get("response")
+	var err error
+	if response != "" {
+		err = q.UpdateData(r, response)
+	} else {
+		var ignored bool
+		response, ignored, err = q.SaveData(r)
+		if err == nil {
+			if ignored {
+				countFieldwork(id, fieldworkScreenOut)
+			} else {
+				countFieldwork(id, fieldworkSubmission)
+			}
+		}
+	}
 	if err != nil {
 		_, validationError := err.(ErrValidation)
 		if validationError {
@@ -310,9 +392,41 @@ func answerHandle(rw http.ResponseWriter, r *http.Request) {
 		rw.Write([]byte(err.Error()))
 		return
 	}
+	if response != "" {
+		http.Redirect(rw, r, fmt.Sprintf("%s/%s?end=1&response=%s", config.ServerPath, id, url.QueryEscape(response)), http.StatusSeeOther)
+		return
+	}
 	http.Redirect(rw, r, fmt.Sprintf("%s/%s?end=1", config.ServerPath, id), http.StatusSeeOther)
 }
 
+func skipHandle(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	id := r.URL.Query().Get("id")
+	questionnairesLock.RLock()
+	q, ok := questionnaires[id]
+	questionnairesLock.RUnlock()
+	if !ok || !q.Open || !q.hasSkipConditions() {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !skipAllowed(helper.GetRealIP(r)) {
+		rw.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	b, err := json.Marshal(q.SkippedPages(r))
+	if err != nil {
+		log.Printf("server: can not encode skipped pages for questionnaire %s: %s", id, err.Error())
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Write(b)
+}
+
 func resultsHandle(rw http.ResponseWriter, r *http.Request) {
 	rw.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
 	translationStruct := translation.GetDefaultTranslation()
@@ -326,6 +440,7 @@ func resultsHandle(rw http.ResponseWriter, r *http.Request) {
 
 		key := r.Form.Get("key")
 		pw := r.Form.Get("pw")
+		a := r.Form.Get("auth")
 
 		questionnairesLock.RLock()
 		q, ok := questionnaires[key]
@@ -338,11 +453,16 @@ func resultsHandle(rw http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		ok, err = registry.ComparePasswords(q.PasswordMethod, pw, q.Password)
-		if err != nil {
-			rw.WriteHeader(http.StatusInternalServerError)
-			rw.Write([]byte(err.Error()))
-			return
+		if pw == "" && a != "" {
+			// Results page was already opened, e.g. for cross-tabulation
+			ok = auth.VerifyStringsTimed(a, key, time.Now(), 1*time.Hour)
+		} else {
+			ok, err = registry.ComparePasswords(q.PasswordMethod, pw, q.Password)
+			if err != nil {
+				rw.WriteHeader(http.StatusInternalServerError)
+				rw.Write([]byte(err.Error()))
+				return
+			}
 		}
 		if !ok {
 			if config.LogFailedLogin {
@@ -352,14 +472,24 @@ func resultsHandle(rw http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		results, err := q.GetResults()
+		filter, err := ParseResultFilter(r.Form.Get("filter"))
+		if err == nil {
+			err = q.CheckFilter(filter)
+		}
+		if err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			rw.Write([]byte(err.Error()))
+			return
+		}
+
+		results, err := q.GetResults(filter)
 		if err != nil {
 			rw.WriteHeader(http.StatusInternalServerError)
 			rw.Write([]byte(err.Error()))
 			return
 		}
 
-		a, err := auth.GetStringsTimed(time.Now(), key)
+		a, err = auth.GetStringsTimed(time.Now(), key)
 		if err != nil {
 			rw.WriteHeader(http.StatusInternalServerError)
 			rw.Write([]byte(err.Error()))
@@ -374,11 +504,56 @@ func resultsHandle(rw http.ResponseWriter, r *http.Request) {
 		}
 
 		td := resultsTemplateStruct{
-			Results:     results,
-			Key:         key,
-			Auth:        a,
-			Translation: translationStruct,
-			ServerPath:  config.ServerPath,
+			Results:        results,
+			Key:            key,
+			Auth:           a,
+			Categorical:    q.CategoricalQuestions(),
+			Codable:        len(q.CodableQuestions()) > 0,
+			Weighting:      q.DescribeWeighting(translationStruct),
+			CrossTabRow:    r.Form.Get("crosstab_row"),
+			CrossTabColumn: r.Form.Get("crosstab_column"),
+			Compare:        r.Form.Get("compare"),
+			FilterOptions:  q.FilterOptions(),
+			MinCellSize:    q.MinCellSize,
+			Filter:         filter.String(),
+			FilterText:     q.DescribeFilter(filter),
+			Translation:    translationStruct,
+			ServerPath:     config.ServerPath,
+		}
+
+		if filter.Active() {
+			td.FilterMatching, td.FilterTotal, err = q.CountFiltered(filter)
+			if err != nil {
+				rw.WriteHeader(http.StatusInternalServerError)
+				rw.Write([]byte(err.Error()))
+				return
+			}
+			td.FilterSuppressed = registry.DisplayOptions{MinCellSize: q.MinCellSize}.Suppressed(td.FilterMatching)
+		}
+
+		td.Fieldwork, err = q.GetFieldwork()
+		if err != nil {
+			rw.WriteHeader(http.StatusInternalServerError)
+			rw.Write([]byte(err.Error()))
+			return
+		}
+
+		if td.CrossTabRow != "" && td.CrossTabColumn != "" {
+			td.CrossTab, err = q.GetCrossTab(td.CrossTabRow, td.CrossTabColumn, filter)
+			if err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+				rw.Write([]byte(err.Error()))
+				return
+			}
+		}
+
+		if td.Compare != "" {
+			td.Comparison, err = q.GetGroupComparison(td.Compare, filter)
+			if err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+				rw.Write([]byte(err.Error()))
+				return
+			}
 		}
 
 		err = resultsTemplate.ExecuteTemplate(rw, "results.html", td)
@@ -391,6 +566,173 @@ func resultsHandle(rw http.ResponseWriter, r *http.Request) {
 	resultsAccessTemplate.Execute(rw, resultsAccessTemplateStruct{translationStruct, config.ServerPath})
 }
 
+func publicResultsHandle(rw http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("id")
+
+	questionnairesLock.RLock()
+	q, ok := questionnaires[key]
+	questionnairesLock.RUnlock()
+	if !ok || q.PublicResults == nil {
+		rw.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+		rw.WriteHeader(http.StatusNotFound)
+		translationStruct := translation.GetDefaultTranslation()
+		t := errorTemplateStruct{template.HTML(fmt.Sprintf("<h1>%s</h1>", translationStruct.CanNotFindQuestionnaire)), translationStruct, config.ServerPath}
+		errorTemplate.Execute(rw, t)
+		return
+	}
+
+	rw.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", q.PublicResults.CacheSeconds))
+
+	page, ok := getPublicResultsCache(key)
+	if ok {
+		rw.Write(page)
+		return
+	}
+
+	translationStruct, err := translation.GetTranslation(q.Language)
+	if err != nil {
+		log.Printf("server: error while getting translation (%s) for questionnaire %s: %s", q.Language, key, err.Error())
+		translationStruct = translation.GetDefaultTranslation()
+	}
+
+	results, err := q.GetPublicResults()
+	if err != nil {
+		rw.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+		rw.WriteHeader(http.StatusInternalServerError)
+		rw.Write([]byte(err.Error()))
+		return
+	}
+
+	td := publicResultsTemplateStruct{
+		Results:     results,
+		Updated:     fmt.Sprintf(translationStruct.PublicResultsUpdated, time.Now().Format("2006-01-02 15:04")),
+		Translation: translationStruct,
+		ServerPath:  config.ServerPath,
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0, 1024))
+	err = publicResultsTemplate.ExecuteTemplate(output, "public.html", td)
+	if err != nil {
+		log.Printf("public results: Error executing template (%s)", err.Error())
+		rw.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	page = output.Bytes()
+	setPublicResultsCache(key, page, time.Duration(q.PublicResults.CacheSeconds)*time.Second)
+	rw.Write(page)
+}
+
+func codingHandle(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+
+	if r.Method != http.MethodPost {
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		rw.Write([]byte(err.Error()))
+		return
+	}
+	key := r.Form.Get("key")
+	a := r.Form.Get("auth")
+
+	questionnairesLock.RLock()
+	q, ok := questionnaires[key]
+	questionnairesLock.RUnlock()
+
+	if !ok || !auth.VerifyStringsTimed(a, key, time.Now(), 1*time.Hour) {
+		if config.LogFailedLogin {
+			log.Printf("Failed login from %s", helper.GetRealIP(r))
+		}
+		rw.WriteHeader(http.StatusUnauthorized)
+		rw.Write([]byte("Access key not valid"))
+		return
+	}
+
+	questions := q.CodableQuestions()
+	if len(questions) == 0 {
+		rw.WriteHeader(http.StatusNotFound)
+		rw.Write([]byte("questionnaire has no questions which can be coded"))
+		return
+	}
+	question := r.Form.Get("question")
+	if question == "" {
+		question = questions[0]
+	}
+	page, pageErr := strconv.Atoi(r.Form.Get("page"))
+	if pageErr != nil {
+		page = 0
+	}
+	uncoded := r.Form.Get("uncoded") != ""
+
+	translationStruct, err := translation.GetTranslation(q.Language)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		rw.Write([]byte(fmt.Sprintf("can not get translation for language '%s'", q.Language)))
+		return
+	}
+
+	message := ""
+	switch r.Form.Get("action") {
+	case "":
+	case "codebook":
+		var codebook []string
+		codebook, err = ParseCodebook(r.Form.Get("codebook"))
+		if err == nil {
+			err = q.SaveCodebook(question, codebook)
+		}
+		message = translationStruct.CodingCodebookSaved
+	case "codes":
+		codes := make(map[int][]string)
+		for _, p := range r.Form["position"] {
+			position, err := strconv.Atoi(p)
+			if err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+				rw.Write([]byte(err.Error()))
+				return
+			}
+			codes[position] = r.Form[fmt.Sprintf("code_%d", position)]
+		}
+		err = q.SaveCodes(question, codes)
+		message = translationStruct.CodingCodesSaved
+	default:
+		err = fmt.Errorf("unknown action %s", r.Form.Get("action"))
+	}
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(err.Error()))
+		return
+	}
+
+	td, err := q.GetCodingPage(question, page, uncoded)
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(err.Error()))
+		return
+	}
+
+	td.Key = key
+	td.Message = message
+	td.ServerPath = config.ServerPath
+	td.Auth, err = auth.GetStringsTimed(time.Now(), key)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		rw.Write([]byte(err.Error()))
+		return
+	}
+	td.Translation = translationStruct
+
+	err = codingTemplate.ExecuteTemplate(rw, "coding.html", td)
+	if err != nil {
+		log.Println("server:", err)
+	}
+}
+
 func resultDownloadHandle(rw http.ResponseWriter, r *http.Request, filetype string) {
 	rw.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
 
@@ -451,6 +793,16 @@ func resultDownloadHandle(rw http.ResponseWriter, r *http.Request, filetype stri
 		}
 	}
 
+	filter, err := ParseResultFilter(r.Form.Get("filter"))
+	if err == nil {
+		err = q.CheckFilter(filter)
+	}
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(err.Error()))
+		return
+	}
+
 	name := strings.ReplaceAll(key, "\"", "_")
 	name = strings.ReplaceAll(name, ";", "_")
 
@@ -458,9 +810,9 @@ func resultDownloadHandle(rw http.ResponseWriter, r *http.Request, filetype stri
 
 	switch filetype {
 	case "csv":
-		err = q.WriteCSV(rw)
+		err = q.WriteCSV(rw, filter)
 	case "zip":
-		err = q.WriteZip(rw)
+		err = q.WriteZip(rw, filter)
 	default:
 		rw.WriteHeader(http.StatusInternalServerError)
 		rw.Write([]byte(fmt.Sprintf("Unknown filetype %s", filetype)))
@@ -554,6 +906,7 @@ func reloadHandle(rw http.ResponseWriter, r *http.Request) {
 		questionnairesLock.Lock()
 		questionnaires = q
 		questionnairesLock.Unlock()
+		clearPublicResultsCache()
 
 		rw.WriteHeader(http.StatusOK)
 		if isWebsite {
@@ -0,0 +1,245 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Top-Ranger/questiongo/helper"
+	"github.com/Top-Ranger/questiongo/registry"
+)
+
+// loadSkipConditions compiles and checks the skip conditions of all pages (see QuestionnairePage.SkipIf).
+// headerPage holds the page on which each statistics header becomes known, headerType its type.
+// It must be called after all questions (including computed questions) are loaded.
+func (q *Questionnaire) loadSkipConditions(headerPage map[string]int, headerType map[string]registry.ValueType) error {
+	types := make(map[string]helper.ExpressionType, len(headerType))
+	for k, v := range headerType {
+		switch v {
+		case registry.ValueNumber:
+			types[k] = helper.ExpressionNumber
+		case registry.ValueString:
+			types[k] = helper.ExpressionString
+		case registry.ValueBool:
+			types[k] = helper.ExpressionBool
+		}
+	}
+
+	q.skipValues = make(map[string]bool)
+	for p := range q.Pages {
+		if q.Pages[p].SkipIf == "" {
+			continue
+		}
+		e, err := helper.CompileExpression(q.Pages[p].SkipIf)
+		if err != nil {
+			return fmt.Errorf("skip condition of page %d: %w", p, err)
+		}
+		if len(e.Variables()) == 0 {
+			return fmt.Errorf("skip condition of page %d must reference at least one value", p)
+		}
+		t, err := e.Check(types)
+		if err != nil {
+			return fmt.Errorf("skip condition of page %d: %w", p, err)
+		}
+		if t != helper.ExpressionBool {
+			return fmt.Errorf("skip condition of page %d must be of type bool, is %s", p, t)
+		}
+		for _, v := range e.Variables() {
+			page, ok := headerPage[v]
+			if !ok {
+				return fmt.Errorf("skip condition of page %d references unknown value '%s'", p, v)
+			}
+			if page >= p {
+				return fmt.Errorf("skip condition of page %d: value '%s' must be known on an earlier page, is known on page %d", p, v, page)
+			}
+			if q.RandomOrderPages && page >= q.DoNotRandomiseFirstNPages && p < len(q.Pages)-q.DoNotRandomiseLastNPages {
+				return fmt.Errorf("skip condition of page %d: value '%s' might be shown after the page due to random page order", p, v)
+			}
+			q.skipValues[v] = true
+		}
+		q.Pages[p].skip = e
+	}
+
+	// Computed questions can only reference earlier computed questions, so a single pass in reverse order finds all needed values
+	for c := len(q.computed) - 1; c >= 0; c-- {
+		cq := q.allQuestions[q.computed[c]].(registry.ComputedQuestion)
+		for _, h := range cq.GetStatisticsHeader() {
+			if q.skipValues[h] {
+				for _, r := range cq.References() {
+					q.skipValues[r] = true
+				}
+				break
+			}
+		}
+	}
+
+	// Skip conditions are evaluated for every page change without authentication, so they must only depend on the submitted answers
+	for p := range q.Pages {
+		for _, question := range q.Pages[p].questions {
+			if _, ok := question.(registry.DataSourceQuestion); ok && q.neededForSkip(question) {
+				return fmt.Errorf("skip conditions can not use question %s, since it depends on stored answers", question.GetID())
+			}
+		}
+	}
+	return nil
+}
+
+// skipRequestsPerMinute is the number of skip requests (see SkippedPages) a single client can make per minute.
+const skipRequestsPerMinute = 120
+
+var skipLimitLock sync.Mutex
+var skipLimitStart time.Time
+var skipLimit = make(map[string]int)
+
+// skipAllowed returns whether the client with the given IP can make another skip request.
+// Requests are counted per minute, so the counts of all clients are dropped once a minute.
+func skipAllowed(ip string) bool {
+	skipLimitLock.Lock()
+	defer skipLimitLock.Unlock()
+	if time.Since(skipLimitStart) >= time.Minute {
+		skipLimitStart = time.Now()
+		skipLimit = make(map[string]int)
+	}
+	skipLimit[ip]++
+	return skipLimit[ip] <= skipRequestsPerMinute
+}
+
+// hasSkipConditions returns whether any page has a skip condition.
+func (q Questionnaire) hasSkipConditions() bool {
+	for p := range q.Pages {
+		if q.Pages[p].skip != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// neededForSkip returns whether any statistics header of the question is used by a skip condition, either directly or through a computed question.
+func (q Questionnaire) neededForSkip(question registry.Question) bool {
+	for _, h := range question.GetStatisticsHeader() {
+		if q.skipValues[h] {
+			return true
+		}
+	}
+	return false
+}
+
+// skippedPages returns the pages (index of Pages) which are skipped because their skip condition is true.
+// results holds the answers mapped by question ID, inactive the questions which are not shown (e.g. iterations of repeat groups).
+// Pages are evaluated in order. A skip condition sees the answers of all earlier pages and each computed question as soon as all its inputs are known.
+// Answers on skipped pages and answers which are not valid are treated as empty.
+func (q Questionnaire) skippedPages(results map[string]map[string][]string, inactive map[string]bool) map[int]bool {
+	skipped := make(map[int]bool)
+	if !q.hasSkipConditions() {
+		return skipped
+	}
+
+	values := make(map[string]string)
+	addValues := func(question registry.Question, entry string) {
+		header := question.GetStatisticsHeader()
+		statistics := question.GetStatistics([]string{entry})
+		if len(statistics) != 1 || len(statistics[0]) != len(header) {
+			return
+		}
+		for h := range header {
+			values[header[h]] = statistics[0][h]
+		}
+	}
+
+	computed := make([]bool, len(q.computed))
+	for p := range q.Pages {
+		// Computed questions whose inputs are all on earlier pages
+		for c, i := range q.computed {
+			if computed[c] || q.computedPage[c] >= p {
+				continue
+			}
+			computed[c] = true
+			if !q.neededForSkip(q.allQuestions[i]) {
+				continue
+			}
+			// Screen-out is only applied on submission
+			entry, _ := q.allQuestions[i].(registry.ComputedQuestion).Compute(values)
+			addValues(q.allQuestions[i], entry)
+		}
+
+		if q.Pages[p].skip != nil {
+			v, err := q.Pages[p].skip.Evaluate(values)
+			if err != nil && err != helper.ErrExpressionMissingValue {
+				log.Printf("skip condition (%s): Can not evaluate condition of page %d: %s", q.id, p, err.Error())
+			}
+			if err == nil && v.Bool {
+				skipped[p] = true
+			}
+		}
+
+		for _, question := range q.Pages[p].questions {
+			if !q.neededForSkip(question) {
+				continue
+			}
+			m, ok := results[question.GetID()]
+			if !ok || skipped[p] || inactive[question.GetID()] || question.ValidateInput(m) != nil {
+				m = make(map[string][]string)
+			}
+			addValues(question, question.GetDatabaseEntry(m))
+		}
+	}
+	return skipped
+}
+
+// inactiveRepeats returns the IDs of all questions of repeat group iterations which are not shown for the form values.
+func (q Questionnaire) inactiveRepeats(form url.Values) map[string]bool {
+	inactive := make(map[string]bool)
+	for g := range q.repeats {
+		for it := q.repeats[g].activeIterations(form.Get(q.repeats[g].TimesFrom)); it < len(q.repeats[g].indices); it++ {
+			for _, i := range q.repeats[g].indices[it] {
+				inactive[q.allQuestions[i].GetID()] = true
+			}
+		}
+	}
+	return inactive
+}
+
+// SkippedPages returns the pages (index of Pages) which are skipped for the answers given so far, contained in the http.Request.
+// It is used while answering to only show the pages which apply to the participant. SaveData evaluates all skip conditions again.
+func (q Questionnaire) SkippedPages(r *http.Request) []int {
+	r.ParseForm()
+
+	results := make(map[string]map[string][]string)
+	for k := range r.Form {
+		id := strings.Split(k, "_")[0]
+		m, ok := results[id]
+		if !ok {
+			m = make(map[string][]string)
+			results[id] = m
+		}
+		m[k] = r.Form[k]
+	}
+
+	skipped := q.skippedPages(results, q.inactiveRepeats(r.Form))
+	result := make([]int, 0, len(skipped))
+	for p := range q.Pages {
+		if skipped[p] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
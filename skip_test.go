@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	_ "github.com/Top-Ranger/questiongo/passwordmethods"
+	_ "github.com/Top-Ranger/questiongo/question"
+)
+
+// loadSkipQuestionnaire loads a questionnaire with the children page (page 1) skipped through the computed value haskids and the given skip condition on the last page.
+func loadSkipQuestionnaire(t *testing.T, lastSkipIf string) (Questionnaire, error) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"questionnaire.json": `{
+    "Password": "test",
+    "PasswordMethod": "plain",
+    "Open": true,
+    "Start": "start.md",
+    "StartFormat": "plain",
+    "End": "end.md",
+    "EndFormat": "plain",
+    "Pages": [
+        {"Questions": [["kids", "number", "kids.json"], ["date", "appointment", "appointment.json"]]},
+        {"SkipIf": "!{haskids}", "Questions": [["name", "text", "text.json"]]},
+        {"SkipIf": ` + strconv.Quote(lastSkipIf) + `, "Questions": [["end", "text", "text.json"]]}
+    ],
+    "Computed": [
+        ["haskids", "computed", "haskids.json"]
+    ]
+}`,
+		"start.md":         "Start",
+		"end.md":           "End",
+		"kids.json":        `{"Format": "plain", "Question": "Children", "HasMinMax": true, "Min": 0, "Max": 5}`,
+		"text.json":        `{"Format": "plain", "Question": "Text", "Lines": 1}`,
+		"haskids.json":     `{"Format": "plain", "Title": "Has children", "Expression": "kids > 0"}`,
+		"appointment.json": `{"Format": "plain", "Text": "Date", "FirstDate": "2020-01-01", "LastDate": "2020-01-02", "Days": ["wed", "thu"], "Time": ["notime"]}`,
+	}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		if err != nil {
+			t.Fatalf("can not write %s: %s", name, err.Error())
+		}
+	}
+	return LoadQuestionnaire(dir, filepath.Join(dir, "questionnaire.json"), "skip")
+}
+
+func TestSkippedPages(t *testing.T) {
+	q, err := loadSkipQuestionnaire(t, "{name} == 'skip'")
+	if err != nil {
+		t.Fatalf("can not load questionnaire: %s", err.Error())
+	}
+
+	tests := []struct {
+		name string
+		form url.Values
+		want []int
+	}{
+		{"no children", url.Values{"kids": {"0"}}, []int{1}},
+		{"children", url.Values{"kids": {"2"}}, []int{}},
+		{"missing value", url.Values{}, []int{}},
+		{"condition on answer", url.Values{"kids": {"2"}, "name": {"skip"}}, []int{2}},
+		{"answer on skipped page", url.Values{"kids": {"0"}, "name": {"skip"}}, []int{1}},
+		{"invalid answer", url.Values{"kids": {"9"}}, []int{}},
+	}
+
+	for _, tc := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/skip.json?id=skip", strings.NewReader(tc.form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		got := q.SkippedPages(r)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestLoadSkipConditionsError(t *testing.T) {
+	tests := []string{
+		"{end} == 'x'",      // Value on the same page
+		"{unknown} == 'x'",  // Unknown value
+		"kids + 1",          // Not bool
+		"true",              // No value
+		"{name} +",          // Syntax
+		"{date_name} == ''", // Depends on stored answers
+	}
+
+	for _, tc := range tests {
+		_, err := loadSkipQuestionnaire(t, tc)
+		if err == nil {
+			t.Errorf("%s: expected error", tc)
+		}
+	}
+}
+
+func TestSkipAllowed(t *testing.T) {
+	for i := 0; i < skipRequestsPerMinute; i++ {
+		if !skipAllowed("192.0.2.1") {
+			t.Fatalf("request %d not allowed", i+1)
+		}
+	}
+	if skipAllowed("192.0.2.1") {
+		t.Errorf("request over the limit allowed")
+	}
+	if !skipAllowed("192.0.2.2") {
+		t.Errorf("request of other client not allowed")
+	}
+}
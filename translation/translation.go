@@ -1,5 +1,5 @@
 // SPDX-License-Identifier: Apache-2.0
-// Copyright 2020,2021,2022 Marcus Soll
+// Copyright 2020,2021,2022,2026 Marcus Soll
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -64,6 +64,41 @@ type Translation struct {
 	ReloadSurveys               string
 	SurveyReloadSuccessful      string
 	ReloadingDisabled           string
+	FileUploadMaximumSize       string
+	FileUploadMaximumFiles      string
+	FileUploadTooManyFiles      string
+	FileUploadFileTooLarge      string
+	RepeatIteration             string
+	MaxDiffBest                 string
+	MaxDiffWorst                string
+	MaxDiffSet                  string
+	ConjointTask                string
+	ConjointOption              string
+	ConjointNone                string
+	ReactionTimeKeys            string
+	ReactionTimeStart           string
+	ReactionTimeDone            string
+	DrawingClear                string
+	DrawingTooLarge             string
+	DropdownSelect              string
+	DropdownSearch              string
+	DropdownOther               string
+	DateWeekdayNotAllowed       string
+	AppointmentSeatsFree        string
+	AppointmentLocalTime        string
+	EditLinkText                string
+	EditAnswer                  string
+	EditUnknownResponse         string
+	AppointmentCurrentVotes     string
+	AppointmentParticipant      string
+	CodingCodebookSaved         string
+	CodingCodesSaved            string
+	PublicResults               string
+	PublicResultsUpdated        string
+	SkipError                   string
+	WeightedResults             string
+	WeightingQuestion           string
+	WeightingRaking             string
 }
 
 const defaultLanguage = "en"
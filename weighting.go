@@ -0,0 +1,245 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/Top-Ranger/questiongo/registry"
+	"github.com/Top-Ranger/questiongo/translation"
+)
+
+// WeightColumn is the header of the weights in the result exports.
+// Since question IDs can not contain '_', it can not collide with a question.
+const WeightColumn = "_weight"
+
+// QuestionnaireWeighting describes how responses are weighted in the results.
+// The weights are either taken from a numeric question (Question, e.g. a computed question), computed by raking to the population margins (Raking), or both.
+// In the latter case, raking starts from the weights of Question.
+// Weights are always computed on all responses and scaled to an average of 1.
+type QuestionnaireWeighting struct {
+	Question      string
+	Raking        []QuestionnaireRakingTarget
+	MaxIterations int
+}
+
+// QuestionnaireRakingTarget holds the population share of each category (in the order of registry.CategoricalQuestion) of a question.
+// The shares do not need to sum up to 1, they are normalised.
+type QuestionnaireRakingTarget struct {
+	Question string
+	Targets  []float64
+}
+
+// defaultRakingIterations is the maximum number of raking iterations if none is given.
+const defaultRakingIterations = 100
+
+// checkWeighting validates the weighting of the questionnaire and resolves the used questions.
+// It must be called after all questions are loaded.
+func (q *Questionnaire) checkWeighting() error {
+	if q.Weighting == nil {
+		return nil
+	}
+	if q.Weighting.Question == "" && len(q.Weighting.Raking) == 0 {
+		return fmt.Errorf("weighting needs either Question or Raking")
+	}
+	if q.Weighting.MaxIterations < 0 {
+		return fmt.Errorf("weighting: MaxIterations must be positive, is %d", q.Weighting.MaxIterations)
+	}
+
+	index := make(map[string]int, len(q.allQuestions))
+	for i := range q.allQuestions {
+		index[q.allQuestions[i].GetID()] = i
+	}
+
+	q.weightQuestion = -1
+	if q.Weighting.Question != "" {
+		i, ok := index[q.Weighting.Question]
+		if !ok {
+			return fmt.Errorf("weighting: unknown question %s", q.Weighting.Question)
+		}
+		nq, ok := q.allQuestions[i].(registry.NumericQuestion)
+		if !ok || !nq.IsNumeric() {
+			return fmt.Errorf("weighting: question %s is not numeric", q.Weighting.Question)
+		}
+		q.weightQuestion = i
+	}
+
+	q.rakingQuestions = make([]int, len(q.Weighting.Raking))
+	for r := range q.Weighting.Raking {
+		target := q.Weighting.Raking[r]
+		i, ok := index[target.Question]
+		if !ok {
+			return fmt.Errorf("weighting: unknown raking question %s", target.Question)
+		}
+		cq, ok := q.allQuestions[i].(registry.CategoricalQuestion)
+		if !ok {
+			return fmt.Errorf("weighting: raking question %s is not categorical", target.Question)
+		}
+		if len(target.Targets) != len(cq.GetCategoryLabels()) {
+			return fmt.Errorf("weighting: raking question %s needs %d targets, has %d", target.Question, len(cq.GetCategoryLabels()), len(target.Targets))
+		}
+		sum := 0.0
+		for t := range target.Targets {
+			if target.Targets[t] < 0 {
+				return fmt.Errorf("weighting: targets of raking question %s must not be negative", target.Question)
+			}
+			sum += target.Targets[t]
+		}
+		if sum == 0 {
+			return fmt.Errorf("weighting: targets of raking question %s must not all be 0", target.Question)
+		}
+		q.rakingQuestions[r] = i
+	}
+	return nil
+}
+
+// DescribeWeighting returns a short plain text description of the weighting in the language of the translation. It is empty if the results are not weighted.
+func (q Questionnaire) DescribeWeighting(t translation.Translation) string {
+	if q.Weighting == nil {
+		return ""
+	}
+	parts := make([]string, 0, 2)
+	if q.Weighting.Question != "" {
+		parts = append(parts, fmt.Sprintf(t.WeightingQuestion, q.Weighting.Question))
+	}
+	if len(q.Weighting.Raking) > 0 {
+		raking := make([]string, len(q.Weighting.Raking))
+		for i := range q.Weighting.Raking {
+			raking[i] = q.Weighting.Raking[i].Question
+		}
+		parts = append(parts, fmt.Sprintf(t.WeightingRaking, strings.Join(raking, ", ")))
+	}
+	return fmt.Sprintf(t.WeightedResults, strings.Join(parts, ", "))
+}
+
+// getWeights returns the weights of the responses at the given positions (see getDataPositions).
+// It returns nil if the questionnaire is not weighted.
+// Responses without a valid weight (e.g. no answer to the weight question) have a weight of 0.
+func (q Questionnaire) getWeights(positions []int) ([]float64, error) {
+	if q.Weighting == nil {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(q.rakingQuestions)+1)
+	for _, i := range q.rakingQuestions {
+		ids = append(ids, q.allQuestions[i].GetID())
+	}
+	if q.weightQuestion != -1 {
+		ids = append(ids, q.allQuestions[q.weightQuestion].GetID())
+	}
+
+	data, total, err := q.getData(ids, ResultFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make([]float64, total)
+	for i := range weights {
+		weights[i] = 1
+	}
+	if q.weightQuestion != -1 {
+		values := q.allQuestions[q.weightQuestion].(registry.NumericQuestion).GetValues(data[len(data)-1])
+		for i := range weights {
+			weights[i] = 0
+			if i < len(values) && values[i] > 0 && !math.IsInf(values[i], 0) {
+				weights[i] = values[i]
+			}
+		}
+	}
+
+	if len(q.rakingQuestions) > 0 {
+		categories := make([][]int, len(q.rakingQuestions))
+		targets := make([][]float64, len(q.rakingQuestions))
+		for r, i := range q.rakingQuestions {
+			categories[r] = q.allQuestions[i].(registry.CategoricalQuestion).GetCategories(data[r])
+			targets[r] = q.Weighting.Raking[r].Targets
+		}
+		iterations := q.Weighting.MaxIterations
+		if iterations == 0 {
+			iterations = defaultRakingIterations
+		}
+		rake(weights, categories, targets, iterations)
+	}
+
+	// Scale to an average weight of 1
+	sum, n := 0.0, 0
+	for i := range weights {
+		if weights[i] > 0 {
+			sum += weights[i]
+			n++
+		}
+	}
+	if sum > 0 {
+		for i := range weights {
+			weights[i] *= float64(n) / sum
+		}
+	}
+
+	result := make([]float64, len(positions))
+	for i, p := range positions {
+		if p < len(weights) {
+			result[i] = weights[p]
+		}
+	}
+	return result, nil
+}
+
+// rake adjusts the weights in place by iterative proportional fitting, so that the weighted shares of the categories match the targets.
+// categories[r] holds the category of each response for the raking question r, responses without a category (-1) are not adjusted for that question.
+// Categories without any response can not be matched and are ignored.
+func rake(weights []float64, categories [][]int, targets [][]float64, iterations int) {
+	const epsilon = 1e-6
+
+	for it := 0; it < iterations; it++ {
+		change := 0.0
+		for r := range categories {
+			sums := make([]float64, len(targets[r]))
+			total, totalTarget := 0.0, 0.0
+			for i := range weights {
+				if i < len(categories[r]) && categories[r][i] >= 0 && categories[r][i] < len(sums) {
+					sums[categories[r][i]] += weights[i]
+					total += weights[i]
+				}
+			}
+			for c := range sums {
+				if sums[c] > 0 {
+					totalTarget += targets[r][c]
+				}
+			}
+			if total == 0 || totalTarget == 0 {
+				continue
+			}
+
+			factors := make([]float64, len(sums))
+			for c := range sums {
+				if sums[c] > 0 {
+					factors[c] = targets[r][c] / totalTarget * total / sums[c]
+					change = math.Max(change, math.Abs(factors[c]-1))
+				}
+			}
+			for i := range weights {
+				if i < len(categories[r]) && categories[r][i] >= 0 && categories[r][i] < len(factors) {
+					weights[i] *= factors[categories[r][i]]
+				}
+			}
+		}
+		if change < epsilon {
+			return
+		}
+	}
+}
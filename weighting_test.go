@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// rakingShares returns the weighted share of each of the n categories. Responses without a category are not counted.
+func rakingShares(weights []float64, categories []int, n int) []float64 {
+	result := make([]float64, n)
+	total := 0.0
+	for i := range weights {
+		if categories[i] >= 0 {
+			result[categories[i]] += weights[i]
+			total += weights[i]
+		}
+	}
+	for c := range result {
+		result[c] /= total
+	}
+	return result
+}
+
+func TestRake(t *testing.T) {
+	tests := []struct {
+		name       string
+		weights    []float64
+		categories [][]int
+		targets    [][]float64
+		want       [][]float64 // Expected shares per raking question
+	}{
+		{
+			name:       "single question",
+			weights:    []float64{1, 1, 1, 1},
+			categories: [][]int{{0, 0, 0, 1}},
+			targets:    [][]float64{{0.5, 0.5}},
+			want:       [][]float64{{0.5, 0.5}},
+		},
+		{
+			name:       "targets as population counts",
+			weights:    []float64{1, 1, 1, 1, 1},
+			categories: [][]int{{0, 1, 1, 1, 2}},
+			targets:    [][]float64{{200, 300, 500}},
+			want:       [][]float64{{0.2, 0.3, 0.5}},
+		},
+		{
+			name:    "two questions",
+			weights: []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+			categories: [][]int{
+				{0, 0, 0, 0, 0, 0, 0, 1, 1, 1},
+				{0, 0, 1, 1, 1, 2, 2, 0, 2, 2},
+			},
+			targets: [][]float64{{0.49, 0.51}, {0.3, 0.4, 0.3}},
+			want:    [][]float64{{0.49, 0.51}, {0.3, 0.4, 0.3}},
+		},
+		{
+			name:    "starting weights",
+			weights: []float64{2, 1, 0.5, 1, 3, 1},
+			categories: [][]int{
+				{0, 1, 0, 1, 0, 1},
+				{0, 0, 1, 1, 1, 0},
+			},
+			targets: [][]float64{{0.5, 0.5}, {0.6, 0.4}},
+			want:    [][]float64{{0.5, 0.5}, {0.6, 0.4}},
+		},
+		{
+			name:       "category without responses",
+			weights:    []float64{1, 1, 1, 1},
+			categories: [][]int{{0, 1, 1, 1}},
+			targets:    [][]float64{{0.2, 0.3, 0.5}},
+			want:       [][]float64{{0.4, 0.6, 0}},
+		},
+		{
+			name:       "responses without category",
+			weights:    []float64{1, 1, 1, 1, 1},
+			categories: [][]int{{0, 1, 1, 1, -1}},
+			targets:    [][]float64{{0.5, 0.5}},
+			want:       [][]float64{{0.5, 0.5}},
+		},
+	}
+
+	for _, test := range tests {
+		weights := make([]float64, len(test.weights))
+		copy(weights, test.weights)
+		rake(weights, test.categories, test.targets, 1000)
+		for r := range test.categories {
+			shares := rakingShares(weights, test.categories[r], len(test.targets[r]))
+			for c := range shares {
+				if math.Abs(shares[c]-test.want[r][c]) > 1e-5 {
+					t.Errorf("%s: shares of question %d = %v, want %v (weights %v)", test.name, r, shares, test.want[r], weights)
+					break
+				}
+			}
+		}
+		for i := range weights {
+			if (test.weights[i] == 0) != (weights[i] == 0) || weights[i] < 0 {
+				t.Errorf("%s: weight %d changed from %v to %v", test.name, i, test.weights[i], weights[i])
+			}
+			if test.categories[0][i] == -1 && len(test.categories) == 1 && weights[i] != test.weights[i] {
+				t.Errorf("%s: weight %d without category changed from %v to %v", test.name, i, test.weights[i], weights[i])
+			}
+		}
+	}
+}
+
+func TestRakeIterations(t *testing.T) {
+	// Raking stops after the given iterations, even if the margins are not matched yet
+	categories := [][]int{
+		{0, 0, 0, 0, 0, 0, 0, 1, 1, 1},
+		{0, 0, 1, 1, 1, 2, 2, 0, 2, 2},
+	}
+	targets := [][]float64{{0.49, 0.51}, {0.3, 0.4, 0.3}}
+	distance := func(iterations int) float64 {
+		weights := []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+		rake(weights, categories, targets, iterations)
+		result := 0.0
+		for r := range categories {
+			shares := rakingShares(weights, categories[r], len(targets[r]))
+			for c := range shares {
+				result = math.Max(result, math.Abs(shares[c]-targets[r][c]))
+			}
+		}
+		return result
+	}
+
+	one, many := distance(1), distance(1000)
+	if one < 1e-3 {
+		t.Errorf("distance after one iteration = %v, expected to be not converged", one)
+	}
+	if many > 1e-5 || many >= one {
+		t.Errorf("distance after 1000 iterations = %v (one iteration: %v)", many, one)
+	}
+}